@@ -89,6 +89,10 @@ const Display: React.FC = () => {
         // Force mode 2 for lottery2 page - removed key to prevent remounting
         return <User forcedMode={2} />;
       
+      case 'lottery3':
+        // Progressive jackpot wheel
+        return <User forcedMode={3} />;
+      
       case 'advertisement':
         return <Restaurant />;
       
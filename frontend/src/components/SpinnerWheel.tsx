@@ -8,6 +8,7 @@ interface SpinnerWheelProps {
   isSpinning: boolean;
   winningIndex?: number;
   spinStartTime?: number | null;
+  spinDuration?: number; // Milliseconds from the server's spin timeline
   disabled?: boolean;
 }
 
@@ -117,6 +118,7 @@ const SpinnerWheel: React.FC<SpinnerWheelProps> = ({
   isSpinning,
   winningIndex,
   spinStartTime,
+  spinDuration = 6000,
   disabled = false
 }) => {
   const [rotation, setRotation] = useState(0);
@@ -128,7 +130,7 @@ const SpinnerWheel: React.FC<SpinnerWheelProps> = ({
   const center = size / 2; // Now 340
   const radius = 260; // Proportionally increased wheel radius
   const segmentAngle = 360 / 12; // Always 12 segments
-  const ANIMATION_DURATION = 6000; // 6 seconds
+  const ANIMATION_DURATION = spinDuration;
   
   // Update ref when rotation changes
   useEffect(() => {
@@ -1,7 +1,10 @@
-import React, { useState, useEffect, useCallback, Fragment, useMemo } from 'react';
+import React, { useState, useEffect, useCallback, useRef, Fragment, useMemo } from 'react';
 import styled from 'styled-components';
 import { 
   apiService, 
+  AuthError,
+  authError,
+  ConflictError,
   GameConfig, 
   PrizeOption, 
   ConfigUpdateRequest, 
@@ -212,6 +215,19 @@ const SuccessMessage = styled.div`
   font-weight: 500;
 `;
 
+const ConflictMessage = styled.div`
+  background: #fdcb6e;
+  color: #2d3436;
+  padding: 12px 20px;
+  border-radius: 8px;
+  margin: 16px 0;
+  font-weight: 500;
+
+  p {
+    margin: 0 0 12px 0;
+  }
+`;
+
 const LoadingMessage = styled.div`
   color: white;
   text-align: center;
@@ -259,11 +275,31 @@ const SpinMessage = styled.div`
   opacity: 0.9;
 `;
 
+// errorMessage explains a failed request: permission errors name what is missing, and an
+// expired login goes back to the login page
+const errorMessage = (err: any, fallback: string): string => {
+  if (err instanceof AuthError && err.status === 401) {
+    window.location.assign(apiService.loginUrl());
+    return '登录已过期，请重新登录';
+  }
+  if (err instanceof AuthError) {
+    return err.permission
+      ? `当前账号没有权限执行此操作 (需要 ${err.permission} 权限)`
+      : '当前账号没有权限执行此操作';
+  }
+  return err.message || fallback;
+};
+
 const Admin: React.FC = () => {
   const [loading, setLoading] = useState(true);
   const [saving, setSaving] = useState(false);
   const [error, setError] = useState('');
   const [success, setSuccess] = useState('');
+  // Saves that lost to another manager's edit, held until the user reloads or keeps their own values
+  const [configConflict, setConfigConflict] = useState<ConflictError<GameConfig> | null>(null);
+  const [restaurantConflict, setRestaurantConflict] = useState<ConflictError<RestaurantConfig> | null>(null);
+  // The config the form was last filled from; saves send only the fields edited since
+  const formSourceRef = useRef<GameConfig | null>(null);
   const [currentPage, setCurrentPage] = useState('lottery1');
   const [isSpinning, setIsSpinning] = useState(false);
 
@@ -309,6 +345,11 @@ const Admin: React.FC = () => {
   const [mode2WinText, setMode2WinText] = useState('中奖了!');
   const [mode2LoseText, setMode2LoseText] = useState('再接再厉');
   const [mode2WinRate, setMode2WinRate] = useState(8.33);
+  const [mode3JackpotText, setMode3JackpotText] = useState('头奖!');
+  const [mode3LoseText, setMode3LoseText] = useState('再接再厉');
+  const [mode3JackpotRate, setMode3JackpotRate] = useState(0.5);
+  const [mode3JackpotSeed, setMode3JackpotSeed] = useState(500);
+  const [mode3JackpotIncrement, setMode3JackpotIncrement] = useState(10);
 
   // Restaurant management state
   const [restaurantName, setRestaurantName] = useState('XX土菜馆');
@@ -332,6 +373,7 @@ const Admin: React.FC = () => {
       ]);
       
       // Update game config state
+      formSourceRef.current = configData;
       setCurrentPlayer(configData.current_player);
       setRemainingSpins(configData.remaining_spins);
       setSelectedMode(configData.mode);
@@ -339,6 +381,11 @@ const Admin: React.FC = () => {
       setMode2WinText(configData.mode2_win_text || '中奖了!');
       setMode2LoseText(configData.mode2_lose_text || '再接再厉');
       setMode2WinRate(configData.mode2_win_rate || 8.33);
+      setMode3JackpotText(configData.mode3_jackpot_text || '头奖!');
+      setMode3LoseText(configData.mode3_lose_text || '再接再厉');
+      setMode3JackpotRate(configData.mode3_jackpot_rate ?? 0.5);
+      setMode3JackpotSeed(configData.mode3_jackpot_seed ?? 500);
+      setMode3JackpotIncrement(configData.mode3_jackpot_increment ?? 10);
       setCurrentPage(configData.current_page || 'lottery1');
       
       // Update restaurant data state
@@ -395,6 +442,7 @@ const Admin: React.FC = () => {
     const unsubscribe = wsService.onConfigUpdated((data: GameConfig) => {
       // Only update config if not spinning (to avoid conflicts)
       if (!isSpinning) {
+        formSourceRef.current = data;
         setCurrentPlayer(data.current_player);
         setRemainingSpins(data.remaining_spins);
         setSelectedMode(data.mode);
@@ -402,6 +450,11 @@ const Admin: React.FC = () => {
         setMode2WinText(data.mode2_win_text || '中奖了!');
         setMode2LoseText(data.mode2_lose_text || '再接再厉');
         setMode2WinRate(data.mode2_win_rate || 8.33);
+        setMode3JackpotText(data.mode3_jackpot_text || '头奖!');
+        setMode3LoseText(data.mode3_lose_text || '再接再厉');
+        setMode3JackpotRate(data.mode3_jackpot_rate ?? 0.5);
+        setMode3JackpotSeed(data.mode3_jackpot_seed ?? 500);
+        setMode3JackpotIncrement(data.mode3_jackpot_increment ?? 10);
         setCurrentPage(data.current_page || 'lottery1');
       }
     });
@@ -417,6 +470,13 @@ const Admin: React.FC = () => {
       // Don't unlock UI yet - wait for spin_lock_cleared
       console.log('Admin: Spin completed, but keeping UI locked during animation');
       // Refresh config after spin to get updated remaining spins
+      if (data.config && formSourceRef.current) {
+        formSourceRef.current = {
+          ...formSourceRef.current,
+          current_player: data.config.current_player,
+          remaining_spins: data.config.remaining_spins,
+        };
+      }
       if (data.config) {
         setCurrentPlayer(data.config.current_player);
         setRemainingSpins(data.config.remaining_spins);
@@ -439,6 +499,7 @@ const Admin: React.FC = () => {
     const unsubscribePageSwitch = wsService.on('page_switched', (data: any) => {
       setCurrentPage(data.page);
       if (data.config) {
+        formSourceRef.current = data.config;
         setCurrentPlayer(data.config.current_player);
         setRemainingSpins(data.config.remaining_spins);
         setSelectedMode(data.config.mode);
@@ -446,6 +507,11 @@ const Admin: React.FC = () => {
         setMode2WinText(data.config.mode2_win_text || '中奖了!');
         setMode2LoseText(data.config.mode2_lose_text || '再接再厉');
         setMode2WinRate(data.config.mode2_win_rate || 8.33);
+        setMode3JackpotText(data.config.mode3_jackpot_text || '头奖!');
+        setMode3LoseText(data.config.mode3_lose_text || '再接再厉');
+        setMode3JackpotRate(data.config.mode3_jackpot_rate ?? 0.5);
+        setMode3JackpotSeed(data.config.mode3_jackpot_seed ?? 500);
+        setMode3JackpotIncrement(data.config.mode3_jackpot_increment ?? 10);
       }
     });
 
@@ -510,7 +576,7 @@ const Admin: React.FC = () => {
             pressedKeys.has('Numpad1') && 
             pressedKeys.has('Numpad2') && 
             pressedKeys.has('Numpad3') &&
-            (currentPage === 'lottery1' || currentPage === 'lottery2')) {
+            (currentPage === 'lottery1' || currentPage === 'lottery2' || currentPage === 'lottery3')) {
           
           // Trigger spin
           handleKeyboardSpin();
@@ -564,7 +630,7 @@ const Admin: React.FC = () => {
       
     } catch (err: any) {
       console.error('Keyboard spin failed:', err);
-      setError(err.message || '键盘触发抽奖失败');
+      setError(errorMessage(err, '键盘触发抽奖失败'));
     }
   };
 
@@ -615,14 +681,26 @@ const Admin: React.FC = () => {
         throw new Error('剩余次数不能小于0');
       }
 
-      // Prepare update request
-      const updateRequest: ConfigUpdateRequest = {
-        mode: selectedMode,
-        current_player: currentPlayer,
-        remaining_spins: remainingSpins,
-      };
+      // Prepare update request from the fields edited since the form was filled, so values
+      // that changed through play meanwhile (player, spins) aren't written back stale
+      const source = formSourceRef.current;
+      const edited = (value: any, saved: any) => !source || JSON.stringify(value) !== JSON.stringify(saved);
+      const updateRequest: ConfigUpdateRequest = {};
+      if (edited(selectedMode, source?.mode)) {
+        updateRequest.mode = selectedMode;
+      }
+      if (edited(currentPlayer, source?.current_player)) {
+        updateRequest.current_player = currentPlayer;
+      }
+      if (edited(remainingSpins, source?.remaining_spins)) {
+        updateRequest.remaining_spins = remainingSpins;
+        if (source) {
+          // Refused with a conflict if spins were used since the form was filled
+          updateRequest.expected_remaining_spins = source.remaining_spins;
+        }
+      }
 
-      if (selectedMode === 1) {
+      if (selectedMode === 1 && edited(mode1Options, source?.mode1_options)) {
         updateRequest.mode1_options = mode1Options;
       }
       
@@ -637,13 +715,40 @@ const Admin: React.FC = () => {
         if (mode2WinRate <= 0 || mode2WinRate >= 100) {
           throw new Error('中奖概率必须在0-100之间');
         }
-        updateRequest.mode2_win_text = mode2WinText;
-        updateRequest.mode2_lose_text = mode2LoseText;
-        updateRequest.mode2_win_rate = mode2WinRate;
+        if (edited(mode2WinText, source?.mode2_win_text)) updateRequest.mode2_win_text = mode2WinText;
+        if (edited(mode2LoseText, source?.mode2_lose_text)) updateRequest.mode2_lose_text = mode2LoseText;
+        if (edited(mode2WinRate, source?.mode2_win_rate)) updateRequest.mode2_win_rate = mode2WinRate;
+      }
+
+      if (selectedMode === 3) {
+        // Validate mode3 settings
+        if (!mode3JackpotText.trim()) {
+          throw new Error('头奖文本不能为空');
+        }
+        if (!mode3LoseText.trim()) {
+          throw new Error('失败文本不能为空');
+        }
+        if (mode3JackpotRate <= 0 || mode3JackpotRate >= 100) {
+          throw new Error('头奖概率必须在0-100之间');
+        }
+        if (mode3JackpotSeed < 0 || mode3JackpotIncrement < 0) {
+          throw new Error('奖池金额不能小于0');
+        }
+        if (edited(mode3JackpotText, source?.mode3_jackpot_text)) updateRequest.mode3_jackpot_text = mode3JackpotText;
+        if (edited(mode3LoseText, source?.mode3_lose_text)) updateRequest.mode3_lose_text = mode3LoseText;
+        if (edited(mode3JackpotRate, source?.mode3_jackpot_rate)) updateRequest.mode3_jackpot_rate = mode3JackpotRate;
+        if (edited(mode3JackpotSeed, source?.mode3_jackpot_seed)) updateRequest.mode3_jackpot_seed = mode3JackpotSeed;
+        if (edited(mode3JackpotIncrement, source?.mode3_jackpot_increment)) updateRequest.mode3_jackpot_increment = mode3JackpotIncrement;
+      }
+
+      if (Object.keys(updateRequest).length === 0) {
+        setSuccess('没有需要保存的修改');
+        setTimeout(() => setSuccess(''), 3000);
+        return;
       }
 
       // Save configuration
-      await apiService.updateConfig(updateRequest);
+      formSourceRef.current = await apiService.updateConfig(updateRequest);
       setSuccess('配置保存成功！');
 
       // Clear success message after 3 seconds
@@ -651,12 +756,46 @@ const Admin: React.FC = () => {
 
     } catch (err: any) {
       console.error('Failed to save config:', err);
-      setError(err.message || '保存配置失败');
+      if (err instanceof ConflictError) {
+        setConfigConflict(err as ConflictError<GameConfig>);
+      } else {
+        setError(errorMessage(err, '保存配置失败'));
+      }
     } finally {
       setSaving(false);
     }
   };
 
+  // Replace the form with the config another manager saved
+  const handleReloadConflictingConfig = () => {
+    if (!configConflict) return;
+    const data = configConflict.current;
+    setCurrentPlayer(data.current_player);
+    setRemainingSpins(data.remaining_spins);
+    setSelectedMode(data.mode);
+    setMode1Options(data.mode1_options || []);
+    setMode2WinText(data.mode2_win_text || '中奖了!');
+    setMode2LoseText(data.mode2_lose_text || '再接再厉');
+    setMode2WinRate(data.mode2_win_rate || 8.33);
+    setMode3JackpotText(data.mode3_jackpot_text || '头奖!');
+    setMode3LoseText(data.mode3_lose_text || '再接再厉');
+    setMode3JackpotRate(data.mode3_jackpot_rate ?? 0.5);
+    setMode3JackpotSeed(data.mode3_jackpot_seed ?? 500);
+    setMode3JackpotIncrement(data.mode3_jackpot_increment ?? 10);
+    setCurrentPage(data.current_page || 'lottery1');
+    formSourceRef.current = data;
+    apiService.acceptConfig(configConflict);
+    setConfigConflict(null);
+  };
+
+  // Keep the form's values; the next save overwrites the other manager's config
+  const handleKeepMyConfig = () => {
+    if (!configConflict) return;
+    formSourceRef.current = configConflict.current;
+    apiService.acceptConfig(configConflict);
+    setConfigConflict(null);
+  };
+
   // Handle reset game
   const handleReset = async () => {
     if (!window.confirm('确定要重置游戏吗？这将清空所有历史记录并重置玩家和次数。')) {
@@ -677,7 +816,7 @@ const Admin: React.FC = () => {
 
     } catch (err: any) {
       console.error('Failed to reset game:', err);
-      setError(err.message || '重置游戏失败');
+      setError(errorMessage(err, '重置游戏失败'));
     } finally {
       setSaving(false);
     }
@@ -699,7 +838,7 @@ const Admin: React.FC = () => {
 
       if (!response.ok) {
         const error = await response.json();
-        throw new Error(error.error || '切换页面失败');
+        throw authError(response.status, error) || new Error(error.error || '切换页面失败');
       }
 
       setSuccess(`已切换到${getPageDisplayName(targetPage)}`);
@@ -709,7 +848,7 @@ const Admin: React.FC = () => {
 
     } catch (err: any) {
       console.error('Failed to switch page:', err);
-      setError(err.message || '切换页面失败');
+      setError(errorMessage(err, '切换页面失败'));
     }
   };
 
@@ -718,6 +857,7 @@ const Admin: React.FC = () => {
     switch (page) {
       case 'lottery1': return '抽奖模式1';
       case 'lottery2': return '抽奖模式2'; 
+      case 'lottery3': return '头奖模式';
       case 'advertisement': return '广告展示页';
       default: return '未知页面';
     }
@@ -745,12 +885,34 @@ const Admin: React.FC = () => {
       setTimeout(() => setSuccess(''), 3000);
     } catch (err: any) {
       console.error('Failed to save restaurant config:', err);
-      setError(err.message || '保存餐厅配置失败');
+      if (err instanceof ConflictError) {
+        setRestaurantConflict(err as ConflictError<RestaurantConfig>);
+      } else {
+        setError(errorMessage(err, '保存餐厅配置失败'));
+      }
     } finally {
       setSaving(false);
     }
   };
 
+  // Replace the form with the restaurant config another manager saved
+  const handleReloadConflictingRestaurant = () => {
+    if (!restaurantConflict) return;
+    const data = restaurantConflict.current;
+    setRestaurantName(data.name);
+    setAdRotationTime(data.ad_rotation_time);
+    setAutoSwitchTime(data.auto_switch_time);
+    apiService.acceptRestaurantConfig(restaurantConflict);
+    setRestaurantConflict(null);
+  };
+
+  // Keep the form's values; the next save overwrites the other manager's restaurant config
+  const handleKeepMyRestaurant = () => {
+    if (!restaurantConflict) return;
+    apiService.acceptRestaurantConfig(restaurantConflict);
+    setRestaurantConflict(null);
+  };
+
   // Handle advertisement upload
   const handleAdvertisementUpload = async (event: React.ChangeEvent<HTMLInputElement>) => {
     const file = event.target.files?.[0];
@@ -785,7 +947,7 @@ const Admin: React.FC = () => {
       setTimeout(() => setSuccess(''), 3000);
     } catch (err: any) {
       console.error('Failed to upload advertisement:', err);
-      setError(err.message || '广告上传失败');
+      setError(errorMessage(err, '广告上传失败'));
     } finally {
       setSaving(false);
     }
@@ -808,7 +970,7 @@ const Admin: React.FC = () => {
       setTimeout(() => setSuccess(''), 3000);
     } catch (err: any) {
       console.error('Failed to delete advertisement:', err);
-      setError(err.message || '删除广告失败');
+      setError(errorMessage(err, '删除广告失败'));
     }
   };
 
@@ -837,7 +999,7 @@ const Admin: React.FC = () => {
         // setTimeout(() => setSuccess(''), 1000);
       } catch (err: any) {
         console.error('Failed to update menu item:', err);
-        setError(err.message || '更新菜单项失败');
+        setError(errorMessage(err, '更新菜单项失败'));
         // Revert local state on error
         setMenuItems(prev => prev.map(m => m.id === id ? item : m));
       } finally {
@@ -879,7 +1041,7 @@ const Admin: React.FC = () => {
       setTimeout(() => setSuccess(''), 3000);
     } catch (err: any) {
       console.error('Failed to add recommendation:', err);
-      setError(err.message || '添加推荐失败');
+      setError(errorMessage(err, '添加推荐失败'));
     }
   };
 
@@ -907,7 +1069,7 @@ const Admin: React.FC = () => {
         // setTimeout(() => setSuccess(''), 1000);
       } catch (err: any) {
         console.error('Failed to update recommendation:', err);
-        setError(err.message || '更新推荐失败');
+        setError(errorMessage(err, '更新推荐失败'));
         // Revert local state on error
         setRecommendations(prev => prev.map(r => r.id === id ? rec : r));
       } finally {
@@ -942,7 +1104,7 @@ const Admin: React.FC = () => {
       setTimeout(() => setSuccess(''), 3000);
     } catch (err: any) {
       console.error('Failed to delete recommendation:', err);
-      setError(err.message || '删除推荐失败');
+      setError(errorMessage(err, '删除推荐失败'));
     }
   };
 
@@ -965,6 +1127,30 @@ const Admin: React.FC = () => {
       <ConfigForm>
         {error && <ErrorMessage>{error}</ErrorMessage>}
         {success && <SuccessMessage>{success}</SuccessMessage>}
+        {configConflict && (
+          <ConflictMessage>
+            <p>
+              保存失败：其他管理员已修改游戏配置 (当前为模式{configConflict.current.mode}，
+              剩余次数 {configConflict.current.remaining_spins})。请载入最新配置，或确认后保留您的修改再保存。
+            </p>
+            <ButtonGroup>
+              <Button $variant="primary" onClick={handleReloadConflictingConfig}>载入最新配置</Button>
+              <Button $variant="secondary" onClick={handleKeepMyConfig}>保留我的修改</Button>
+            </ButtonGroup>
+          </ConflictMessage>
+        )}
+        {restaurantConflict && (
+          <ConflictMessage>
+            <p>
+              保存失败：其他管理员已修改餐厅配置 (当前名称「{restaurantConflict.current.name}」，
+              广告轮播 {restaurantConflict.current.ad_rotation_time} 秒)。请载入最新配置，或确认后保留您的修改再保存。
+            </p>
+            <ButtonGroup>
+              <Button $variant="primary" onClick={handleReloadConflictingRestaurant}>载入最新配置</Button>
+              <Button $variant="secondary" onClick={handleKeepMyRestaurant}>保留我的修改</Button>
+            </ButtonGroup>
+          </ConflictMessage>
+        )}
 
         {/* Page Control Section */}
         <Section>
@@ -998,6 +1184,13 @@ const Admin: React.FC = () => {
             >
               切换到抽奖模式2
             </Button>
+            <Button 
+              $variant={currentPage === 'lottery3' ? 'primary' : 'secondary'}
+              onClick={() => handlePageSwitch('lottery3')}
+              disabled={saving}
+            >
+              切换到头奖模式
+            </Button>
             <Button 
               $variant={currentPage === 'advertisement' ? 'primary' : 'secondary'}
               onClick={() => handlePageSwitch('advertisement')}
@@ -1018,7 +1211,7 @@ const Admin: React.FC = () => {
         </Section>
 
         {/* Game Mode Selection - Only shown when on lottery pages */}
-        {(currentPage === 'lottery1' || currentPage === 'lottery2') && (
+        {(currentPage === 'lottery1' || currentPage === 'lottery2' || currentPage === 'lottery3') && (
         <Section>
           <SectionTitle>游戏模式</SectionTitle>
           <ModeSelector>
@@ -1036,6 +1229,13 @@ const Admin: React.FC = () => {
             >
               模式2 - 固定概率
             </ModeButton>
+            <ModeButton
+              $active={selectedMode === 3}
+              onClick={() => !isSpinning && setSelectedMode(3)}
+              disabled={isSpinning}
+            >
+              模式3 - 累积头奖
+            </ModeButton>
           </ModeSelector>
 
           {selectedMode === 1 && (
@@ -1161,11 +1361,91 @@ const Admin: React.FC = () => {
               </div>
             </div>
           )}
+
+          {selectedMode === 3 && (
+            <div>
+              <p style={{ color: '#666', marginBottom: '20px' }}>
+                累积头奖：每次抽奖奖池增加固定金额，抽中头奖获得整个奖池，随后奖池恢复为初始金额
+              </p>
+
+              <div style={{ display: 'grid', gridTemplateColumns: '1fr 1fr', gap: '16px', marginBottom: '20px' }}>
+                <FormGroup>
+                  <Label htmlFor="mode3JackpotRate">头奖概率 (%)</Label>
+                  <Input
+                    id="mode3JackpotRate"
+                    type="number"
+                    min="0.01"
+                    max="99.9"
+                    step="0.01"
+                    value={mode3JackpotRate}
+                    onChange={(e) => setMode3JackpotRate(parseFloat(e.target.value) || 0)}
+                    disabled={isSpinning}
+                  />
+                  <small style={{ color: '#666', fontSize: '12px', marginTop: '4px', display: 'block' }}>
+                    头奖应当稀有，建议设置0.5%
+                  </small>
+                </FormGroup>
+
+                <FormGroup>
+                  <Label htmlFor="mode3JackpotText">头奖文案</Label>
+                  <Input
+                    id="mode3JackpotText"
+                    type="text"
+                    placeholder="输入抽中头奖时显示的文案"
+                    value={mode3JackpotText}
+                    onChange={(e) => setMode3JackpotText(e.target.value)}
+                    disabled={isSpinning}
+                  />
+                </FormGroup>
+
+                <FormGroup>
+                  <Label htmlFor="mode3JackpotSeed">奖池初始金额 (元)</Label>
+                  <Input
+                    id="mode3JackpotSeed"
+                    type="number"
+                    min="0"
+                    step="1"
+                    value={mode3JackpotSeed}
+                    onChange={(e) => setMode3JackpotSeed(parseFloat(e.target.value) || 0)}
+                    disabled={isSpinning}
+                  />
+                  <small style={{ color: '#666', fontSize: '12px', marginTop: '4px', display: 'block' }}>
+                    头奖开出后奖池恢复为此金额
+                  </small>
+                </FormGroup>
+
+                <FormGroup>
+                  <Label htmlFor="mode3JackpotIncrement">每次抽奖增加 (元)</Label>
+                  <Input
+                    id="mode3JackpotIncrement"
+                    type="number"
+                    min="0"
+                    step="1"
+                    value={mode3JackpotIncrement}
+                    onChange={(e) => setMode3JackpotIncrement(parseFloat(e.target.value) || 0)}
+                    disabled={isSpinning}
+                  />
+                </FormGroup>
+              </div>
+
+              <FormGroup style={{ marginBottom: '20px' }}>
+                <Label htmlFor="mode3LoseText">未中奖文案</Label>
+                <Input
+                  id="mode3LoseText"
+                  type="text"
+                  placeholder="输入未中奖时显示的文案"
+                  value={mode3LoseText}
+                  onChange={(e) => setMode3LoseText(e.target.value)}
+                  disabled={isSpinning}
+                />
+              </FormGroup>
+            </div>
+          )}
         </Section>
         )}
 
         {/* Basic Settings - Only shown when on lottery pages */}
-        {(currentPage === 'lottery1' || currentPage === 'lottery2') && (
+        {(currentPage === 'lottery1' || currentPage === 'lottery2' || currentPage === 'lottery3') && (
         <Section>
           <SectionTitle>基本设置</SectionTitle>
           
@@ -1488,7 +1768,7 @@ const Admin: React.FC = () => {
         )}
 
         {/* Actions - Only shown when on lottery pages */}
-        {(currentPage === 'lottery1' || currentPage === 'lottery2') && (
+        {(currentPage === 'lottery1' || currentPage === 'lottery2' || currentPage === 'lottery3') && (
         <Section>
           <SectionTitle>操作</SectionTitle>
           <ButtonGroup>
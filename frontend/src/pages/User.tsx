@@ -3,7 +3,7 @@ import styled from 'styled-components';
 import SpinnerWheel from '../components/SpinnerWheel';
 import WinnerAnnouncements from '../components/WinnerAnnouncements';
 import WinnerBanner from '../components/WinnerBanner';
-import { apiService, GameConfig, SpinHistory, SpinResult, PrizeOption } from '../services/api';
+import { apiService, AuthError, newIdempotencyKey, GameConfig, SpinHistory, SpinResult, PrizeOption, Jackpot } from '../services/api';
 import { wsService } from '../services/websocket';
 
 const UserContainer = styled.div`
@@ -28,6 +28,7 @@ const GameArea = styled.div`
 
 const WheelSection = styled.div`
   display: flex;
+  flex-direction: column;
   justify-content: center;
   align-items: center;
 `;
@@ -51,6 +52,12 @@ const ErrorMessage = styled.div`
   font-weight: 500;
 `;
 
+const LoginLink = styled.a`
+  color: white;
+  font-weight: 700;
+  margin-left: 8px;
+`;
+
 const LoadingMessage = styled.div`
   color: white;
   text-align: center;
@@ -58,6 +65,23 @@ const LoadingMessage = styled.div`
   margin: 40px 0;
 `;
 
+const JackpotCounter = styled.div`
+  color: #f1c40f;
+  text-align: center;
+  font-size: 48px;
+  font-weight: bold;
+  text-shadow: 0 0 20px rgba(241, 196, 15, 0.6);
+  margin-bottom: 20px;
+
+  span {
+    display: block;
+    color: white;
+    font-size: 20px;
+    font-weight: normal;
+    text-shadow: none;
+  }
+`;
+
 const AnnouncementsSection = styled.div`
   position: fixed;
   left: 20px;
@@ -129,9 +153,22 @@ const ConnectionButton = styled.button`
 `;
 
 
+// Converts a spin event's server timeline to local epoch milliseconds, correcting for clock skew
+const toLocalTimeline = (data: any): { start: number; stop: number; reveal: number; unlock: number } | null => {
+  if (!data || !data.timeline || !data.server_time) {
+    return null;
+  }
+  const offset = Date.now() - Date.parse(data.server_time);
+  return {
+    start: Date.parse(data.timeline.start) + offset,
+    stop: Date.parse(data.timeline.stop) + offset,
+    reveal: Date.parse(data.timeline.reveal) + offset,
+    unlock: Date.parse(data.timeline.unlock) + offset,
+  };
+};
 
 interface UserProps {
-  forcedMode?: number; // Optional forced mode override (1 or 2)
+  forcedMode?: number; // Optional forced mode override (1, 2 or 3)
 }
 
 const User: React.FC<UserProps> = ({ forcedMode }) => {
@@ -143,6 +180,12 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
   const [loading, setLoading] = useState(true);
   const [winnerResult, setWinnerResult] = useState<SpinResult | null>(null);
   const [spinStartTime, setSpinStartTime] = useState<number | null>(null);
+  const [spinDuration, setSpinDuration] = useState<number>(6000);
+  const [jackpot, setJackpot] = useState<Jackpot | null>(null);
+  // Spinning needs a login with the spin permission; null until the status has loaded
+  const [canSpin, setCanSpin] = useState<boolean | null>(null);
+  // Key of a spin whose outcome never arrived; pressing again retries it rather than spinning twice
+  const pendingSpinKeyRef = useRef<string | null>(null);
 
   // Load initial data
   const loadData = useCallback(async () => {
@@ -163,6 +206,30 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
     }
   }, []);
 
+  // Only offer the spin button to a logged-in user allowed to spin
+  useEffect(() => {
+    apiService.getAuthStatus()
+      .then(status => setCanSpin(status.authenticated && !!status.permissions?.includes('spin')))
+      .catch(err => {
+        console.error('Failed to load login status:', err);
+        setCanSpin(false);
+      });
+  }, []);
+
+  // Load the progressive jackpot and follow it as spins add to it
+  useEffect(() => {
+    apiService.getJackpot()
+      .then(setJackpot)
+      .catch(err => console.error('Failed to load jackpot:', err));
+
+    const unsubscribeJackpot = wsService.onJackpotUpdated((data: Jackpot) => {
+      setJackpot(data);
+    });
+    return () => {
+      unsubscribeJackpot();
+    };
+  }, []);
+
   // Initialize WebSocket and load data
   useEffect(() => {
     loadData();
@@ -207,14 +274,24 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
       }
     });
 
-    const unsubscribeSpinStarted = wsService.onSpinStarted(() => {
+    const unsubscribeSpinStarted = wsService.onSpinStarted((data: any) => {
       setIsSpinning(true);
-      setSpinStartTime(Date.now());
+      const timeline = toLocalTimeline(data);
+      if (timeline) {
+        setSpinStartTime(timeline.start);
+        setSpinDuration(timeline.stop - timeline.start);
+      } else {
+        setSpinStartTime(Date.now());
+      }
       setError('');
     });
 
     const unsubscribeSpinCompleted = wsService.onSpinCompleted((data: any) => {
       const { result, config: newConfig } = data;
+      // Follow the server's timeline so every screen reveals and unlocks together
+      const timeline = toLocalTimeline(data);
+      const revealIn = timeline ? Math.max(timeline.reveal - Date.now(), 0) : 6000;
+      const unlockIn = timeline ? Math.max(timeline.unlock - Date.now(), 0) : 7000;
       setConfig(newConfig);
       setWinningIndex(result.index);
       
@@ -241,14 +318,14 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
         
         // Show winner banner (always works) - user can manually play audio
         setWinnerResult(correctedResult);
-      }, 6000);
+      }, revealIn);
 
       // Stop spinning after animation completes
       setTimeout(() => {
         setIsSpinning(false);
         setWinningIndex(undefined);
         setSpinStartTime(null);
-      }, 7000);
+      }, unlockIn);
     });
 
     const unsubscribeStateUpdated = wsService.onStateUpdated((data: GameConfig) => {
@@ -278,7 +355,7 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
 
   // Handle spin request
   const handleSpin = async () => {
-    if (!config || isSpinning || config.remaining_spins <= 0) {
+    if (!config || isSpinning || config.remaining_spins <= 0 || !canSpin) {
       return;
     }
 
@@ -290,11 +367,34 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
         window.AudioManager.ensureUnlocked();
       }
       
-      await apiService.spin();
+      if (!pendingSpinKeyRef.current) {
+        pendingSpinKeyRef.current = newIdempotencyKey();
+      }
+      const response = await apiService.spin(pendingSpinKeyRef.current);
+      pendingSpinKeyRef.current = null;
+      if (response.replayed) {
+        // The spin already ran on an earlier attempt; its events may have been missed
+        console.log('Spin was already done, reloading its result');
+        loadData();
+      }
     } catch (err: any) {
       console.error('Spin failed:', err);
-      setError(err.message || '抽奖失败，请重试');
       setIsSpinning(false);
+      // fetch only throws TypeError when no response arrived; any answer settles the key
+      if (!(err instanceof TypeError)) {
+        pendingSpinKeyRef.current = null;
+      }
+      if (err instanceof AuthError && err.status === 401) {
+        // Session expired or was revoked; log in again and come back here
+        window.location.assign(apiService.loginUrl());
+        return;
+      }
+      if (err instanceof AuthError) {
+        setCanSpin(false);
+        setError('当前账号没有抽奖权限，请联系管理员');
+        return;
+      }
+      setError(err.message || '抽奖失败，请重试');
     }
   };
 
@@ -375,7 +475,7 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
     }
     
     // Better fallback logic
-    if (mode === 2) {
+    if (mode === 2 || mode === 3) {
       console.log('🎮 Mode 2 fallback: 再接再厉');
       return '再接再厉';
     } else {
@@ -410,6 +510,20 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
       const options = config.mode1_options || [];
       console.log(`🎁 Mode 1 options:`, options);
       return options;
+    } else if (activeMode === 3) {
+      // Mode 3: Progressive jackpot, one jackpot segment after 11 losing ones
+      const options: PrizeOption[] = [];
+      const jackpotRate = config.mode3_jackpot_rate ?? 0.5;
+      const loseRate = (100 - jackpotRate) / 11;
+      const loseText = config.mode3_lose_text || '再接再厉';
+      const jackpotText = config.mode3_jackpot_text || '头奖!';
+
+      for (let i = 0; i < 11; i++) {
+        options.push({ text: loseText, probability: loseRate });
+      }
+      options.push({ text: jackpotText, probability: jackpotRate });
+      console.log(`🎁 Mode 3 options:`, options);
+      return options;
     } else {
       // Mode 2: Configurable options
       const options: PrizeOption[] = [];
@@ -446,6 +560,7 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
   }
 
   const wheelOptions = getWheelOptions();
+  const showJackpot = (forcedMode ?? config.mode) === 3 && jackpot !== null;
   
   // Connection status helpers
   const getStatusText = (status: string) => {
@@ -483,6 +598,11 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
       </ConnectionStatus>
       
       {error && <ErrorMessage>{error}</ErrorMessage>}
+      {canSpin === false && !error && (
+        <ErrorMessage>
+          需要登录后才能抽奖 <LoginLink href={apiService.loginUrl()}>登录</LoginLink>
+        </ErrorMessage>
+      )}
 
       <GameArea>
         <AnnouncementsSection>
@@ -490,6 +610,12 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
         </AnnouncementsSection>
 
         <WheelSection>
+          {showJackpot && (
+            <JackpotCounter>
+              <span>当前奖池</span>
+              ¥{jackpot!.pool.toFixed(2)}
+            </JackpotCounter>
+          )}
           <WheelWrapper>
             <SpinnerWheel
               options={wheelOptions}
@@ -497,7 +623,8 @@ const User: React.FC<UserProps> = ({ forcedMode }) => {
               isSpinning={isSpinning}
               winningIndex={winningIndex}
               spinStartTime={spinStartTime}
-              disabled={config.remaining_spins <= 0}
+              spinDuration={spinDuration}
+              disabled={config.remaining_spins <= 0 || !canSpin}
             />
           </WheelWrapper>
         </WheelSection>
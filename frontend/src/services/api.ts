@@ -6,9 +6,18 @@ export interface GameConfig {
   mode2_win_text: string;
   mode2_lose_text: string;
   mode2_win_rate: number;
+  mode3_jackpot_text: string;
+  mode3_lose_text: string;
+  mode3_jackpot_rate: number;
+  mode3_jackpot_seed: number;
+  mode3_jackpot_increment: number;
   current_player: number;
+  current_player_id?: string;
   remaining_spins: number;
   current_page: string;
+  spin_duration_ms: number;
+  reveal_delay_ms: number;
+  cooldown_ms: number;
 }
 
 export interface PrizeOption {
@@ -18,10 +27,48 @@ export interface PrizeOption {
 
 export interface SpinResult {
   player: number;
+  player_id?: string;
+  table?: string;
   prize: string;
   index: number;
   timestamp: string;
   mode: number;
+  type?: 'prize' | 'lose' | 'jackpot';
+  jackpot_amount?: number;
+  voucher_code?: string;
+}
+
+export interface Jackpot {
+  pool: number;
+  updated: string;
+  last_win?: {
+    amount: number;
+    player: number;
+    player_id?: string;
+    table?: string;
+    timestamp: string;
+  };
+}
+
+export interface JackpotUpdatedEvent extends Jackpot {
+  change: 'grew' | 'won' | 'adjusted';
+  increment: number;
+}
+
+export interface Voucher {
+  code: string;
+  qr_payload: string;
+  prize: string;
+  mode: number;
+  player_id?: string;
+  table?: string;
+  status: 'issued' | 'redeemed' | 'void';
+  expired: boolean;
+  issued: string;
+  expires: string;
+  redeemed?: string;
+  voided?: string;
+  void_reason?: string;
 }
 
 export interface SpinHistory {
@@ -34,13 +81,38 @@ export interface ConfigUpdateRequest {
   mode2_win_text?: string;
   mode2_lose_text?: string;
   mode2_win_rate?: number;
+  mode3_jackpot_text?: string;
+  mode3_lose_text?: string;
+  mode3_jackpot_rate?: number;
+  mode3_jackpot_seed?: number;
+  mode3_jackpot_increment?: number;
   current_player?: number;
+  current_player_id?: string;
   remaining_spins?: number;
+  expected_remaining_spins?: number; // Spins the form was based on; the save is refused if play changed them
+  spin_duration_ms?: number;
+  reveal_delay_ms?: number;
+  cooldown_ms?: number;
 }
 
 export interface SpinResponse {
   result: SpinResult;
   config: GameConfig;
+  voucher?: Voucher;
+  replayed?: boolean; // Set from the Idempotent-Replayed header: an earlier attempt already spun
+}
+
+// Attempts at one spin before giving up; retries send the same Idempotency-Key, so a
+// spin whose response was lost is returned again instead of spinning twice
+const SPIN_ATTEMPTS = 3;
+const SPIN_RETRY_DELAY_MS = 500;
+
+// newIdempotencyKey makes the key for one user action. crypto.randomUUID needs HTTPS,
+// which displays on the LAN often don't have, so the key is built from getRandomValues.
+export function newIdempotencyKey(): string {
+  const bytes = new Uint8Array(16);
+  window.crypto.getRandomValues(bytes);
+  return Array.from(bytes, b => b.toString(16).padStart(2, '0')).join('');
 }
 
 // Restaurant management interfaces
@@ -89,8 +161,60 @@ export interface RestaurantData {
   recommendations: Recommendation[];
 }
 
+// ConflictError is thrown when a save loses to someone else's edit (412). It carries the
+// current state and its ETag; the next save only goes through once the user has seen it.
+export class ConflictError<T> extends Error {
+  current: T;
+  etag: string;
+
+  constructor(message: string, current: T, etag: string) {
+    super(message);
+    Object.setPrototypeOf(this, ConflictError.prototype);
+    this.name = 'ConflictError';
+    this.current = current;
+    this.etag = etag;
+  }
+}
+
+// AuthError is thrown when a request needs a login (401) or a permission the user lacks (403)
+export class AuthError extends Error {
+  status: number;
+  permission?: string;
+
+  constructor(message: string, status: number, permission?: string) {
+    super(message);
+    Object.setPrototypeOf(this, AuthError.prototype);
+    this.name = 'AuthError';
+    this.status = status;
+    this.permission = permission;
+  }
+}
+
+// authError turns a 401/403 response body into an AuthError, or returns null for other failures
+export function authError(status: number, error: any): AuthError | null {
+  if (status === 401 && error?.auth_required) {
+    return new AuthError(error.error || 'Authentication required', status);
+  }
+  if (status === 403 && error?.permission_denied) {
+    return new AuthError(error.error || 'Permission denied', status, error.required_permission);
+  }
+  return null;
+}
+
+export interface AuthStatus {
+  setup_required: boolean;
+  authenticated: boolean;
+  username?: string;
+  role?: string;
+  permissions?: string[];
+}
+
 class ApiService {
   private baseUrl: string;
+  // ETags from the last read of each editable resource, sent back as If-Match.
+  // Saving is refused until the resource has been read; "*" is never sent.
+  private configETag: string | null = null;
+  private restaurantETag: string | null = null;
 
   constructor() {
     // Use current origin in production, localhost:8080 in development
@@ -104,41 +228,92 @@ class ApiService {
     if (!response.ok) {
       throw new Error(`Failed to get config: ${response.statusText}`);
     }
+    this.configETag = response.headers.get('ETag');
     return response.json();
   }
 
   async updateConfig(update: ConfigUpdateRequest): Promise<GameConfig> {
+    if (!this.configETag) {
+      throw new Error('Config has not been loaded; reload the page before saving');
+    }
     const response = await fetch(`${this.baseUrl}/api/config`, {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
+        'If-Match': this.configETag,
       },
       body: JSON.stringify(update),
     });
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to update config: ${response.statusText}`);
+      if (response.status === 412 && error.etag) {
+        // Someone else changed the config; keep our tag until the user has seen their version
+        throw new ConflictError<GameConfig>(error.error, error.current, error.etag);
+      }
+      throw authError(response.status, error) || new Error(error.error || `Failed to update config: ${response.statusText}`);
     }
     
+    this.configETag = response.headers.get('ETag');
     return response.json();
   }
 
-  async spin(): Promise<SpinResponse> {
-    const response = await fetch(`${this.baseUrl}/api/spin`, {
-      method: 'POST',
-      headers: {
-        'Content-Type': 'application/json',
-      },
-      body: JSON.stringify({}),
-    });
+  // acceptConfig takes the tag of a conflicting config once the user has looked at it
+  acceptConfig(conflict: ConflictError<GameConfig>) {
+    this.configETag = conflict.etag;
+  }
+
+  async getAuthStatus(): Promise<AuthStatus> {
+    const response = await fetch(`${this.baseUrl}/api/auth/status`);
+    if (!response.ok) {
+      throw new Error(`Failed to get login status: ${response.statusText}`);
+    }
+    return response.json();
+  }
+
+  // loginUrl is the login page, which returns to the current page afterwards
+  loginUrl(): string {
+    return `${this.baseUrl}/login?next=${encodeURIComponent(window.location.pathname)}`;
+  }
+
+  // spin asks for one spin. Pass the same idempotencyKey when the user retries the same action;
+  // network failures and gateway errors are retried here with it.
+  async spin(idempotencyKey: string = newIdempotencyKey()): Promise<SpinResponse> {
+    const response = await this.postSpin(idempotencyKey);
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to spin: ${response.statusText}`);
+      throw authError(response.status, error) || new Error(error.error || `Failed to spin: ${response.statusText}`);
     }
     
-    return response.json();
+    const data: SpinResponse = await response.json();
+    data.replayed = response.headers.get('Idempotent-Replayed') === 'true';
+    return data;
+  }
+
+  // postSpin sends the spin request, retrying lost requests and gateway errors with the same key
+  private async postSpin(idempotencyKey: string): Promise<Response> {
+    for (let attempt = 1; ; attempt++) {
+      try {
+        const response = await fetch(`${this.baseUrl}/api/spin`, {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            'Idempotency-Key': idempotencyKey,
+          },
+          body: JSON.stringify({}),
+        });
+        if (response.status < 502 || response.status > 504 || attempt >= SPIN_ATTEMPTS) {
+          return response;
+        }
+      } catch (err) {
+        // The request may have reached the server; only the same key makes retrying safe
+        if (attempt >= SPIN_ATTEMPTS) {
+          throw err;
+        }
+      }
+      await new Promise(resolve => setTimeout(resolve, SPIN_RETRY_DELAY_MS * attempt));
+    }
   }
 
   async getHistory(): Promise<SpinHistory> {
@@ -149,6 +324,14 @@ class ApiService {
     return response.json();
   }
 
+  async getJackpot(): Promise<Jackpot> {
+    const response = await fetch(`${this.baseUrl}/api/jackpot`);
+    if (!response.ok) {
+      throw new Error(`Failed to get jackpot: ${response.statusText}`);
+    }
+    return response.json();
+  }
+
   async resetGame(): Promise<{ message: string; config: GameConfig }> {
     const response = await fetch(`${this.baseUrl}/api/reset`, {
       method: 'POST',
@@ -160,7 +343,7 @@ class ApiService {
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to reset game: ${response.statusText}`);
+      throw authError(response.status, error) || new Error(error.error || `Failed to reset game: ${response.statusText}`);
     }
     
     return response.json();
@@ -173,26 +356,40 @@ class ApiService {
     if (!response.ok) {
       throw new Error(`Failed to get restaurant data: ${response.statusText}`);
     }
+    this.restaurantETag = response.headers.get('ETag');
     return response.json();
   }
 
   async updateRestaurantConfig(config: RestaurantConfig): Promise<RestaurantConfig> {
+    if (!this.restaurantETag) {
+      throw new Error('Restaurant config has not been loaded; reload the page before saving');
+    }
     const response = await fetch(`${this.baseUrl}/api/restaurant/config`, {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
+        'If-Match': this.restaurantETag,
       },
       body: JSON.stringify(config),
     });
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to update restaurant config: ${response.statusText}`);
+      if (response.status === 412 && error.etag) {
+        throw new ConflictError<RestaurantConfig>(error.error, error.current, error.etag);
+      }
+      throw authError(response.status, error) || new Error(error.error || `Failed to update restaurant config: ${response.statusText}`);
     }
     
+    this.restaurantETag = response.headers.get('ETag');
     return response.json();
   }
 
+  // acceptRestaurantConfig takes the tag of a conflicting restaurant config once the user has looked at it
+  acceptRestaurantConfig(conflict: ConflictError<RestaurantConfig>) {
+    this.restaurantETag = conflict.etag;
+  }
+
   async uploadAdvertisement(file: File, name?: string): Promise<Advertisement> {
     const formData = new FormData();
     formData.append('image', file);
@@ -207,7 +404,7 @@ class ApiService {
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to upload advertisement: ${response.statusText}`);
+      throw authError(response.status, error) || new Error(error.error || `Failed to upload advertisement: ${response.statusText}`);
     }
     
     return response.json();
@@ -220,7 +417,7 @@ class ApiService {
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to delete advertisement: ${response.statusText}`);
+      throw authError(response.status, error) || new Error(error.error || `Failed to delete advertisement: ${response.statusText}`);
     }
     
     return response.json();
@@ -237,7 +434,7 @@ class ApiService {
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to update menu item: ${response.statusText}`);
+      throw authError(response.status, error) || new Error(error.error || `Failed to update menu item: ${response.statusText}`);
     }
     
     return response.json();
@@ -254,7 +451,7 @@ class ApiService {
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to add recommendation: ${response.statusText}`);
+      throw authError(response.status, error) || new Error(error.error || `Failed to add recommendation: ${response.statusText}`);
     }
     
     return response.json();
@@ -271,7 +468,7 @@ class ApiService {
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to update recommendation: ${response.statusText}`);
+      throw authError(response.status, error) || new Error(error.error || `Failed to update recommendation: ${response.statusText}`);
     }
     
     return response.json();
@@ -284,7 +481,7 @@ class ApiService {
     
     if (!response.ok) {
       const error = await response.json();
-      throw new Error(error.error || `Failed to delete recommendation: ${response.statusText}`);
+      throw authError(response.status, error) || new Error(error.error || `Failed to delete recommendation: ${response.statusText}`);
     }
     
     return response.json();
@@ -198,6 +198,10 @@ class WebSocketService {
     return this.on('state_updated', handler);
   }
 
+  onJackpotUpdated(handler: WebSocketEventHandler): () => void {
+    return this.on('jackpot_updated', handler);
+  }
+
   onConnected(handler: WebSocketEventHandler): () => void {
     return this.on('connected', handler);
   }
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math/rand"
 	"mime/multipart"
 	"net/http"
@@ -10,7 +12,6 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"spinner-wheel/models"
@@ -22,17 +23,42 @@ import (
 // APIHandler handles all API requests
 type APIHandler struct {
 	storage     *storage.Storage
-	spinMutex   sync.Mutex
+	state       *gameStateManager // Game config and spin lock; all changes go through it
+	scheduler   *pageScheduler    // Pending automatic page switches
+	playlist    *displayPlaylist  // Weekly display schedule and manual override
 	wsHandler   *WebSocketHandler
-	isSpinning  bool
-	spinStarted time.Time
+	idempotency *idempotencyCache // Spin results by Idempotency-Key
+	presence    *devicePresence   // Last-seen times of registered devices, flushed to storage periodically
+	publicURL   string            // Base of spin link URLs; empty uses the request's address
 }
 
-// NewAPIHandler creates a new API handler
-func NewAPIHandler(store *storage.Storage) *APIHandler {
-	return &APIHandler{
-		storage: store,
+// NewAPIHandler creates a new API handler, loading the game state, page switches, display schedule and registered devices from storage
+func NewAPIHandler(store *storage.Storage) (*APIHandler, error) {
+	state, err := newGameStateManager(store)
+	if err != nil {
+		return nil, err
+	}
+	scheduler, err := newPageScheduler(store)
+	if err != nil {
+		return nil, err
+	}
+	playlist, err := newDisplayPlaylist(store)
+	if err != nil {
+		return nil, err
 	}
+	devices, err := store.GetDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	return &APIHandler{
+		storage:     store,
+		state:       state,
+		scheduler:   scheduler,
+		playlist:    playlist,
+		idempotency: newIdempotencyCache(),
+		presence:    newDevicePresence(devices),
+	}, nil
 }
 
 // SetWebSocketHandler sets the WebSocket handler for broadcasting
@@ -40,206 +66,346 @@ func (h *APIHandler) SetWebSocketHandler(ws *WebSocketHandler) {
 	h.wsHandler = ws
 }
 
-// GetConfig returns the current game configuration
+// GetConfig returns the current game configuration with its ETag
 func (h *APIHandler) GetConfig(c *gin.Context) {
-	config, err := h.storage.GetConfig()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get config: " + err.Error()})
-		return
-	}
+	state := h.state.Snapshot()
 
+	c.Header("ETag", state.ETag())
 	c.Header("Content-Type", "application/json; charset=utf-8")
-	c.JSON(http.StatusOK, config)
+	c.JSON(http.StatusOK, state.Config)
 }
 
-// UpdateConfig updates the game configuration
+// UpdateConfig updates the game configuration if it still matches the If-Match ETag
 func (h *APIHandler) UpdateConfig(c *gin.Context) {
-	// Block config updates during active spins
-	if h.isSpinning {
-		c.JSON(http.StatusLocked, gin.H{
-			"error":     "Cannot update configuration while spin is in progress",
-			"spinning":  true,
-			"spin_time": time.Since(h.spinStarted).Seconds(),
-		})
-		return
-	}
-
 	var updateReq models.ConfigUpdateRequest
 	if err := c.ShouldBindJSON(&updateReq); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
 
-	// Get current config
-	config, err := h.storage.GetConfig()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current config: " + err.Error()})
+	// Staff may change the current player; the venue-wide spins are free spins for anyone,
+	// so they need edit_config like everything else
+	if (updateReq.ChangesGameSettings() || updateReq.RemainingSpins != nil) && !requirePermission(c, models.PermissionEditConfig) {
 		return
 	}
 
-	// Update fields if provided
-	if updateReq.Mode != nil {
-		config.Mode = *updateReq.Mode
-	}
-	if updateReq.Mode1Options != nil {
-		config.Mode1Options = updateReq.Mode1Options
-	}
-	if updateReq.Mode2WinText != nil {
-		config.Mode2WinText = *updateReq.Mode2WinText
-	}
-	if updateReq.Mode2LoseText != nil {
-		config.Mode2LoseText = *updateReq.Mode2LoseText
-	}
-	if updateReq.Mode2WinRate != nil {
-		config.Mode2WinRate = *updateReq.Mode2WinRate
-	}
-	if updateReq.CurrentPlayer != nil {
-		config.CurrentPlayer = *updateReq.CurrentPlayer
-	}
-	if updateReq.RemainingSpins != nil {
-		config.RemainingSpins = *updateReq.RemainingSpins
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
 	}
 
-	// Save updated config
-	if err := h.storage.SaveConfig(config); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to save config: " + err.Error()})
+	before, after, err := h.updateConfig(updateReq, ifMatch)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
+	config := after.ConfigCopy()
+	h.audit(auditActor(c), models.AuditConfigUpdate, "", before, config)
+
+	c.Header("ETag", after.ETag())
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, config)
+}
+
+// updateConfig applies a partial configuration update and broadcasts it, returning the config before and the state after.
+// A non-empty ifMatch refuses the update if the game settings changed since the caller read that ETag.
+func (h *APIHandler) updateConfig(updateReq models.ConfigUpdateRequest, ifMatch string) (*models.GameConfig, gameState, error) {
+	before, after, err := h.state.CompareAndSwap(h.state.VersionOf(ifMatch), func(tx *gameTx) error {
+		// Block config updates during active spins
+		if tx.Spinning {
+			return spinLockedError("Cannot update configuration while spin is in progress", tx.Timeline)
+		}
+		// Spins change through play without a new ETag, so an edit based on an old count is caught here
+		if updateReq.ExpectedRemainingSpins != nil && *updateReq.ExpectedRemainingSpins != tx.Config.RemainingSpins {
+			return staleStateError(tx.gameState)
+		}
+
+		// Update fields if provided
+		config := &tx.Config
+		if updateReq.Mode != nil {
+			config.Mode = *updateReq.Mode
+		}
+		if updateReq.Mode1Options != nil {
+			config.Mode1Options = updateReq.Mode1Options
+		}
+		if updateReq.Mode2WinText != nil {
+			config.Mode2WinText = *updateReq.Mode2WinText
+		}
+		if updateReq.Mode2LoseText != nil {
+			config.Mode2LoseText = *updateReq.Mode2LoseText
+		}
+		if updateReq.Mode2WinRate != nil {
+			config.Mode2WinRate = *updateReq.Mode2WinRate
+		}
+		if updateReq.Mode3JackpotText != nil {
+			config.Mode3JackpotText = *updateReq.Mode3JackpotText
+		}
+		if updateReq.Mode3LoseText != nil {
+			config.Mode3LoseText = *updateReq.Mode3LoseText
+		}
+		if updateReq.Mode3JackpotRate != nil {
+			config.Mode3JackpotRate = *updateReq.Mode3JackpotRate
+		}
+		if updateReq.Mode3JackpotSeed != nil {
+			config.Mode3JackpotSeed = *updateReq.Mode3JackpotSeed
+		}
+		if updateReq.Mode3JackpotIncrement != nil {
+			config.Mode3JackpotIncrement = *updateReq.Mode3JackpotIncrement
+		}
+		if updateReq.CurrentPlayer != nil {
+			config.CurrentPlayer = *updateReq.CurrentPlayer
+		}
+		if updateReq.RemainingSpins != nil {
+			config.RemainingSpins = *updateReq.RemainingSpins
+		}
+		if updateReq.CurrentPlayerID != nil {
+			if *updateReq.CurrentPlayerID != "" {
+				if _, err := h.storage.GetPlayer(*updateReq.CurrentPlayerID); err != nil {
+					return newAPIError(http.StatusBadRequest, "Invalid player: "+err.Error())
+				}
+			}
+			config.CurrentPlayerID = *updateReq.CurrentPlayerID
+		}
+		if updateReq.SpinDurationMs != nil {
+			config.SpinDurationMs = *updateReq.SpinDurationMs
+		}
+		if updateReq.RevealDelayMs != nil {
+			config.RevealDelayMs = *updateReq.RevealDelayMs
+		}
+		if updateReq.CooldownMs != nil {
+			config.CooldownMs = *updateReq.CooldownMs
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, gameState{}, err
+	}
 
 	// Broadcast config update
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "config_updated",
-			Data: config,
+			Type: models.EventConfigUpdated,
+			Data: after.ConfigCopy(),
 		})
 	}
 
-	c.Header("Content-Type", "application/json; charset=utf-8")
-	c.JSON(http.StatusOK, config)
+	return before.ConfigCopy(), after, nil
 }
 
-// Spin handles spin requests
+// Spin handles spin requests. With an Idempotency-Key header, retries of the same request
+// return the original result instead of spinning again.
 func (h *APIHandler) Spin(c *gin.Context) {
-	// Prevent concurrent spins
-	h.spinMutex.Lock()
-	defer h.spinMutex.Unlock()
-
-	// Check if already spinning
-	if h.isSpinning {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Spin already in progress"})
-		return
+	var (
+		result   *models.SpinResult
+		config   *models.GameConfig
+		replayed bool
+		err      error
+	)
+	if key := c.GetHeader(IdempotencyKeyHeader); key != "" {
+		result, config, replayed, err = h.idempotentSpinRequest(c, key)
+	} else {
+		result, config, err = h.performSpin(auditActor(c), "")
 	}
-
-	// Get current config
-	config, err := h.storage.GetConfig()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get config: " + err.Error()})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	// Check if spins remaining
-	if config.RemainingSpins <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No spins remaining"})
-		return
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	response := gin.H{
+		"result": result,
+		"config": config,
+	}
+	if result.VoucherCode != "" {
+		if voucher, err := h.storage.GetVoucher(result.VoucherCode); err == nil {
+			response["voucher"] = voucher
+		}
 	}
 
-	// Set spinning state
-	h.isSpinning = true
-	h.spinStarted = time.Now()
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, response)
+}
 
-	// Broadcast spin started with lock state
-	if h.wsHandler != nil {
-		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "spin_started",
-			Data: gin.H{
-				"player":      config.CurrentPlayer,
-				"is_spinning": true,
-			},
-		})
-	}
+// performSpin runs one spin under the spin lock, held by actor, and broadcasts its lifecycle.
+// The spin comes from playerID's allowance, or the selected player's when playerID is empty.
+func (h *APIHandler) performSpin(actor models.AuditActor, playerID string) (*models.SpinResult, *models.GameConfig, error) {
+	var (
+		result  models.SpinResult
+		player  *models.Player
+		voucher *models.Voucher
+		jackpot *models.Jackpot
+	)
+	_, after, err := h.state.Update(func(tx *gameTx) error {
+		// Prevent concurrent spins
+		if tx.Spinning {
+			return newAPIError(http.StatusBadRequest, "Spin already in progress")
+		}
 
-	// Determine winning segment
-	var winningIndex int
-	var winningPrize string
+		// Spins come from the player's allowance, or the venue-wide counter without one
+		if playerID == "" {
+			playerID = tx.Config.CurrentPlayerID
+		}
+		if playerID != "" {
+			selected, err := h.storage.GetPlayer(playerID)
+			if err != nil {
+				return newAPIError(http.StatusBadRequest, "Player not found: "+err.Error())
+			}
+			if selected.RemainingSpins() <= 0 {
+				return newAPIError(http.StatusBadRequest, "No spins remaining for this player")
+			}
+			player = selected
+		} else if tx.Config.RemainingSpins <= 0 {
+			return newAPIError(http.StatusBadRequest, "No spins remaining")
+		}
 
-	if config.Mode == 1 {
-		// Mode 1: Use probabilities
-		winningIndex, winningPrize = h.spinMode1(config.Mode1Options)
-	} else {
-		// Mode 2: Simple 5% win rate
-		winningIndex, winningPrize = h.spinMode2(config)
-	}
+		// Determine winning segment
+		var winningIndex int
+		var winningPrize string
+
+		if tx.Config.Mode == 1 {
+			// Mode 1: Use probabilities
+			winningIndex, winningPrize = h.spinMode1(tx.Config.Mode1Options)
+		} else if tx.Config.Mode == 3 {
+			// Mode 3: Every spin adds to the jackpot pool before the draw
+			current, err := h.storage.GetJackpot()
+			if err != nil {
+				return fmt.Errorf("failed to get jackpot: %w", err)
+			}
+			jackpot = current
+			jackpot.Pool += tx.Config.Mode3JackpotIncrement
+			winningIndex, winningPrize = h.spinMode3(&tx.Config)
+		} else {
+			// Mode 2: Simple 5% win rate
+			winningIndex, winningPrize = h.spinMode2(&tx.Config)
+		}
+
+		// Create spin result
+		result = models.SpinResult{
+			Player:    tx.Config.CurrentPlayer,
+			Prize:     winningPrize,
+			Index:     winningIndex,
+			Timestamp: time.Now(),
+			Mode:      tx.Config.Mode,
+			Type:      models.SpinResultType(tx.Config.Mode, winningIndex),
+		}
+		if player != nil {
+			result.PlayerID = player.ID
+			result.Table = player.Table
+		} else {
+			tx.Config.RemainingSpins--
+		}
+
+		// A jackpot pays out the whole pool, which starts again from the seed
+		if jackpot != nil {
+			jackpot.Updated = result.Timestamp
+			if result.Type == models.ResultTypeJackpot {
+				result.JackpotAmount = jackpot.Pool
+				jackpot.LastWin = &models.JackpotWin{
+					Amount:    jackpot.Pool,
+					Player:    result.Player,
+					PlayerID:  result.PlayerID,
+					Table:     result.Table,
+					Timestamp: result.Timestamp,
+				}
+				jackpot.Pool = tx.Config.Mode3JackpotSeed
+			}
+		}
 
-	// Create spin result
-	result := models.SpinResult{
-		Player:    config.CurrentPlayer,
-		Prize:     winningPrize,
-		Index:     winningIndex,
-		Timestamp: time.Now(),
-		Mode:      config.Mode,
+		// Winning spins earn a prize voucher, saved with the result
+		if models.IsWinningResult(&result) {
+			issued, err := h.issueVoucher(result)
+			if err != nil {
+				return fmt.Errorf("failed to issue voucher: %w", err)
+			}
+			voucher = issued
+			result.VoucherCode = voucher.Code
+		}
+
+		// Take the spin lock; history, config, the player's allowance, the voucher and the jackpot are saved together
+		tx.Spinning = true
+		tx.Timeline = tx.Config.SpinTimeline(result.Timestamp)
+		tx.Holder = &actor
+		tx.persist = func(config *models.GameConfig) error {
+			err := h.storage.SaveSpin(config, result, voucher, jackpot)
+			if errors.Is(err, storage.ErrNoSpinsRemaining) {
+				return newAPIError(http.StatusBadRequest, "No spins remaining for this player")
+			}
+			if errors.Is(err, storage.ErrVoucherBookFull) {
+				return newAPIError(http.StatusServiceUnavailable, "Voucher book is full; redeem or void outstanding vouchers first")
+			}
+			var partial *storage.PartialWriteError
+			if errors.As(err, &partial) {
+				log.Printf("Spin for player %d was not saved: %v", result.Player, err)
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Add to history
-	if err := h.storage.AddSpinResult(result); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save result: " + err.Error()})
-		return
+	if player != nil {
+		player.SpinsUsed++
+		player.Updated = result.Timestamp
+		h.broadcastPlayerUpdated(*player)
 	}
 
-	// Update config (decrement spins)
-	config.RemainingSpins--
-	if err := h.storage.SaveConfig(config); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update config: " + err.Error()})
-		return
+	config := after.ConfigCopy()
+
+	if jackpot != nil {
+		h.broadcastSpinJackpot(*jackpot, result, after.Timeline, config.Mode3JackpotIncrement)
 	}
 
-	// Broadcast spin completed but keep lock active during animation
+	// Broadcast spin started with lock state, then the result while keeping the lock active during animation.
+	// Both carry the timeline so every screen stops, reveals and unlocks at the same moment.
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "spin_completed",
-			Data: gin.H{
-				"result":      result,
-				"config":      config,
-				"is_spinning": true, // Keep spinning state active
+			Type: models.EventSpinStarted,
+			Data: models.SpinStartedEvent{
+				Player:     result.Player,
+				IsSpinning: true,
+				Timeline:   after.Timeline,
+				ServerTime: time.Now(),
+			},
+		})
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventSpinCompleted,
+			Data: models.SpinCompletedEvent{
+				Result:     result.Redacted(), // Displays show the prize, never the voucher code
+				Config:     config,
+				IsSpinning: true, // Keep spinning state active
+				Timeline:   after.Timeline,
+				ServerTime: time.Now(),
 			},
 		})
 	}
 
-	// Clear spinning state after animation completes (8 seconds)
+	// Clear spinning state once the timeline unlocks, then move the queue along
 	go func() {
-		time.Sleep(8 * time.Second)
-		h.spinMutex.Lock()
-		h.isSpinning = false
-		h.spinStarted = time.Time{}
-		h.spinMutex.Unlock()
-		
-		// Broadcast lock cleared
-		if h.wsHandler != nil {
-			h.wsHandler.Broadcast(models.WebSocketMessage{
-				Type: "spin_lock_cleared",
-				Data: gin.H{
-					"is_spinning": false,
-				},
-			})
+		if h.clearSpinLock(after.Timeline) {
+			h.autoAdvanceQueue()
 		}
 	}()
 
-	// Check if auto-switch to advertisement page is enabled
-	go h.handleAutoSwitchAfterSpin(config)
+	// Schedule the advertisement break if auto-switch is enabled
+	h.scheduleAutoSwitchAfterSpin(after.Timeline)
 
-	c.Header("Content-Type", "application/json; charset=utf-8")
-	c.JSON(http.StatusOK, gin.H{
-		"result": result,
-		"config": config,
-	})
+	return &result, config, nil
 }
 
-// GetHistory returns the spin history
+// GetHistory returns the spin history, without voucher codes since it is public
 func (h *APIHandler) GetHistory(c *gin.Context) {
 	history, err := h.storage.GetHistory()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get history: " + err.Error()})
 		return
 	}
+	for i := range history.Results {
+		history.Results[i] = history.Results[i].Redacted()
+	}
 
 	c.Header("Content-Type", "application/json; charset=utf-8")
 	c.JSON(http.StatusOK, history)
@@ -247,32 +413,34 @@ func (h *APIHandler) GetHistory(c *gin.Context) {
 
 // Reset resets the game state
 func (h *APIHandler) Reset(c *gin.Context) {
-	// Block reset during active spins
-	if h.isSpinning {
-		c.JSON(http.StatusLocked, gin.H{
-			"error":     "Cannot reset game while spin is in progress",
-			"spinning":  true,
-			"spin_time": time.Since(h.spinStarted).Seconds(),
-		})
-		return
-	}
+	var beforeHistory *models.SpinHistory
+	before, after, err := h.state.Update(func(tx *gameTx) error {
+		// Block reset during active spins
+		if tx.Spinning {
+			return spinLockedError("Cannot reset game while spin is in progress", tx.Timeline)
+		}
 
-	if err := h.storage.ResetGame(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset game: " + err.Error()})
-		return
-	}
+		// Keep what the reset wipes in the audit log
+		beforeHistory, _ = h.storage.GetHistory()
 
-	// Get updated config
-	config, err := h.storage.GetConfig()
+		tx.Config.CurrentPlayer = 1
+		tx.Config.CurrentPlayerID = ""
+		tx.Config.RemainingSpins = 100
+		tx.persist = h.storage.ResetGame
+		return nil
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get config after reset: " + err.Error()})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
+	config := after.ConfigCopy()
+	h.audit(auditActor(c), models.AuditGameReset, "", gin.H{"config": before.Config, "history": beforeHistory}, gin.H{"config": config})
+
 	// Broadcast state updated
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "state_updated",
+			Type: models.EventStateUpdated,
 			Data: config,
 		})
 	}
@@ -312,7 +480,7 @@ func (h *APIHandler) spinMode2(config *models.GameConfig) (int, string) {
 	winRate := config.Mode2WinRate / 100.0
 	if rand.Float64() < winRate {
 		// Winner! Always place at index 11 to match frontend layout
-		return 11, config.Mode2WinText
+		return models.Mode2WinIndex, config.Mode2WinText
 	}
 
 	// No win - place at random losing position (indices 0-10)
@@ -320,146 +488,97 @@ func (h *APIHandler) spinMode2(config *models.GameConfig) (int, string) {
 	return loseIndex, config.Mode2LoseText
 }
 
-// handleAutoSwitchAfterSpin handles automatic page switching after spin completion
-func (h *APIHandler) handleAutoSwitchAfterSpin(config *models.GameConfig) {
-	// Get restaurant configuration to check if auto-switch is enabled
-	restaurantData, err := h.storage.GetRestaurantData()
-	if err != nil {
-		return // Silently fail - don't interrupt main flow
-	}
-
-	// Check if auto-switch is enabled
-	if !restaurantData.Config.EnableAutoSwitch {
-		return
+// spinMode3 handles mode 3 spinning logic (rare progressive jackpot)
+func (h *APIHandler) spinMode3(config *models.GameConfig) (int, string) {
+	if rand.Float64() < config.Mode3JackpotRate/100.0 {
+		return models.Mode3JackpotIndex, config.Mode3JackpotText
 	}
 
-	// Wait for the spin animation to complete (about 8 seconds total)
-	// This allows users to see the result before switching
-	time.Sleep(8 * time.Second)
-
-	// Switch to advertisement page
-	config.CurrentPage = "advertisement"
-	
-	// Save the updated config
-	if err := h.storage.SaveConfig(config); err != nil {
-		return // Silently fail
-	}
-
-	// Broadcast the page switch
-	if h.wsHandler != nil {
-		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "page_switched",
-			Data: gin.H{
-				"page":   "advertisement",
-				"config": config,
-				"auto":   true, // Indicate this was an automatic switch
-			},
-		})
-	}
-
-	// Wait for the configured advertisement time, then switch back to the lottery page
-	autoSwitchTime := time.Duration(restaurantData.Config.AutoSwitchTime) * time.Second
-	if autoSwitchTime > 0 {
-		time.Sleep(autoSwitchTime)
-		
-		// Determine which lottery page to return to based on current mode
-		returnPage := "lottery1"
-		if config.Mode == 2 {
-			returnPage = "lottery2"
-		}
-		
-		config.CurrentPage = returnPage
-		
-		// Ensure mode is synchronized with page (defensive programming)
-		if returnPage == "lottery1" && config.Mode != 1 {
-			config.Mode = 1
-		} else if returnPage == "lottery2" && config.Mode != 2 {
-			config.Mode = 2
-		}
-		
-		// Save the updated config
-		if err := h.storage.SaveConfig(config); err != nil {
-			return // Silently fail
-		}
-
-		// Broadcast the return to lottery page
-		if h.wsHandler != nil {
-			h.wsHandler.Broadcast(models.WebSocketMessage{
-				Type: "page_switched",
-				Data: gin.H{
-					"page":   returnPage,
-					"config": config,
-					"auto":   true, // Indicate this was an automatic switch
-				},
-			})
-		}
-	}
+	// No jackpot - place at a random losing position (indices 0-10)
+	return rand.Intn(models.Mode3JackpotIndex), config.Mode3LoseText
 }
 
 // Restaurant and Page Management API Endpoints
 
 // SwitchPage switches the current display page
 func (h *APIHandler) SwitchPage(c *gin.Context) {
-	// Block page switching during active spins
-	if h.isSpinning {
-		c.JSON(http.StatusLocked, gin.H{
-			"error":     "Cannot switch pages while spin is in progress",
-			"spinning":  true,
-			"spin_time": time.Since(h.spinStarted).Seconds(),
-		})
-		return
-	}
-
 	var request models.PageSwitchRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
 
-	if err := request.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page: " + err.Error()})
+	// If-Match is optional here; with it the switch is refused if the game settings changed since that ETag
+	before, _, err := h.switchPage(auditActor(c), request, c.GetHeader("If-Match"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
+	h.audit(auditActor(c), models.AuditPageSwitch, "", pageOf(before), gin.H{"page": request.Page})
 
-	// Get current config
-	config, err := h.storage.GetConfig()
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"message": "Page switched successfully", "page": request.Page})
+}
+
+// switchPage validates and applies a global page switch, then broadcasts it, returning the config before and the state after.
+// A non-empty ifMatch refuses the switch if the game settings changed since the caller read that ETag.
+func (h *APIHandler) switchPage(actor models.AuditActor, request models.PageSwitchRequest, ifMatch string) (*models.GameConfig, gameState, error) {
+	if err := request.Validate(); err != nil {
+		return nil, gameState{}, newAPIError(http.StatusBadRequest, "Invalid page: "+err.Error())
+	}
+
+	before, after, err := h.state.CompareAndSwap(h.state.VersionOf(ifMatch), func(tx *gameTx) error {
+		// Block page switching during active spins
+		if tx.Spinning {
+			return spinLockedError("Cannot switch pages while spin is in progress", tx.Timeline)
+		}
+
+		showPage(&tx.Config, request.Page)
+		return nil
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get config: " + err.Error()})
-		return
+		return nil, gameState{}, err
 	}
 
-	// Update current page
-	config.CurrentPage = request.Page
+	// A manual switch wins over any scheduled one, and pauses the display schedule
+	h.supersedePageTransitions()
+	h.pauseDisplaySchedule(request.Page, actor)
+
+	h.broadcastPageSwitched(after, models.TransitionReasonManual, false)
+
+	return before.ConfigCopy(), after, nil
+}
+
+// showPage sets the global page, keeping the game mode in step with the lottery pages
+func showPage(config *models.GameConfig, page string) {
+	config.CurrentPage = page
 
 	// Synchronize mode with page for consistency
-	if request.Page == "lottery1" && config.Mode != 1 {
+	if page == "lottery1" && config.Mode != 1 {
 		config.Mode = 1
-	} else if request.Page == "lottery2" && config.Mode != 2 {
+	} else if page == "lottery2" && config.Mode != 2 {
 		config.Mode = 2
+	} else if page == "lottery3" && config.Mode != 3 {
+		config.Mode = 3
 	}
+}
 
-	// Save updated config
-	if err := h.storage.SaveConfig(config); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save config: " + err.Error()})
-		return
-	}
-
-	// Broadcast page change
+// broadcastPageSwitched sends the new page to every screen that isn't pinned to its own page
+func (h *APIHandler) broadcastPageSwitched(state gameState, reason string, auto bool) {
 	if h.wsHandler != nil {
-		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "page_switched",
-			Data: gin.H{
-				"page":   request.Page,
-				"config": config,
+		h.wsHandler.BroadcastExcludingDevices(models.WebSocketMessage{
+			Type: models.EventPageSwitched,
+			Data: models.PageSwitchedEvent{
+				Page:   state.Config.CurrentPage,
+				Config: state.ConfigCopy(),
+				Auto:   auto,
+				Reason: reason,
 			},
-		})
+		}, h.pinnedDevices())
 	}
-
-	c.Header("Content-Type", "application/json; charset=utf-8")
-	c.JSON(http.StatusOK, gin.H{"message": "Page switched successfully", "page": request.Page})
 }
 
-// GetRestaurantData returns restaurant configuration and data
+// GetRestaurantData returns restaurant configuration and data, with the ETag of the configuration
 func (h *APIHandler) GetRestaurantData(c *gin.Context) {
 	data, err := h.storage.GetRestaurantData()
 	if err != nil {
@@ -467,11 +586,13 @@ func (h *APIHandler) GetRestaurantData(c *gin.Context) {
 		return
 	}
 
+	// Only the configuration is edited through If-Match; ads and menu edits don't conflict with it
+	c.Header("ETag", entityTag(data.Config))
 	c.Header("Content-Type", "application/json; charset=utf-8")
 	c.JSON(http.StatusOK, data)
 }
 
-// UpdateRestaurantConfig updates restaurant configuration
+// UpdateRestaurantConfig updates restaurant configuration if it still matches the If-Match ETag
 func (h *APIHandler) UpdateRestaurantConfig(c *gin.Context) {
 	var config models.RestaurantConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
@@ -479,39 +600,53 @@ func (h *APIHandler) UpdateRestaurantConfig(c *gin.Context) {
 		return
 	}
 
-	// Get current restaurant data
-	data, err := h.storage.GetRestaurantData()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get restaurant data: " + err.Error()})
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	// Update config
-	data.Config = config
-
-	// Save updated data
-	if err := h.storage.SaveRestaurantData(data); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save restaurant config: " + err.Error()})
+	// Update config, refusing to overwrite changes the caller hasn't seen
+	before, data, err := h.storage.UpdateRestaurantConfig(config, func(current *models.RestaurantData) error {
+		return checkIfMatch(ifMatch, current.Config)
+	})
+	if err != nil {
+		if _, ok := err.(*apiError); !ok {
+			err = newAPIError(http.StatusInternalServerError, "Failed to save restaurant config: "+err.Error())
+		}
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
+	h.audit(auditActor(c), models.AuditRestaurantUpdate, "", before, config)
 
 	// Broadcast restaurant config update
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "restaurant_config_updated",
+			Type: models.EventRestaurantConfigUpdated,
 			Data: config,
 		})
 	}
 
+	c.Header("ETag", entityTag(data.Config))
 	c.Header("Content-Type", "application/json; charset=utf-8")
 	c.JSON(http.StatusOK, config)
 }
 
+// maxAdvertisementSize limits the size of an uploaded advertisement image request
+const maxAdvertisementSize = 10 << 20
+
 // UploadAdvertisement handles advertisement image upload
 func (h *APIHandler) UploadAdvertisement(c *gin.Context) {
+	// Reject oversized uploads before they fill the disk
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAdvertisementSize)
+
 	// Get file from form
 	file, header, err := c.Request.FormFile("image")
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Image too large, maximum is %d MB", maxAdvertisementSize>>20)})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to get image file: " + err.Error()})
 		return
 	}
@@ -565,11 +700,12 @@ func (h *APIHandler) UploadAdvertisement(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save advertisement: " + err.Error()})
 		return
 	}
+	h.audit(auditActor(c), models.AuditAdUpload, ad.ID, nil, ad)
 
 	// Broadcast advertisement update
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "advertisement_added",
+			Type: models.EventAdvertisementAdded,
 			Data: ad,
 		})
 	}
@@ -586,16 +722,18 @@ func (h *APIHandler) DeleteAdvertisement(c *gin.Context) {
 		return
 	}
 
+	before := h.findRestaurantItem(adID)
 	if err := h.storage.DeleteAdvertisement(adID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete advertisement: " + err.Error()})
 		return
 	}
+	h.audit(auditActor(c), models.AuditAdDelete, adID, before, nil)
 
 	// Broadcast advertisement deletion
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "advertisement_deleted",
-			Data: gin.H{"id": adID},
+			Type: models.EventAdvertisementDeleted,
+			Data: models.DeletedEvent{ID: adID},
 		})
 	}
 
@@ -620,15 +758,17 @@ func (h *APIHandler) UpdateMenuItem(c *gin.Context) {
 	// Ensure ID matches
 	item.ID = itemID
 
+	before := h.findRestaurantItem(itemID)
 	if err := h.storage.UpdateMenuItem(itemID, item); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update menu item: " + err.Error()})
 		return
 	}
+	h.audit(auditActor(c), models.AuditMenuUpdate, itemID, before, item)
 
 	// Broadcast menu item update
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "menu_item_updated",
+			Type: models.EventMenuItemUpdated,
 			Data: item,
 		})
 	}
@@ -653,11 +793,12 @@ func (h *APIHandler) AddRecommendation(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add recommendation: " + err.Error()})
 		return
 	}
+	h.audit(auditActor(c), models.AuditRecommendationAdd, rec.ID, nil, rec)
 
 	// Broadcast recommendation addition
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "recommendation_added",
+			Type: models.EventRecommendationAdded,
 			Data: rec,
 		})
 	}
@@ -683,15 +824,17 @@ func (h *APIHandler) UpdateRecommendation(c *gin.Context) {
 	// Ensure ID matches
 	rec.ID = recID
 
+	before := h.findRestaurantItem(recID)
 	if err := h.storage.UpdateRecommendation(recID, rec); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recommendation: " + err.Error()})
 		return
 	}
+	h.audit(auditActor(c), models.AuditRecommendationUpdate, recID, before, rec)
 
 	// Broadcast recommendation update
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "recommendation_updated",
+			Type: models.EventRecommendationUpdated,
 			Data: rec,
 		})
 	}
@@ -708,16 +851,18 @@ func (h *APIHandler) DeleteRecommendation(c *gin.Context) {
 		return
 	}
 
+	before := h.findRestaurantItem(recID)
 	if err := h.storage.DeleteRecommendation(recID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recommendation: " + err.Error()})
 		return
 	}
+	h.audit(auditActor(c), models.AuditRecommendationDelete, recID, before, nil)
 
 	// Broadcast recommendation deletion
 	if h.wsHandler != nil {
 		h.wsHandler.Broadcast(models.WebSocketMessage{
-			Type: "recommendation_deleted",
-			Data: gin.H{"id": recID},
+			Type: models.EventRecommendationDeleted,
+			Data: models.DeletedEvent{ID: recID},
 		})
 	}
 
@@ -768,30 +913,6 @@ func generateID() string {
 	return strconv.FormatInt(time.Now().UnixNano(), 36)
 }
 
-// CheckAndRecoverSpinLock checks for stale spin locks and recovers them
-func (h *APIHandler) CheckAndRecoverSpinLock() {
-	h.spinMutex.Lock()
-	defer h.spinMutex.Unlock()
-	
-	// If spinning for more than 12 seconds, assume something went wrong (8s animation + 4s buffer)
-	if h.isSpinning && time.Since(h.spinStarted) > 12*time.Second {
-		fmt.Printf("Recovering stale spin lock (duration: %v)\n", time.Since(h.spinStarted))
-		h.isSpinning = false
-		h.spinStarted = time.Time{}
-		
-		// Broadcast unlock to all clients
-		if h.wsHandler != nil {
-			h.wsHandler.Broadcast(models.WebSocketMessage{
-				Type: "spin_lock_recovered",
-				Data: gin.H{
-					"is_spinning": false,
-					"recovered":   true,
-				},
-			})
-		}
-	}
-}
-
 // init initializes random seed
 func init() {
 	rand.Seed(time.Now().UnixNano())
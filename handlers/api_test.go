@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+func TestPerformSpinAllowance(t *testing.T) {
+	tests := []struct {
+		name         string
+		venueSpins   int    // Venue-wide counter before the spin
+		selected     string // Config's current player ID
+		playerID     string // Player passed to performSpin
+		wantStatus   int    // 0 when the spin goes ahead
+		wantVenue    int    // Venue-wide counter afterwards
+		wantUsedBy   string // Player charged for the spin, "" for the venue counter
+		wantPlayerID string // Player recorded on the result
+	}{
+		{name: "venue counter", venueSpins: 1, wantVenue: 0},
+		{name: "venue counter used up", venueSpins: 0, wantStatus: http.StatusBadRequest},
+		{name: "selected player", venueSpins: 3, selected: "p1", wantVenue: 3, wantUsedBy: "p1", wantPlayerID: "p1"},
+		{name: "selected player used up ignores the venue counter", venueSpins: 3, selected: "used-up", wantStatus: http.StatusBadRequest, wantVenue: 3},
+		{name: "explicit player over the selection", venueSpins: 3, selected: "p1", playerID: "p2", wantVenue: 3, wantUsedBy: "p2", wantPlayerID: "p2"},
+		{name: "explicit player used up", venueSpins: 3, playerID: "used-up", wantStatus: http.StatusBadRequest, wantVenue: 3},
+		{name: "unknown player", venueSpins: 3, playerID: "missing", wantStatus: http.StatusBadRequest, wantVenue: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			now := time.Now()
+			for _, player := range []models.Player{
+				{ID: "p1", Table: "A1", SpinsGranted: 2, Created: now, Updated: now},
+				{ID: "p2", Table: "A2", SpinsGranted: 1, Created: now, Updated: now},
+				{ID: "used-up", Table: "A3", SpinsGranted: 1, SpinsUsed: 1, Created: now, Updated: now},
+			} {
+				if err := h.storage.AddPlayer(player); err != nil {
+					t.Fatalf("AddPlayer: %v", err)
+				}
+			}
+			if _, _, err := h.state.Update(func(tx *gameTx) error {
+				tx.Config.RemainingSpins = tt.venueSpins
+				tx.Config.CurrentPlayerID = tt.selected
+				return nil
+			}); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			result, _, err := h.performSpin(models.AuditActor{Username: "admin"}, tt.playerID)
+			if tt.wantStatus != 0 {
+				apiErr, ok := err.(*apiError)
+				if !ok || apiErr.Status != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+				if h.state.Snapshot().Spinning {
+					t.Error("refused spin holds the spin lock")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("performSpin: %v", err)
+				}
+				if result.PlayerID != tt.wantPlayerID {
+					t.Errorf("result player = %q, want %q", result.PlayerID, tt.wantPlayerID)
+				}
+			}
+
+			if venue := h.state.Snapshot().Config.RemainingSpins; venue != tt.wantVenue {
+				t.Errorf("venue spins = %d, want %d", venue, tt.wantVenue)
+			}
+			for _, id := range []string{"p1", "p2"} {
+				player, err := h.storage.GetPlayer(id)
+				if err != nil {
+					t.Fatalf("GetPlayer: %v", err)
+				}
+				wantUsed := 0
+				if id == tt.wantUsedBy {
+					wantUsed = 1
+				}
+				if player.SpinsUsed != wantUsed {
+					t.Errorf("%s SpinsUsed = %d, want %d", id, player.SpinsUsed, wantUsed)
+				}
+			}
+		})
+	}
+}
+
+func TestPerformSpinRefusesWhileSpinning(t *testing.T) {
+	h := newTestAPIHandler(t)
+	if _, _, err := h.state.Update(func(tx *gameTx) error { tx.Config.RemainingSpins = 2; return nil }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if _, _, err := h.performSpin(models.AuditActor{Username: "admin"}, ""); err != nil {
+		t.Fatalf("first spin: %v", err)
+	}
+	// The first spin holds the lock until its timeline ends
+	if _, _, err := h.performSpin(models.AuditActor{Username: "admin"}, ""); err == nil {
+		t.Fatal("second spin went ahead while the wheel was turning")
+	}
+	if venue := h.state.Snapshot().Config.RemainingSpins; venue != 1 {
+		t.Errorf("venue spins = %d, want 1", venue)
+	}
+
+	history, err := h.storage.GetHistory()
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(history.Results) != 1 {
+		t.Errorf("history has %d results, want 1", len(history.Results))
+	}
+}
+
+func TestPerformSpinLocksForItsTimeline(t *testing.T) {
+	h := newTestAPIHandler(t)
+	if _, _, err := h.state.Update(func(tx *gameTx) error {
+		tx.Config.RemainingSpins = 1
+		tx.Config.SpinDurationMs = models.MinSpinDurationMs
+		tx.Config.RevealDelayMs = 200
+		tx.Config.CooldownMs = 100
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	result, config, err := h.performSpin(models.AuditActor{Username: "admin"}, "")
+	if err != nil {
+		t.Fatalf("performSpin: %v", err)
+	}
+	want := config.SpinTimeline(result.Timestamp)
+	if state := h.state.Snapshot(); !state.Spinning || state.Timeline != want {
+		t.Fatalf("lock = %v %+v, want held for %+v", state.Spinning, state.Timeline, want)
+	}
+	if want.Unlock.Sub(want.Start) != 1300*time.Millisecond {
+		t.Errorf("lock lasts %v, want 1.3s", want.Unlock.Sub(want.Start))
+	}
+
+	// The lock is released once the timeline unlocks, not before
+	time.Sleep(time.Until(want.Unlock) - 200*time.Millisecond)
+	if !h.state.Snapshot().Spinning {
+		t.Fatal("lock released before the timeline unlocked")
+	}
+	deadline := want.Unlock.Add(time.Second)
+	for h.state.Snapshot().Spinning {
+		if time.Now().After(deadline) {
+			t.Fatal("lock still held a second after the timeline unlocked")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Audit Log
+
+// recordAudit appends an administrative action to the audit log.
+// Failures are logged rather than failing the request that already succeeded.
+func recordAudit(store *storage.Storage, actor models.AuditActor, action string, target string, before interface{}, after interface{}) {
+	_, err := store.AppendAudit(models.AuditEntry{
+		Time:   time.Now(),
+		Actor:  actor,
+		Action: action,
+		Target: target,
+		Before: models.NewAuditValue(before),
+		After:  models.NewAuditValue(after),
+	})
+	if err != nil {
+		log.Printf("Failed to record audit entry %s: %v", action, err)
+	}
+}
+
+// auditActor returns the logged-in admin behind a request
+func auditActor(c *gin.Context) models.AuditActor {
+	return models.AuditActor{
+		Username: c.GetString(contextUsername),
+		Role:     c.GetString(contextRole),
+		TokenID:  c.GetString(contextTokenID),
+		IP:       c.ClientIP(),
+	}
+}
+
+// requestIP returns the remote address of a request outside Gin (e.g. a WebSocket upgrade)
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// audit records an action performed through the API handler
+func (h *APIHandler) audit(actor models.AuditActor, action string, target string, before interface{}, after interface{}) {
+	recordAudit(h.storage, actor, action, target, before, after)
+}
+
+// GetAuditLog returns audit entries, newest first.
+// Filters: ?action=config.update (or a prefix like auth.), ?actor=, ?before=<seq>, ?limit=
+func (h *APIHandler) GetAuditLog(c *gin.Context) {
+	var query models.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+
+	page, err := h.storage.QueryAudit(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read audit log: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, page)
+}
+
+// VerifyAuditLog recomputes the hash chain and reports the first altered entry, if any
+func (h *APIHandler) VerifyAuditLog(c *gin.Context) {
+	result, err := h.storage.VerifyAudit()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify audit log: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, result)
+}
+
+// pageOf returns the audit value for a config's current page
+func pageOf(config *models.GameConfig) interface{} {
+	if config == nil {
+		return nil
+	}
+	return gin.H{"page": config.CurrentPage}
+}
+
+// findRestaurantItem returns the advertisement, menu item or recommendation with an ID, for audit "before" values
+func (h *APIHandler) findRestaurantItem(id string) interface{} {
+	data, err := h.storage.GetRestaurantData()
+	if err != nil {
+		return nil
+	}
+	for _, ad := range data.Advertisements {
+		if ad.ID == id {
+			return ad
+		}
+	}
+	for _, item := range data.MenuItems {
+		if item.ID == id {
+			return item
+		}
+	}
+	for _, rec := range data.Recommendations {
+		if rec.ID == id {
+			return rec
+		}
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// SessionCookieName is the cookie carrying the admin session token
+	SessionCookieName = "spinner_session"
+
+	// Gin context keys set by the auth middleware
+	contextUsername    = "auth_username"
+	contextTokenHash   = "auth_token_hash"
+	contextRole        = "auth_role"
+	contextPermissions = "auth_permissions"
+	contextTokenID     = "auth_token_id" // Set when an API token authenticated the request
+)
+
+// AuthHandler handles admin login, sessions and route protection
+type AuthHandler struct {
+	storage      *storage.Storage
+	sessionTTL   time.Duration
+	tokenLimiter *rateLimiter  // Per-API-token request budgets
+	loginLimit   *FailureLimit // Failed logins per IP and username; nil for no limit
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(store *storage.Storage, sessionTTL time.Duration) *AuthHandler {
+	return &AuthHandler{
+		storage:      store,
+		sessionTTL:   sessionTTL,
+		tokenLimiter: newRateLimiter(),
+	}
+}
+
+// SetLoginLimit limits failed logins per client IP and per username
+func (a *AuthHandler) SetLoginLimit(limit *FailureLimit) {
+	a.loginLimit = limit
+}
+
+// GetStatus reports whether setup is required and whether the caller is logged in
+func (a *AuthHandler) GetStatus(c *gin.Context) {
+	hasUsers, err := a.storage.HasAdminUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read auth data: " + err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"setup_required": !hasUsers,
+		"authenticated":  false,
+	}
+	if session, ok := a.AuthenticateRequest(c.Request); ok {
+		response["authenticated"] = true
+		response["username"] = session.Username
+		response["expires"] = session.Expires
+		if user, err := a.storage.GetAdminUser(session.Username); err == nil {
+			response["role"] = user.Role
+			response["permissions"] = models.PermissionsForRole(user.Role)
+		}
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, response)
+}
+
+// Setup creates the initial admin password on first run and logs the caller in
+func (a *AuthHandler) Setup(c *gin.Context) {
+	var request models.LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if request.Username == "" {
+		request.Username = models.DefaultAdminUsername
+	}
+	if err := models.ValidateUsername(request.Username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username: " + err.Error()})
+		return
+	}
+
+	user := models.AdminUser{
+		Username: request.Username,
+		Role:     models.RoleOwner,
+		Created:  time.Now(),
+	}
+	if err := user.SetPassword(request.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password: " + err.Error()})
+		return
+	}
+
+	if err := a.storage.CreateInitialUser(user); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to complete setup: " + err.Error()})
+		return
+	}
+	a.audit(models.AuditActor{Username: user.Username, Role: user.Role, IP: c.ClientIP()}, models.AuditAuthSetup, user.Username, nil, user.Info())
+
+	a.startSession(c, user.Username)
+}
+
+// Login verifies credentials and starts a session
+func (a *AuthHandler) Login(c *gin.Context) {
+	var request models.LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if request.Username == "" {
+		request.Username = models.DefaultAdminUsername
+	}
+
+	// Refuse before checking the password so a locked-out guesser learns nothing
+	if blocked, wait := a.loginLimit.Blocked(c.ClientIP(), request.Username, time.Now()); blocked {
+		rateLimited(c, wait)
+		return
+	}
+
+	user, err := a.storage.GetAdminUser(request.Username)
+	if err != nil || !user.CheckPassword(request.Password) {
+		a.loginLimit.Fail(c.ClientIP(), request.Username, time.Now())
+		a.audit(models.AuditActor{Username: request.Username, IP: c.ClientIP()}, models.AuditAuthLoginFailed, request.Username, nil, nil)
+		// Same response for unknown users and wrong passwords
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		return
+	}
+	a.audit(models.AuditActor{Username: user.Username, Role: user.Role, IP: c.ClientIP()}, models.AuditAuthLogin, user.Username, nil, nil)
+
+	a.startSession(c, user.Username)
+}
+
+// Logout ends the caller's session
+func (a *AuthHandler) Logout(c *gin.Context) {
+	if tokenHash := c.GetString(contextTokenHash); tokenHash != "" {
+		if err := a.storage.DeleteSession(tokenHash); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end session: " + err.Error()})
+			return
+		}
+	}
+
+	a.clearSessionCookie(c)
+	a.audit(auditActor(c), models.AuditAuthLogout, c.GetString(contextUsername), nil, nil)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// ChangePassword changes the caller's password and ends their other sessions
+func (a *AuthHandler) ChangePassword(c *gin.Context) {
+	var request models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	username := c.GetString(contextUsername)
+	user, err := a.storage.GetAdminUser(username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user: " + err.Error()})
+		return
+	}
+
+	if !user.CheckPassword(request.CurrentPassword) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if err := user.SetPassword(request.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password: " + err.Error()})
+		return
+	}
+
+	if err := a.storage.UpdateAdminUser(username, *user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save password: " + err.Error()})
+		return
+	}
+
+	if err := a.storage.DeleteUserSessions(username, c.GetString(contextTokenHash)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end other sessions: " + err.Error()})
+		return
+	}
+	a.audit(auditActor(c), models.AuditPasswordChange, username, nil, nil)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// RequireAdmin is middleware rejecting API requests without a valid admin session or API token
+func (a *AuthHandler) RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Already authenticated by AuthenticateAPIToken
+		if c.GetString(contextTokenID) != "" {
+			c.Next()
+			return
+		}
+
+		session, ok := a.AuthenticateRequest(c.Request)
+		if !ok {
+			hasUsers, _ := a.storage.HasAdminUsers()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":          "Authentication required",
+				"auth_required":  true,
+				"setup_required": !hasUsers,
+			})
+			return
+		}
+
+		// Look the role up on every request so role changes apply immediately
+		user, err := a.storage.GetAdminUser(session.Username)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":         "Account no longer exists",
+				"auth_required": true,
+			})
+			return
+		}
+
+		c.Set(contextUsername, session.Username)
+		c.Set(contextTokenHash, session.TokenHash)
+		c.Set(contextRole, user.Role)
+		c.Set(contextPermissions, models.PermissionsForRole(user.Role))
+		c.Next()
+	}
+}
+
+// RequireAdminPage is middleware redirecting unauthenticated browsers to the login page
+func (a *AuthHandler) RequireAdminPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := a.AuthenticateRequest(c.Request); !ok {
+			c.Redirect(http.StatusFound, "/login?next="+url.QueryEscape(c.Request.URL.Path))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuthenticateRequest returns the session for a request's bearer token or session cookie
+func (a *AuthHandler) AuthenticateRequest(r *http.Request) (*models.Session, bool) {
+	token := bearerToken(r)
+	if token == "" {
+		if cookie, err := r.Cookie(SessionCookieName); err == nil {
+			token = cookie.Value
+		}
+	}
+	if token == "" {
+		return nil, false
+	}
+
+	session, err := a.storage.GetSession(models.HashToken(token))
+	if err != nil {
+		return nil, false
+	}
+	return session, true
+}
+
+// CommandActor authorizes WebSocket commands for logged-in admins, attributing them to the account
+func (a *AuthHandler) CommandActor(r *http.Request) (models.AuditActor, bool) {
+	session, ok := a.AuthenticateRequest(r)
+	if !ok {
+		return models.AuditActor{}, false
+	}
+	user, err := a.storage.GetAdminUser(session.Username)
+	if err != nil {
+		return models.AuditActor{}, false
+	}
+	return models.AuditActor{Username: user.Username, Role: user.Role, IP: requestIP(r)}, true
+}
+
+// audit records an authentication or account action
+func (a *AuthHandler) audit(actor models.AuditActor, action string, target string, before interface{}, after interface{}) {
+	recordAudit(a.storage, actor, action, target, before, after)
+}
+
+// LoginPage serves the built-in login and first-run setup page
+func (a *AuthHandler) LoginPage(c *gin.Context) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.String(http.StatusOK, loginPageHTML)
+}
+
+// startSession creates a session for the user, sets the cookie and returns the bearer token
+func (a *AuthHandler) startSession(c *gin.Context, username string) {
+	token, tokenHash, err := models.NewSessionToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	session := models.Session{
+		TokenHash: tokenHash,
+		Username:  username,
+		Created:   now,
+		Expires:   now.Add(a.sessionTTL),
+		LastUsed:  now,
+		IP:        c.ClientIP(),
+	}
+	if err := a.storage.CreateSession(session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session: " + err.Error()})
+		return
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.Expires,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{
+		"token":    token,
+		"username": username,
+		"expires":  session.Expires,
+	})
+}
+
+// clearSessionCookie removes the session cookie from the browser
+func (a *AuthHandler) clearSessionCookie(c *gin.Context) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
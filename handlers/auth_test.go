@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoginFailureLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	owner := models.AdminUser{Username: "admin", Role: models.RoleOwner, Created: time.Now()}
+	if err := owner.SetPassword("secret-pin"); err != nil {
+		t.Fatalf("SetPassword: %v", err)
+	}
+	if err := store.CreateInitialUser(owner); err != nil {
+		t.Fatalf("CreateInitialUser: %v", err)
+	}
+
+	limits, err := NewRateLimits([]string{"10.0.0.9"})
+	if err != nil {
+		t.Fatalf("NewRateLimits: %v", err)
+	}
+	a := NewAuthHandler(store, time.Hour)
+	a.SetLoginLimit(limits.Failures("login", RateBudget{Requests: 3, Window: time.Hour}))
+	r := gin.New()
+	r.POST("/api/auth/login", a.Login)
+
+	// Steps run in order against the same limits, three failures allowed per IP and per username
+	steps := []struct {
+		name       string
+		ip         string
+		username   string
+		password   string
+		wantStatus int
+	}{
+		{name: "correct password", ip: "10.0.0.1", username: "admin", password: "secret-pin", wantStatus: http.StatusOK},
+		{name: "successes cost nothing", ip: "10.0.0.1", username: "admin", password: "secret-pin", wantStatus: http.StatusOK},
+
+		{name: "guess 1 from one IP", ip: "10.0.0.2", username: "guess1", password: "0000", wantStatus: http.StatusUnauthorized},
+		{name: "guess 2 from one IP", ip: "10.0.0.2", username: "guess2", password: "0000", wantStatus: http.StatusUnauthorized},
+		{name: "guess 3 from one IP", ip: "10.0.0.2", username: "guess3", password: "0000", wantStatus: http.StatusUnauthorized},
+		{name: "IP over budget is refused even with the right password", ip: "10.0.0.2", username: "admin", password: "secret-pin", wantStatus: http.StatusTooManyRequests},
+		{name: "other IP is unaffected", ip: "10.0.0.3", username: "admin", password: "secret-pin", wantStatus: http.StatusOK},
+
+		{name: "admin guess 1", ip: "10.0.0.4", username: "admin", password: "1111", wantStatus: http.StatusUnauthorized},
+		{name: "admin guess 2 from another IP", ip: "10.0.0.5", username: "Admin", password: "2222", wantStatus: http.StatusUnauthorized},
+		{name: "admin guess 3 from an allowlisted IP", ip: "10.0.0.9", username: "admin", password: "3333", wantStatus: http.StatusUnauthorized},
+		{name: "username over budget is refused from any IP", ip: "10.0.0.6", username: "admin", password: "secret-pin", wantStatus: http.StatusTooManyRequests},
+		{name: "allowlisted IP still counts against the username", ip: "10.0.0.9", username: "admin", password: "secret-pin", wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, step := range steps {
+		ok := t.Run(step.name, func(t *testing.T) {
+			body := `{"username":"` + step.username + `","password":"` + step.password + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.RemoteAddr = step.ip + ":50000"
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != step.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, step.wantStatus, w.Body.String())
+			}
+			if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+				t.Error("429 without Retry-After")
+			}
+		})
+		if !ok {
+			t.FailNow()
+		}
+	}
+
+	// Every wrong password is audited; refused attempts never reach the password check
+	page, err := store.QueryAudit(models.AuditQuery{Action: models.AuditAuthLoginFailed})
+	if err != nil {
+		t.Fatalf("QueryAudit: %v", err)
+	}
+	if len(page.Entries) != 6 {
+		t.Errorf("%d failed logins audited, want 6", len(page.Entries))
+	}
+}
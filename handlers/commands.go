@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebSocket Command Execution
+
+// ExecuteCommand runs a WebSocket command through the same code paths as the REST endpoints,
+// returning the REST body and, for commands that change the config, its new ETag
+func (h *APIHandler) ExecuteCommand(actor models.AuditActor, cmd models.CommandMessage) (interface{}, string, error) {
+	permission, ok := models.CommandPermissions[cmd.Command]
+	if !ok {
+		return nil, "", newAPIError(http.StatusBadRequest, "Unknown command: "+cmd.Command)
+	}
+	if !models.RoleHasPermission(actor.Role, permission) {
+		return nil, "", newAPIError(http.StatusForbidden, "Permission denied: requires "+permission)
+	}
+	// Setting the venue-wide spins hands out free spins, so it needs edit_config as on POST /api/config
+	if cmd.Command == models.CommandSetPlayer && cmd.RemainingSpins != nil && !models.RoleHasPermission(actor.Role, models.PermissionEditConfig) {
+		return nil, "", newAPIError(http.StatusForbidden, "Permission denied: requires "+models.PermissionEditConfig)
+	}
+
+	switch cmd.Command {
+	case models.CommandSpin:
+		result, config, err := h.performSpin(actor, "")
+		if err != nil {
+			return nil, "", err
+		}
+		return gin.H{"result": result, "config": config}, "", nil
+
+	case models.CommandSwitchPage:
+		before, after, err := h.switchPage(actor, models.PageSwitchRequest{Page: cmd.Page}, cmd.IfMatch)
+		if err != nil {
+			return nil, "", err
+		}
+		h.audit(actor, models.AuditPageSwitch, "", pageOf(before), gin.H{"page": cmd.Page})
+		return gin.H{"message": "Page switched successfully", "page": cmd.Page}, after.ETag(), nil
+
+	case models.CommandSetPlayer:
+		before, after, err := h.updateConfig(models.ConfigUpdateRequest{
+			CurrentPlayer:   cmd.Player,
+			CurrentPlayerID: cmd.PlayerID,
+			RemainingSpins:  cmd.RemainingSpins,
+		}, cmd.IfMatch)
+		if err != nil {
+			return nil, "", err
+		}
+		config := after.ConfigCopy()
+		h.audit(actor, models.AuditConfigUpdate, "", before, config)
+		return config, after.ETag(), nil
+	}
+
+	return nil, "", newAPIError(http.StatusBadRequest, "Unknown command: "+cmd.Command)
+}
+
+// CommandKeyAuthorizer allows WebSocket commands from clients presenting the shared key
+// as ?key=<key> or an X-Command-Key header, attributing their commands to "command-key"
+// with the staff role. An empty key disables key-based access.
+func CommandKeyAuthorizer(key string) func(r *http.Request) (models.AuditActor, bool) {
+	return func(r *http.Request) (models.AuditActor, bool) {
+		if key == "" {
+			return models.AuditActor{}, false
+		}
+		presented := r.URL.Query().Get("key")
+		if presented == "" {
+			presented = r.Header.Get("X-Command-Key")
+		}
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(key)) != 1 {
+			return models.AuditActor{}, false
+		}
+		return models.AuditActor{Username: "command-key", Role: models.RoleStaff, IP: requestIP(r)}, true
+	}
+}
@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Display Device Management API Endpoints
+
+// GetDevices returns all registered display devices with their live status
+func (h *APIHandler) GetDevices(c *gin.Context) {
+	devices, err := h.storage.GetDevices()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get devices: " + err.Error()})
+		return
+	}
+
+	for i := range devices {
+		h.presence.apply(&devices[i])
+		devices[i].Online = h.isDeviceOnline(devices[i].ID)
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"devices": devices})
+}
+
+// RegisterDevice registers a new display device
+func (h *APIHandler) RegisterDevice(c *gin.Context) {
+	var device models.Device
+	if err := c.ShouldBindJSON(&device); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	// Set required fields
+	if device.ID == "" {
+		device.ID = generateID()
+	}
+	if device.Kind == "" {
+		device.Kind = "other"
+	}
+	device.Created = time.Now()
+	device.LastSeen = time.Time{}
+
+	if err := h.storage.AddDevice(device); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to register device: " + err.Error()})
+		return
+	}
+
+	h.presence.register(device.ID)
+	h.audit(auditActor(c), models.AuditDeviceRegister, device.ID, nil, device)
+
+	device.Online = h.isDeviceOnline(device.ID)
+	h.broadcastDeviceUpdated(device)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, device)
+}
+
+// UpdateDevice updates a display device's name, kind, pinned page or rotation settings
+func (h *APIHandler) UpdateDevice(c *gin.Context) {
+	deviceID := c.Param("id")
+	if deviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Device ID is required"})
+		return
+	}
+
+	var updateReq models.DeviceUpdateRequest
+	if err := c.ShouldBindJSON(&updateReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	before, updated, err := h.storage.UpdateDevice(deviceID, func(device *models.Device) error {
+		updateReq.Apply(device)
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, deviceUpdateError(err))
+		return
+	}
+	device := &updated
+	h.audit(auditActor(c), models.AuditDeviceUpdate, deviceID, before, device)
+
+	// Tell the screen itself when its page assignment or ad rotation changed
+	if device.AssignedPage != before.AssignedPage || device.AdRotationTime != before.AdRotationTime {
+		h.sendDevicePage(device, "admin")
+	}
+
+	h.presence.apply(device)
+	device.Online = h.isDeviceOnline(device.ID)
+	h.broadcastDeviceUpdated(*device)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, device)
+}
+
+// DeleteDevice removes a display device from the registry
+func (h *APIHandler) DeleteDevice(c *gin.Context) {
+	deviceID := c.Param("id")
+	if deviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Device ID is required"})
+		return
+	}
+
+	before, _ := h.storage.GetDevice(deviceID)
+	if err := h.storage.DeleteDevice(deviceID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete device: " + err.Error()})
+		return
+	}
+	h.presence.forget(deviceID)
+	h.audit(auditActor(c), models.AuditDeviceDelete, deviceID, before, nil)
+
+	// Broadcast device deletion
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventDeviceDeleted,
+			Data: models.DeletedEvent{ID: deviceID},
+		})
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"message": "Device deleted successfully"})
+}
+
+// SendDeviceCommand sends a remote-control command to a single display device
+func (h *APIHandler) SendDeviceCommand(c *gin.Context) {
+	deviceID := c.Param("id")
+	if deviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Device ID is required"})
+		return
+	}
+
+	var request models.DeviceCommandRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid command: " + err.Error()})
+		return
+	}
+
+	device, err := h.storage.GetDevice(deviceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get device: " + err.Error()})
+		return
+	}
+
+	delivered := 0
+	switch request.Command {
+	case models.DeviceCommandSwitchPage, models.DeviceCommandReleasePage:
+		// Block page switching during active spins, same as the global switch
+		if state := h.state.Snapshot(); state.Spinning {
+			respondError(c, http.StatusLocked, spinLockedError("Cannot switch pages while spin is in progress", state.Timeline))
+			return
+		}
+
+		_, updated, err := h.storage.UpdateDevice(deviceID, func(device *models.Device) error {
+			device.AssignedPage = ""
+			if request.Command == models.DeviceCommandSwitchPage {
+				device.AssignedPage = request.Page
+			}
+			return nil
+		})
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, deviceUpdateError(err))
+			return
+		}
+		device = &updated
+		delivered = h.sendDevicePage(device, "admin")
+		h.presence.apply(device)
+		device.Online = h.isDeviceOnline(device.ID)
+		h.broadcastDeviceUpdated(*device)
+	default:
+		if h.wsHandler != nil {
+			delivered = h.wsHandler.SendToDevice(deviceID, models.WebSocketMessage{
+				Type: models.EventDeviceCommand,
+				Data: models.DeviceCommandEvent{
+					DeviceID: deviceID,
+					Command:  request.Command,
+				},
+			})
+		}
+	}
+
+	h.audit(auditActor(c), models.AuditDeviceCommand, deviceID, nil, request)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Command sent successfully",
+		"device":    device,
+		"delivered": delivered,
+	})
+}
+
+// MarkDeviceSeen records device presence reported by the WebSocket handler. Anyone can
+// connect with ?device=, so only devices an admin registered are tracked; registration
+// and last-seen are kept in memory, and last-seen is saved by StartDevicePresenceFlush.
+func (h *APIHandler) MarkDeviceSeen(deviceID string, presence string) {
+	if !h.presence.mark(deviceID, time.Now()) {
+		return
+	}
+
+	// Heartbeats only refresh last-seen; connect/disconnect are announced
+	if h.wsHandler == nil || presence == PresenceHeartbeat {
+		return
+	}
+
+	device, err := h.storage.GetDevice(deviceID)
+	if err != nil {
+		return
+	}
+	h.presence.apply(device)
+	device.Online = h.wsHandler.IsDeviceOnline(deviceID)
+	if presence == PresenceConnected {
+		// Tell the screen its page and ad rotation, which may differ from everyone else's
+		h.sendDevicePage(device, "reconnect")
+	}
+	h.wsHandler.Broadcast(models.WebSocketMessage{
+		Type: models.EventDevicePresence,
+		Data: models.DevicePresenceEvent{
+			DeviceID: device.ID,
+			Online:   device.Online,
+			LastSeen: device.LastSeen,
+		},
+	})
+}
+
+// sendDevicePage sends the device its effective page and returns how many connections received it
+func (h *APIHandler) sendDevicePage(device *models.Device, reason string) int {
+	if h.wsHandler == nil {
+		return 0
+	}
+
+	config := h.state.Snapshot().ConfigCopy()
+	return h.wsHandler.SendToDevice(device.ID, models.WebSocketMessage{
+		Type: models.EventPageSwitched,
+		Data: models.PageSwitchedEvent{
+			Page:           device.EffectivePage(config.CurrentPage),
+			Config:         config,
+			DeviceID:       device.ID,
+			Reason:         reason,
+			AdRotationTime: device.AdRotationTime,
+		},
+	})
+}
+
+// StartDevicePresenceFlush starts a supervised loop saving device last-seen times every interval
+func (h *APIHandler) StartDevicePresenceFlush(interval time.Duration) {
+	go supervise("device presence flush", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			h.flushDevicePresence()
+		}
+	})
+}
+
+// flushDevicePresence saves the last-seen times recorded since the previous flush
+func (h *APIHandler) flushDevicePresence() {
+	seen := h.presence.drain()
+	if len(seen) == 0 {
+		return
+	}
+	if err := h.storage.RecordDevicesSeen(seen); err != nil {
+		log.Printf("Failed to save device presence: %v", err)
+		// Keep them for the next flush
+		for deviceID, at := range seen {
+			h.presence.mark(deviceID, at)
+		}
+	}
+}
+
+// pinnedDevices returns the IDs of devices that ignore global page switches
+func (h *APIHandler) pinnedDevices() map[string]bool {
+	devices, err := h.storage.GetDevices()
+	if err != nil {
+		return nil
+	}
+
+	pinned := make(map[string]bool)
+	for _, device := range devices {
+		if device.AssignedPage != "" {
+			pinned[device.ID] = true
+		}
+	}
+	return pinned
+}
+
+// isDeviceOnline reports whether a device has an open WebSocket
+func (h *APIHandler) isDeviceOnline(deviceID string) bool {
+	return h.wsHandler != nil && h.wsHandler.IsDeviceOnline(deviceID)
+}
+
+// broadcastDeviceUpdated notifies clients that a device record changed
+func (h *APIHandler) broadcastDeviceUpdated(device models.Device) {
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventDeviceUpdated,
+			Data: device,
+		})
+	}
+}
+
+// devicePresence keeps the registered device IDs and their last-seen times in memory,
+// so heartbeats neither read nor rewrite devices.json
+type devicePresence struct {
+	mu         sync.Mutex
+	registered map[string]bool      // Devices an admin registered
+	seen       map[string]time.Time // Latest time each device was seen
+	pending    map[string]time.Time // Seen since the last flush
+}
+
+// newDevicePresence creates a presence tracker for the registered devices
+func newDevicePresence(devices []models.Device) *devicePresence {
+	p := &devicePresence{
+		registered: make(map[string]bool, len(devices)),
+		seen:       make(map[string]time.Time),
+		pending:    make(map[string]time.Time),
+	}
+	for _, device := range devices {
+		p.registered[device.ID] = true
+	}
+	return p
+}
+
+// register starts tracking a newly registered device
+func (p *devicePresence) register(deviceID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.registered[deviceID] = true
+}
+
+// mark records that a device was seen at a time, reporting false for unregistered devices
+func (p *devicePresence) mark(deviceID string, at time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.registered[deviceID] {
+		return false
+	}
+	if at.After(p.seen[deviceID]) {
+		p.seen[deviceID] = at
+	}
+	if at.After(p.pending[deviceID]) {
+		p.pending[deviceID] = at
+	}
+	return true
+}
+
+// apply fills in a device's last-seen time if it was seen after it was last saved
+func (p *devicePresence) apply(device *models.Device) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if at := p.seen[device.ID]; at.After(device.LastSeen) {
+		device.LastSeen = at
+	}
+}
+
+// forget drops a removed device
+func (p *devicePresence) forget(deviceID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.registered, deviceID)
+	delete(p.seen, deviceID)
+	delete(p.pending, deviceID)
+}
+
+// drain returns the times recorded since the last call and forgets them
+func (p *devicePresence) drain() map[string]time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pending := p.pending
+	p.pending = make(map[string]time.Time)
+	return pending
+}
+
+// deviceUpdateError turns an unknown device ID into a 404
+func deviceUpdateError(err error) error {
+	if errors.Is(err, storage.ErrDeviceNotFound) {
+		return newAPIError(http.StatusNotFound, "Device not found")
+	}
+	return err
+}
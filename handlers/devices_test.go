@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+func TestDevicePresence(t *testing.T) {
+	start := time.Date(2026, 1, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		events   func(p *devicePresence)
+		deviceID string
+		wantSeen time.Time // Zero when the device must not be tracked
+	}{
+		{
+			name:     "registered at startup",
+			events:   func(p *devicePresence) { p.mark("tv1", start) },
+			deviceID: "tv1",
+			wantSeen: start,
+		},
+		{
+			name: "registered later",
+			events: func(p *devicePresence) {
+				p.register("tv2")
+				p.mark("tv2", start)
+			},
+			deviceID: "tv2",
+			wantSeen: start,
+		},
+		{
+			name:     "unregistered device",
+			events:   func(p *devicePresence) { p.mark("rogue", start) },
+			deviceID: "rogue",
+		},
+		{
+			name: "deleted device",
+			events: func(p *devicePresence) {
+				p.mark("tv1", start)
+				p.forget("tv1")
+				p.mark("tv1", start.Add(time.Minute))
+			},
+			deviceID: "tv1",
+		},
+		{
+			name: "older heartbeat arriving late",
+			events: func(p *devicePresence) {
+				p.mark("tv1", start.Add(time.Minute))
+				p.mark("tv1", start)
+			},
+			deviceID: "tv1",
+			wantSeen: start.Add(time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newDevicePresence([]models.Device{{ID: "tv1"}})
+			tt.events(p)
+
+			device := models.Device{ID: tt.deviceID}
+			p.apply(&device)
+			if !device.LastSeen.Equal(tt.wantSeen) {
+				t.Errorf("LastSeen = %v, want %v", device.LastSeen, tt.wantSeen)
+			}
+
+			pending := p.drain()
+			if at, ok := pending[tt.deviceID]; ok != !tt.wantSeen.IsZero() || !at.Equal(tt.wantSeen) {
+				t.Errorf("pending = %v, want %v", pending, tt.wantSeen)
+			}
+			if again := p.drain(); len(again) != 0 {
+				t.Errorf("second drain = %v, want empty", again)
+			}
+		})
+	}
+}
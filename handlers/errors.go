@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// apiError is an error carrying the HTTP status and extra response fields for the client
+type apiError struct {
+	Status  int
+	Message string
+	Details gin.H             // Optional extra fields merged into the JSON response
+	Headers map[string]string // Optional response headers
+}
+
+// Error implements the error interface
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+// newAPIError creates an apiError with the given status and message
+func newAPIError(status int, message string) *apiError {
+	return &apiError{Status: status, Message: message}
+}
+
+// respondError writes an apiError (or any other error as 500) in the standard {"error": ...} shape
+func respondError(c *gin.Context, status int, err error) {
+	body := gin.H{"error": err.Error()}
+	if apiErr, ok := err.(*apiError); ok {
+		status = apiErr.Status
+		for key, value := range apiErr.Details {
+			body[key] = value
+		}
+		for key, value := range apiErr.Headers {
+			c.Header(key, value)
+		}
+	}
+	c.JSON(status, body)
+}
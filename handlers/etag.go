@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Optimistic Concurrency
+//
+// GET responses for editable resources carry an ETag computed from the fields an
+// update can change, so unrelated activity (spins, ad uploads) doesn't invalidate
+// it; the game config's tag is the game state's settings version instead. Updates
+// must send it back in If-Match; if the resource changed in the meantime the update
+// is refused with 412 and the current state, so the admin UI can merge or warn
+// instead of silently overwriting someone else's edit. "If-Match: *" explicitly
+// opts out of the check, for scripts; the admin UI never sends it.
+
+// entityTag returns the strong ETag of a resource's JSON representation
+func entityTag(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-Match header value matches the current ETag
+func etagMatches(header string, current string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// Weak tags never match under the strong comparison If-Match requires
+		if tag == "*" || (tag == current && current != "") {
+			return true
+		}
+	}
+	return false
+}
+
+// requireIfMatch returns the request's If-Match header, responding 428 when it is missing
+func requireIfMatch(c *gin.Context) (string, bool) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"error": "If-Match header is required; send the ETag from the last GET",
+		})
+		return "", false
+	}
+	return ifMatch, true
+}
+
+// checkIfMatch compares an If-Match header with a resource's current state and
+// returns a 412 error carrying that state when they differ. An empty header skips the check.
+func checkIfMatch(ifMatch string, current interface{}) error {
+	if ifMatch == "" {
+		return nil
+	}
+
+	etag := entityTag(current)
+	if etagMatches(ifMatch, etag) {
+		return nil
+	}
+	return &apiError{
+		Status:  http.StatusPreconditionFailed,
+		Message: "Resource was modified by someone else; reload and try again",
+		Details: gin.H{"etag": etag, "current": current},
+		Headers: map[string]string{"ETag": etag},
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// anyVersion makes a state transaction skip the version check
+const anyVersion uint64 = 0
+
+// staleVersion is what an If-Match tag from before a restart, or not ours at all, resolves to; it never matches
+const staleVersion uint64 = math.MaxUint64
+
+// gameState is the game configuration together with the in-memory spin lock
+type gameState struct {
+	Config   models.GameConfig
+	Version  uint64 // Revision of the game settings; spins, player changes and page switches leave it alone
+	epoch    string // Identifies this run of the server, so versions from before a restart never match
+	Spinning bool
+	Timeline models.SpinTimeline // Schedule of the spin holding the lock
+	Holder   *models.AuditActor  // Who started the spin holding the lock
+}
+
+// copy returns a deep copy that shares nothing with the receiver
+func (s gameState) copy() gameState {
+	if s.Config.Mode1Options != nil {
+		s.Config.Mode1Options = append(make([]models.PrizeOption, 0, len(s.Config.Mode1Options)), s.Config.Mode1Options...)
+	}
+	if s.Holder != nil {
+		holder := *s.Holder
+		s.Holder = &holder
+	}
+	return s
+}
+
+// SpinLock returns the spin lock part of the state
+func (s gameState) SpinLock() models.SpinLock {
+	if !s.Spinning {
+		return models.SpinLock{}
+	}
+	timeline := s.Timeline
+	return models.SpinLock{Locked: true, Holder: s.copy().Holder, Timeline: &timeline}
+}
+
+// ETag returns the config's entity tag, which changes only when the game settings do
+func (s gameState) ETag() string {
+	return `"` + s.epoch + "-" + strconv.FormatUint(s.Version, 10) + `"`
+}
+
+// ConfigCopy returns a pointer to a private copy of the config, safe to broadcast
+func (s gameState) ConfigCopy() *models.GameConfig {
+	config := s.copy().Config
+	return &config
+}
+
+// gameTx is the mutable state handed to a transaction
+type gameTx struct {
+	gameState
+	persist func(config *models.GameConfig) error // Replaces storage.SaveConfig and always runs when set
+}
+
+// gameStateManager owns the game state and serialises every change to it.
+// Transactions run against a copy under one lock; the copy only replaces the
+// current state once storage has accepted it, so readers never observe
+// unpersisted changes and concurrent writers cannot overwrite each other.
+type gameStateManager struct {
+	mutex   sync.Mutex
+	storage *storage.Storage
+	state   gameState
+}
+
+// newGameStateManager loads the persisted config and spin lock into a new manager
+func newGameStateManager(store *storage.Storage) (*gameStateManager, error) {
+	config, err := store.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	lock, err := store.GetSpinLock()
+	if err != nil {
+		return nil, err
+	}
+
+	state := gameState{Config: *config, Version: 1, epoch: strconv.FormatInt(time.Now().UnixNano(), 36)}
+	if lock.Locked && lock.Timeline != nil {
+		state.Spinning = true
+		state.Timeline = *lock.Timeline
+		state.Holder = lock.Holder
+	}
+
+	return &gameStateManager{
+		storage: store,
+		state:   state,
+	}, nil
+}
+
+// Snapshot returns a copy of the current state
+func (m *gameStateManager) Snapshot() gameState {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.state.copy()
+}
+
+// VersionOf resolves an If-Match header to the settings version it was read at.
+// An empty header or "*" skips the check.
+func (m *gameStateManager) VersionOf(ifMatch string) uint64 {
+	if ifMatch == "" {
+		return anyVersion
+	}
+
+	m.mutex.Lock()
+	epoch := m.state.epoch
+	m.mutex.Unlock()
+
+	version := staleVersion
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return anyVersion
+		}
+		tagEpoch, number, ok := strings.Cut(strings.Trim(tag, `"`), "-")
+		if !ok || tagEpoch != epoch {
+			continue
+		}
+		if v, err := strconv.ParseUint(number, 10, 64); err == nil && v != anyVersion {
+			version = v
+		}
+	}
+	return version
+}
+
+// Update runs fn against the latest state and commits the result
+func (m *gameStateManager) Update(fn func(tx *gameTx) error) (before, after gameState, err error) {
+	return m.CompareAndSwap(anyVersion, fn)
+}
+
+// CompareAndSwap runs fn and commits the result only if the game settings are still at
+// the expected version, refusing with 412 and the current config otherwise. A config
+// change is persisted before it becomes visible; if fn or the save fails, the state is left untouched.
+func (m *gameStateManager) CompareAndSwap(version uint64, fn func(tx *gameTx) error) (before, after gameState, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	before = m.state.copy()
+	if version != anyVersion && version != m.state.Version {
+		return before, before, staleStateError(before)
+	}
+
+	tx := &gameTx{gameState: m.state.copy()}
+	if err := fn(tx); err != nil {
+		return before, before, err
+	}
+
+	next := tx.gameState
+	next.Version = m.state.Version
+	next.epoch = m.state.epoch
+	changed := !reflect.DeepEqual(next.Config, m.state.Config)
+	if changed || tx.persist != nil {
+		if err := next.Config.ValidateConfig(); err != nil {
+			return before, before, newAPIError(http.StatusBadRequest, "Invalid config: "+err.Error())
+		}
+		persist := tx.persist
+		if persist == nil {
+			persist = m.storage.SaveConfig
+		}
+		if err := persist(&next.Config); err != nil {
+			if apiErr, ok := err.(*apiError); ok {
+				return before, before, apiErr
+			}
+			return before, before, newAPIError(http.StatusInternalServerError, "Failed to save game state: "+err.Error())
+		}
+	}
+	if !reflect.DeepEqual(next.Config.GameSettings(), m.state.Config.GameSettings()) {
+		next.Version++
+	}
+
+	// The lock file only lets a restart resume the lock; a failure to write it is not worth failing the change
+	if lock := next.SpinLock(); !reflect.DeepEqual(lock, m.state.SpinLock()) {
+		if err := m.storage.SaveSpinLock(lock); err != nil {
+			log.Printf("Failed to persist spin lock: %v", err)
+		}
+	}
+
+	m.state = next
+	return before, m.state.copy(), nil
+}
+
+// staleStateError reports that a compare-and-swap lost to a newer settings change, with the current config
+func staleStateError(current gameState) error {
+	etag := current.ETag()
+	return &apiError{
+		Status:  http.StatusPreconditionFailed,
+		Message: "Resource was modified by someone else; reload and try again",
+		Details: gin.H{"etag": etag, "current": current.ConfigCopy()},
+		Headers: map[string]string{"ETag": etag},
+	}
+}
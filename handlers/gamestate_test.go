@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+)
+
+func newTestGameState(t *testing.T) *gameStateManager {
+	t.Helper()
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	m, err := newGameStateManager(store)
+	if err != nil {
+		t.Fatalf("newGameStateManager: %v", err)
+	}
+	return m
+}
+
+func TestVersionOf(t *testing.T) {
+	m := newTestGameState(t)
+	state := m.Snapshot()
+	current := state.ETag()
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    uint64
+	}{
+		{name: "no header", ifMatch: "", want: anyVersion},
+		{name: "wildcard", ifMatch: "*", want: anyVersion},
+		{name: "current tag", ifMatch: current, want: state.Version},
+		{name: "unquoted tag", ifMatch: state.epoch + "-" + strconv.FormatUint(state.Version, 10), want: state.Version},
+		{name: "one of several tags", ifMatch: `"other-3", ` + current, want: state.Version},
+		{name: "tag from before a restart", ifMatch: `"0-1"`, want: staleVersion},
+		{name: "malformed tag", ifMatch: `"garbage"`, want: staleVersion},
+		{name: "version zero", ifMatch: `"` + state.epoch + `-0"`, want: staleVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.VersionOf(tt.ifMatch); got != tt.want {
+				t.Errorf("VersionOf(%q) = %d, want %d", tt.ifMatch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	failure := errors.New("refused")
+
+	tests := []struct {
+		name        string
+		version     func(state gameState) uint64
+		change      func(tx *gameTx) error
+		wantStatus  int // 0 when the change commits
+		wantErr     error
+		wantBump    bool
+		wantChanged bool
+	}{
+		{
+			name:        "settings change at the current version",
+			version:     func(state gameState) uint64 { return state.Version },
+			change:      func(tx *gameTx) error { tx.Config.Mode2WinText = "中奖"; return nil },
+			wantBump:    true,
+			wantChanged: true,
+		},
+		{
+			name:        "settings change without a version",
+			version:     func(state gameState) uint64 { return anyVersion },
+			change:      func(tx *gameTx) error { tx.Config.Mode2WinText = "中奖"; return nil },
+			wantBump:    true,
+			wantChanged: true,
+		},
+		{
+			name:    "play state change keeps the version",
+			version: func(state gameState) uint64 { return state.Version },
+			change: func(tx *gameTx) error {
+				tx.Config.RemainingSpins++
+				tx.Config.CurrentPage = "advertisement"
+				return nil
+			},
+			wantChanged: true,
+		},
+		{
+			name:       "stale version",
+			version:    func(state gameState) uint64 { return state.Version - 1 },
+			change:     func(tx *gameTx) error { tx.Config.Mode2WinText = "中奖"; return nil },
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "foreign tag",
+			version:    func(state gameState) uint64 { return staleVersion },
+			change:     func(tx *gameTx) error { tx.Config.Mode2WinText = "中奖"; return nil },
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "invalid config",
+			version:    func(state gameState) uint64 { return state.Version },
+			change:     func(tx *gameTx) error { tx.Config.Mode = 9; return nil },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "transaction fails",
+			version: func(state gameState) uint64 { return state.Version },
+			change:  func(tx *gameTx) error { tx.Config.Mode2WinText = "中奖"; return failure },
+			wantErr: failure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestGameState(t)
+			// Start from a version above 1 so "one behind" is a real, older version
+			if _, _, err := m.Update(func(tx *gameTx) error { tx.Config.Mode2LoseText = "再来"; return nil }); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			start := m.Snapshot()
+
+			before, after, err := m.CompareAndSwap(tt.version(start), tt.change)
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantStatus != 0 {
+				apiErr, ok := err.(*apiError)
+				if !ok || apiErr.Status != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+				if tt.wantStatus == http.StatusPreconditionFailed && apiErr.Headers["ETag"] != start.ETag() {
+					t.Errorf("ETag header = %q, want %q", apiErr.Headers["ETag"], start.ETag())
+				}
+			}
+			if tt.wantErr == nil && tt.wantStatus == 0 && err != nil {
+				t.Fatalf("CompareAndSwap: %v", err)
+			}
+
+			if before.Version != start.Version {
+				t.Errorf("before.Version = %d, want %d", before.Version, start.Version)
+			}
+			wantVersion := start.Version
+			if tt.wantBump {
+				wantVersion++
+			}
+			current := m.Snapshot()
+			if current.Version != wantVersion || after.Version != wantVersion {
+				t.Errorf("version = %d (after %d), want %d", current.Version, after.Version, wantVersion)
+			}
+			if changed := current.Config.Mode2WinText != start.Config.Mode2WinText ||
+				current.Config.RemainingSpins != start.Config.RemainingSpins; changed != tt.wantChanged {
+				t.Errorf("config changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if (current.ETag() != start.ETag()) != tt.wantBump {
+				t.Errorf("ETag %s -> %s, want change %v", start.ETag(), current.ETag(), tt.wantBump)
+			}
+		})
+	}
+}
+
+func TestCompareAndSwapPersistsBeforeCommitting(t *testing.T) {
+	m := newTestGameState(t)
+	start := m.Snapshot()
+
+	_, _, err := m.Update(func(tx *gameTx) error {
+		tx.Config.Mode2WinText = "中奖"
+		tx.persist = func(config *models.GameConfig) error { return errors.New("disk full") }
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Update succeeded although the save failed")
+	}
+	if current := m.Snapshot(); current.Config.Mode2WinText != start.Config.Mode2WinText || current.Version != start.Version {
+		t.Errorf("state changed after a failed save: %+v", current.Config)
+	}
+
+	saved, err := m.storage.GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	if saved.Mode2WinText != start.Config.Mode2WinText {
+		t.Errorf("stored Mode2WinText = %q, want %q", saved.Mode2WinText, start.Config.Mode2WinText)
+	}
+}
+
+func TestUpdateConfigExpectedRemainingSpins(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name       string
+		update     models.ConfigUpdateRequest
+		wantStatus int // 0 when the update commits
+		wantSpins  int
+	}{
+		{
+			name:      "edit based on the current count",
+			update:    models.ConfigUpdateRequest{RemainingSpins: intPtr(10), ExpectedRemainingSpins: intPtr(4)},
+			wantSpins: 10,
+		},
+		{
+			name:       "edit based on the count before a spin",
+			update:     models.ConfigUpdateRequest{RemainingSpins: intPtr(10), ExpectedRemainingSpins: intPtr(5)},
+			wantStatus: http.StatusPreconditionFailed,
+			wantSpins:  4,
+		},
+		{
+			name:      "player change leaves the spins alone",
+			update:    models.ConfigUpdateRequest{CurrentPlayer: intPtr(8)},
+			wantSpins: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			if _, _, err := h.state.Update(func(tx *gameTx) error { tx.Config.RemainingSpins = 5; return nil }); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			// The admin form is filled now; a spin then uses one without changing the ETag
+			etag := h.state.Snapshot().ETag()
+			if _, _, err := h.state.Update(func(tx *gameTx) error { tx.Config.RemainingSpins--; return nil }); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			_, _, err := h.updateConfig(tt.update, etag)
+			if tt.wantStatus == 0 && err != nil {
+				t.Fatalf("updateConfig: %v", err)
+			}
+			if tt.wantStatus != 0 {
+				apiErr, ok := err.(*apiError)
+				if !ok || apiErr.Status != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+			}
+			if got := h.state.Snapshot().Config.RemainingSpins; got != tt.wantSpins {
+				t.Errorf("RemainingSpins = %d, want %d", got, tt.wantSpins)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader lets clients retry a spin without spinning twice
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	idempotencyWindow    = 10 * time.Minute // How long a key's result is replayed
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotentSpin is the outcome of the first request made with an idempotency key
+type idempotentSpin struct {
+	done    chan struct{} // Closed once the first request has finished
+	result  *models.SpinResult
+	config  *models.GameConfig
+	err     error
+	expires time.Time
+}
+
+// idempotencyCache remembers spin results by client and idempotency key
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotentSpin
+}
+
+// newIdempotencyCache creates an empty cache
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: make(map[string]*idempotentSpin)}
+}
+
+// begin returns the entry for key and whether the caller is the first to use it.
+// Later callers wait on the entry's done channel, so a retry arriving while the original
+// spin is still in flight gets the same result instead of "spin already in progress".
+func (c *idempotencyCache) begin(key string, now time.Time) (*idempotentSpin, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if isClosed(entry.done) && now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	if entry, ok := c.entries[key]; ok {
+		return entry, false
+	}
+
+	entry := &idempotentSpin{done: make(chan struct{})}
+	c.entries[key] = entry
+	return entry, true
+}
+
+// finish records the first request's outcome. Failed spins change no state,
+// so their key is released and a later retry spins normally.
+func (c *idempotencyCache) finish(key string, entry *idempotentSpin, result *models.SpinResult, config *models.GameConfig, err error, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.result = result
+	entry.config = config
+	entry.err = err
+	entry.expires = now.Add(idempotencyWindow)
+	if err != nil {
+		delete(c.entries, key)
+	}
+	close(entry.done)
+}
+
+// isClosed reports whether a done channel has been closed
+func isClosed(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// idempotentSpinRequest performs a spin at most once per client and Idempotency-Key.
+// replayed is true when the result comes from an earlier request.
+func (h *APIHandler) idempotentSpinRequest(c *gin.Context, key string) (result *models.SpinResult, config *models.GameConfig, replayed bool, err error) {
+	if len(key) > maxIdempotencyKeyLen {
+		return nil, nil, false, newAPIError(http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+	}
+
+	// Keys are per client so two tablets can't collide
+	client := c.GetString(contextTokenID)
+	if client == "" {
+		client = "user:" + c.GetString(contextUsername)
+	} else {
+		client = "token:" + client
+	}
+	cacheKey := client + "|" + key
+
+	entry, first := h.idempotency.begin(cacheKey, time.Now())
+	if !first {
+		select {
+		case <-entry.done:
+		case <-c.Request.Context().Done():
+			return nil, nil, false, newAPIError(http.StatusRequestTimeout, "Request cancelled while waiting for the original spin")
+		}
+		return entry.result, entry.config, true, entry.err
+	}
+
+	result, config, err = h.performSpin(auditActor(c), "")
+	h.idempotency.finish(cacheKey, entry, result, config, err, time.Now())
+	return result, config, false, err
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+func TestIdempotencyCache(t *testing.T) {
+	start := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+	spinErr := errors.New("spin already in progress")
+
+	tests := []struct {
+		name      string
+		firstKey  string
+		firstErr  error
+		secondKey string
+		after     time.Duration // Time between the first request finishing and the second arriving
+		wantFirst bool          // Whether the second request spins again
+	}{
+		{name: "retry is replayed", firstKey: "a", secondKey: "a", after: time.Second},
+		{name: "retry near the end of the window is replayed", firstKey: "a", secondKey: "a", after: idempotencyWindow},
+		{name: "retry after the window spins again", firstKey: "a", secondKey: "a", after: idempotencyWindow + time.Second, wantFirst: true},
+		{name: "failed spin releases the key", firstKey: "a", firstErr: spinErr, secondKey: "a", after: time.Second, wantFirst: true},
+		{name: "other key spins", firstKey: "a", secondKey: "b", after: time.Second, wantFirst: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newIdempotencyCache()
+			result := &models.SpinResult{Prize: "奖品1"}
+
+			entry, first := cache.begin(tt.firstKey, start)
+			if !first {
+				t.Fatal("first request was not treated as first")
+			}
+			cache.finish(tt.firstKey, entry, result, nil, tt.firstErr, start)
+
+			second, first := cache.begin(tt.secondKey, start.Add(tt.after))
+			if first != tt.wantFirst {
+				t.Fatalf("second request first = %v, want %v", first, tt.wantFirst)
+			}
+			if first {
+				return
+			}
+			if !isClosed(second.done) {
+				t.Fatal("replayed entry is not finished")
+			}
+			if second.result != result || second.err != nil {
+				t.Errorf("replayed %+v, %v; want %+v, nil", second.result, second.err, result)
+			}
+		})
+	}
+}
+
+func TestIdempotencyCacheWaitsForInFlightSpin(t *testing.T) {
+	cache := newIdempotencyCache()
+	now := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	entry, _ := cache.begin("a", now)
+
+	// An in-flight entry is never expired, however long the spin takes
+	retry, first := cache.begin("a", now.Add(2*idempotencyWindow))
+	if first || retry != entry {
+		t.Fatal("retry during the spin did not get the in-flight entry")
+	}
+	if isClosed(retry.done) {
+		t.Fatal("in-flight entry is already finished")
+	}
+
+	result := &models.SpinResult{Prize: "奖品2"}
+	go cache.finish("a", entry, result, nil, nil, now)
+
+	select {
+	case <-retry.done:
+	case <-time.After(time.Second):
+		t.Fatal("retry was not released when the spin finished")
+	}
+	if retry.result != result {
+		t.Errorf("retry got %+v, want %+v", retry.result, result)
+	}
+}
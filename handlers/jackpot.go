@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Progressive Jackpot
+//
+// In mode 3 every spin adds Mode3JackpotIncrement to a pool kept in
+// data/jackpot.json, and the rare jackpot segment pays out the whole pool,
+// which then starts again from Mode3JackpotSeed. Displays follow the pool
+// through jackpot_updated to show a ticking counter.
+
+// GetJackpot returns the current pool and the last payout, for the displays
+func (h *APIHandler) GetJackpot(c *gin.Context) {
+	jackpot, err := h.storage.GetJackpot()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get jackpot: " + err.Error()})
+		return
+	}
+
+	c.Header("ETag", entityTag(jackpot))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, jackpot)
+}
+
+// UpdateJackpot sets the pool if it still matches the If-Match ETag, e.g. to carry an
+// amount over. It is refused during a spin, which may be about to change the pool.
+func (h *APIHandler) UpdateJackpot(c *gin.Context) {
+	var request models.JackpotUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if *request.Pool < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jackpot pool cannot be negative"})
+		return
+	}
+
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var before, jackpot models.Jackpot
+	_, after, err := h.state.Update(func(tx *gameTx) error {
+		if tx.Spinning {
+			return spinLockedError("Cannot change the jackpot while spin is in progress", tx.Timeline)
+		}
+
+		current, err := h.storage.GetJackpot()
+		if err != nil {
+			return err
+		}
+		if err := checkIfMatch(ifMatch, current); err != nil {
+			return err
+		}
+
+		before = *current
+		jackpot = *current
+		jackpot.Pool = *request.Pool
+		jackpot.Updated = time.Now()
+		tx.persist = func(*models.GameConfig) error {
+			return h.storage.SaveJackpot(&jackpot)
+		}
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditJackpotUpdate, "", before, jackpot)
+	h.broadcastJackpot(models.JackpotChangeAdjusted, jackpot, after.Config.Mode3JackpotIncrement)
+
+	c.Header("ETag", entityTag(jackpot))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, jackpot)
+}
+
+// broadcastSpinJackpot announces the pool left by a mode 3 spin. Growth is shown as the
+// wheel starts; a payout waits for the reveal so the counter doesn't give the result away.
+func (h *APIHandler) broadcastSpinJackpot(jackpot models.Jackpot, result models.SpinResult, timeline models.SpinTimeline, increment float64) {
+	if result.Type != models.ResultTypeJackpot {
+		h.broadcastJackpot(models.JackpotChangeGrew, jackpot, increment)
+		return
+	}
+	time.AfterFunc(time.Until(timeline.Reveal), func() {
+		h.broadcastJackpot(models.JackpotChangeWon, jackpot, increment)
+	})
+}
+
+// broadcastJackpot sends the pool to every display
+func (h *APIHandler) broadcastJackpot(change string, jackpot models.Jackpot, increment float64) {
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventJackpotUpdated,
+			Data: models.JackpotUpdatedEvent{Change: change, Increment: increment, Jackpot: jackpot},
+		})
+	}
+}
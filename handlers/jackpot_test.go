@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSpinGrowsAndPaysJackpot(t *testing.T) {
+	tests := []struct {
+		name        string
+		rate        float64
+		wantType    string
+		wantPool    float64
+		wantPaidOut float64
+	}{
+		{name: "losing spin grows the pool", rate: 0, wantType: models.ResultTypeLose, wantPool: 1010},
+		{name: "jackpot pays the grown pool and reseeds", rate: 100, wantType: models.ResultTypeJackpot, wantPool: 500, wantPaidOut: 1010},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			if err := h.storage.SaveJackpot(&models.Jackpot{Pool: 1000}); err != nil {
+				t.Fatalf("SaveJackpot: %v", err)
+			}
+			if _, _, err := h.state.Update(func(tx *gameTx) error {
+				tx.Config.Mode = 3
+				tx.Config.Mode3JackpotRate = tt.rate
+				tx.Config.Mode3JackpotSeed = 500
+				tx.Config.Mode3JackpotIncrement = 10
+				tx.Config.RemainingSpins = 1
+				return nil
+			}); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			result, _, err := h.performSpin(models.AuditActor{Username: "admin"}, "")
+			if err != nil {
+				t.Fatalf("performSpin: %v", err)
+			}
+			if result.Type != tt.wantType || result.JackpotAmount != tt.wantPaidOut {
+				t.Errorf("result %s paying %v, want %s paying %v", result.Type, result.JackpotAmount, tt.wantType, tt.wantPaidOut)
+			}
+
+			jackpot, err := h.storage.GetJackpot()
+			if err != nil {
+				t.Fatalf("GetJackpot: %v", err)
+			}
+			if jackpot.Pool != tt.wantPool {
+				t.Errorf("pool = %v, want %v", jackpot.Pool, tt.wantPool)
+			}
+			if won := jackpot.LastWin != nil; won != (tt.wantPaidOut > 0) || (won && jackpot.LastWin.Amount != tt.wantPaidOut) {
+				t.Errorf("last win = %+v, want a win of %v", jackpot.LastWin, tt.wantPaidOut)
+			}
+		})
+	}
+}
+
+func TestUpdateJackpot(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		ifMatch    func(current string) string
+		spinning   bool
+		wantStatus int
+		wantPool   float64
+	}{
+		{name: "current ETag", body: `{"pool": 750}`, ifMatch: func(current string) string { return current }, wantStatus: http.StatusOK, wantPool: 750},
+		{name: "no If-Match", body: `{"pool": 750}`, ifMatch: func(current string) string { return "" }, wantStatus: http.StatusPreconditionRequired, wantPool: 1000},
+		{name: "stale ETag", body: `{"pool": 750}`, ifMatch: func(current string) string { return `"stale"` }, wantStatus: http.StatusPreconditionFailed, wantPool: 1000},
+		{name: "negative pool", body: `{"pool": -1}`, ifMatch: func(current string) string { return current }, wantStatus: http.StatusBadRequest, wantPool: 1000},
+		{name: "missing pool", body: `{}`, ifMatch: func(current string) string { return current }, wantStatus: http.StatusBadRequest, wantPool: 1000},
+		{name: "during a spin", body: `{"pool": 750}`, ifMatch: func(current string) string { return current }, spinning: true, wantStatus: http.StatusLocked, wantPool: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			current := &models.Jackpot{Pool: 1000}
+			if err := h.storage.SaveJackpot(current); err != nil {
+				t.Fatalf("SaveJackpot: %v", err)
+			}
+			if tt.spinning {
+				lockSpin(t, h, time.Now().Add(time.Minute))
+			}
+
+			r := gin.New()
+			r.PUT("/api/jackpot", h.UpdateJackpot)
+			req := httptest.NewRequest(http.MethodPut, "/api/jackpot", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if ifMatch := tt.ifMatch(entityTag(current)); ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if jackpot, err := h.storage.GetJackpot(); err != nil || jackpot.Pool != tt.wantPool {
+				t.Errorf("pool = %+v, %v; want %v", jackpot, err, tt.wantPool)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+// loginPageHTML is the built-in admin login and first-run setup page
+const loginPageHTML = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>幸运转盘 - 管理员登录</title>
+    <style>
+        body { font-family: 'Microsoft YaHei', sans-serif; padding: 20px; background: linear-gradient(135deg, #2c3e50 0%, #34495e 100%); color: white; min-height: 100vh; margin: 0; box-sizing: border-box; }
+        .container { max-width: 360px; margin: 60px auto; background: rgba(255,255,255,0.1); padding: 30px; border-radius: 15px; backdrop-filter: blur(10px); }
+        input { width: 100%; box-sizing: border-box; padding: 10px; margin: 8px 0; border: none; border-radius: 5px; background: rgba(255,255,255,0.9); font-size: 16px; }
+        button { width: 100%; background: #3498db; color: white; border: none; padding: 12px; border-radius: 5px; margin-top: 12px; font-size: 16px; cursor: pointer; }
+        .error { color: #ff7675; min-height: 1.5em; margin-top: 10px; }
+        .hint { color: #dfe6e9; font-size: 14px; }
+        .hidden { display: none; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1 id="title">⚙️ 管理员登录</h1>
+        <p id="setupHint" class="hint hidden">首次使用：请设置管理员密码或PIN（至少4位）。</p>
+        <form id="form">
+            <input id="username" type="text" placeholder="用户名 (默认 admin)" autocomplete="username">
+            <input id="password" type="password" placeholder="密码 / PIN" autocomplete="current-password" required>
+            <input id="confirm" class="hidden" type="password" placeholder="确认密码 / PIN" autocomplete="new-password">
+            <button id="submit" type="submit">登录</button>
+        </form>
+        <div id="error" class="error"></div>
+    </div>
+    <script>
+        var setupMode = false;
+        var next = new URLSearchParams(window.location.search).get('next') || '/admin';
+        if (next.charAt(0) !== '/' || next.charAt(1) === '/') { next = '/admin'; }
+
+        fetch('/api/auth/status').then(function (r) { return r.json(); }).then(function (status) {
+            if (status.authenticated) { window.location.replace(next); return; }
+            if (status.setup_required) {
+                setupMode = true;
+                document.getElementById('title').textContent = '⚙️ 初始设置';
+                document.getElementById('setupHint').classList.remove('hidden');
+                document.getElementById('confirm').classList.remove('hidden');
+                document.getElementById('password').setAttribute('autocomplete', 'new-password');
+                document.getElementById('submit').textContent = '创建管理员';
+            }
+        });
+
+        document.getElementById('form').addEventListener('submit', function (e) {
+            e.preventDefault();
+            var error = document.getElementById('error');
+            var password = document.getElementById('password').value;
+            if (setupMode && password !== document.getElementById('confirm').value) {
+                error.textContent = '两次输入的密码不一致';
+                return;
+            }
+            fetch(setupMode ? '/api/auth/setup' : '/api/auth/login', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ username: document.getElementById('username').value, password: password })
+            }).then(function (r) {
+                return r.json().then(function (body) {
+                    if (!r.ok) { throw new Error(body.error || '登录失败'); }
+                    window.location.replace(next);
+                });
+            }).catch(function (err) { error.textContent = err.message; });
+        });
+    </script>
+</body>
+</html>`
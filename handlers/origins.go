@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// originLogInterval limits how often the same rejected origin is logged
+const originLogInterval = time.Minute
+
+// OriginPolicy decides which browser origins may call the API and open WebSockets.
+// It is shared by the CORS middleware and the WebSocket upgrader.
+type OriginPolicy struct {
+	allowed   map[string]bool
+	wildcards [][2]string // Prefix and suffix around a single "*", e.g. "http://192.168.1.*"; see matchWildcard
+	dev       bool        // Allow every origin
+
+	logMu    sync.Mutex
+	lastLogs map[string]time.Time
+}
+
+// NewOriginPolicy creates a policy from allowed origins such as "http://192.168.1.20:8080".
+// A single "*" in the host matches one label or address octet. Same-origin requests are always allowed.
+func NewOriginPolicy(origins []string, dev bool) (*OriginPolicy, error) {
+	policy := &OriginPolicy{
+		allowed:  make(map[string]bool),
+		dev:      dev,
+		lastLogs: make(map[string]time.Time),
+	}
+
+	for _, origin := range origins {
+		origin = normalizeOrigin(origin)
+		if origin == "" {
+			continue
+		}
+
+		if prefix, suffix, found := strings.Cut(origin, "*"); found {
+			if strings.Contains(suffix, "*") {
+				return nil, fmt.Errorf("origin %s may contain only one '*'", origin)
+			}
+			parsed, err := url.Parse(prefix + "wildcard" + suffix)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Path != "" ||
+				!strings.Contains(parsed.Hostname(), "wildcard") {
+				return nil, fmt.Errorf("invalid origin %s: expected scheme://host[:port] with '*' in the host", origin)
+			}
+			policy.wildcards = append(policy.wildcards, [2]string{prefix, suffix})
+			continue
+		}
+
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.Path != "" {
+			return nil, fmt.Errorf("invalid origin %s: expected scheme://host[:port]", origin)
+		}
+		policy.allowed[origin] = true
+	}
+
+	return policy, nil
+}
+
+// Dev reports whether the policy allows every origin
+func (p *OriginPolicy) Dev() bool {
+	return p.dev
+}
+
+// Allow reports whether a request from origin may proceed, logging rejections
+func (p *OriginPolicy) Allow(origin string, r *http.Request) bool {
+	// Non-browser clients (keypads, POS, curl) send no Origin
+	if origin == "" || p.dev {
+		return true
+	}
+
+	normalized := normalizeOrigin(origin)
+	if parsed, err := url.Parse(normalized); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+	if p.allowed[normalized] {
+		return true
+	}
+	for _, wildcard := range p.wildcards {
+		if matchWildcard(normalized, wildcard[0], wildcard[1]) {
+			return true
+		}
+	}
+
+	p.logRejected(origin, r)
+	return false
+}
+
+// CheckOrigin is the WebSocket upgrader hook
+func (p *OriginPolicy) CheckOrigin(r *http.Request) bool {
+	return p.Allow(r.Header.Get("Origin"), r)
+}
+
+// logRejected logs a rejected origin at most once per originLogInterval
+func (p *OriginPolicy) logRejected(origin string, r *http.Request) {
+	p.logMu.Lock()
+	defer p.logMu.Unlock()
+
+	now := time.Now()
+	if last, ok := p.lastLogs[origin]; ok && now.Sub(last) < originLogInterval {
+		return
+	}
+	if len(p.lastLogs) > 1000 {
+		p.lastLogs = make(map[string]time.Time)
+	}
+	p.lastLogs[origin] = now
+
+	log.Printf("Rejected request from origin %s: %s %s (client %s)", origin, r.Method, r.URL.Path, requestIP(r))
+}
+
+// matchWildcard reports whether origin is prefix and suffix around exactly one host label or
+// address octet, so "http://192.168.1.*" matches "http://192.168.1.20" but neither
+// "http://192.168.1.attacker.com" nor another port
+func matchWildcard(origin, prefix, suffix string) bool {
+	if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	label := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(label, ".:/@[]")
+}
+
+// normalizeOrigin lowercases an origin and strips a trailing slash
+func normalizeOrigin(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
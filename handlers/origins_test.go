@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginPolicyAllow(t *testing.T) {
+	policy, err := NewOriginPolicy([]string{
+		"http://pos.local:3000",
+		"http://192.168.1.*",
+		"https://*.bar.example:8443",
+	}, false)
+	if err != nil {
+		t.Fatalf("NewOriginPolicy: %v", err)
+	}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "", want: true},
+		{origin: "http://spinner.local:8080", want: true}, // Same origin as the request
+		{origin: "http://pos.local:3000", want: true},
+		{origin: "HTTP://POS.LOCAL:3000/", want: true},
+		{origin: "http://pos.local:3001", want: false},
+		{origin: "http://192.168.1.20", want: true},
+		{origin: "http://192.168.1.attacker.com", want: false},
+		{origin: "http://192.168.1.20.attacker.com", want: false},
+		{origin: "http://192.168.1.20:3000", want: false},
+		{origin: "http://192.168.1.", want: false},
+		{origin: "http://192.168.1.x@attacker.com", want: false},
+		{origin: "https://192.168.1.20", want: false},
+		{origin: "https://tv.bar.example:8443", want: true},
+		{origin: "https://evil.tv.bar.example:8443", want: false},
+		{origin: "https://.bar.example:8443", want: false},
+		{origin: "https://tv.bar.example", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://spinner.local:8080/api/config", nil)
+			if got := policy.Allow(tt.origin, r); got != tt.want {
+				t.Errorf("Allow(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOriginPolicyRejectsBadWildcards(t *testing.T) {
+	for _, origin := range []string{
+		"http://*.*.example",
+		"*",
+		"http://pos.local:*",
+		"http://pos.local/*",
+		"ftp://*.example",
+	} {
+		if _, err := NewOriginPolicy([]string{origin}, false); err == nil {
+			t.Errorf("NewOriginPolicy(%q) succeeded, want error", origin)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routePermissions maps each admin route ("METHOD /full/path") to the permission it needs.
+// An empty permission only requires a login. Routes missing here are denied.
+var routePermissions = map[string]string{
+	"POST /api/auth/logout":   "",
+	"POST /api/auth/password": "",
+
+	// Game operation; /config needs edit_config for anything beyond selecting the current player
+	"POST /api/config":      models.PermissionSetPlayer,
+	"POST /api/spin":        models.PermissionSpin,
+	"POST /api/switch-page": models.PermissionSwitchPage,
+	"POST /api/reset":       models.PermissionResetHistory,
+
+	// Spin lock
+	"GET /api/spin/lock":    "",
+	"DELETE /api/spin/lock": models.PermissionForceUnlock,
+
+	// Progressive jackpot
+	"PUT /api/jackpot": models.PermissionEditConfig,
+
+	// Scheduled page switches
+	"GET /api/page-transitions":        "",
+	"DELETE /api/page-transitions":     models.PermissionSwitchPage,
+	"DELETE /api/page-transitions/:id": models.PermissionSwitchPage,
+
+	// Display schedule
+	"GET /api/display-schedule":             "",
+	"PUT /api/display-schedule":             models.PermissionEditConfig,
+	"GET /api/display-schedule/status":      "",
+	"DELETE /api/display-schedule/override": models.PermissionSwitchPage,
+
+	// Restaurant content
+	"POST /api/restaurant/config":     models.PermissionEditConfig,
+	"POST /api/advertisements":        models.PermissionManageAds,
+	"DELETE /api/advertisements/:id":  models.PermissionManageAds,
+	"PUT /api/menu/:id":               models.PermissionManageMenu,
+	"POST /api/recommendations":       models.PermissionManageMenu,
+	"PUT /api/recommendations/:id":    models.PermissionManageMenu,
+	"DELETE /api/recommendations/:id": models.PermissionManageMenu,
+
+	// Player registry
+	"GET /api/players":        models.PermissionManagePlayers,
+	"POST /api/players":       models.PermissionManagePlayers,
+	"GET /api/players/:id":    models.PermissionManagePlayers,
+	"PUT /api/players/:id":    models.PermissionManagePlayers,
+	"DELETE /api/players/:id": models.PermissionManagePlayers,
+
+	// Customer queue
+	"POST /api/queue":                 models.PermissionManagePlayers,
+	"POST /api/queue/next":            models.PermissionManagePlayers,
+	"PUT /api/queue/order":            models.PermissionManagePlayers,
+	"DELETE /api/queue/:player_id":    models.PermissionManagePlayers,
+	"POST /api/queue/:player_id/skip": models.PermissionManagePlayers,
+
+	// POS bills
+	"POST /api/pos/bills": models.PermissionPostBills,
+	"GET /api/pos/bills":  models.PermissionPostBills,
+	"GET /api/pos/rules":  "",
+	"PUT /api/pos/rules":  models.PermissionEditConfig,
+
+	// Self-service spin links
+	"POST /api/spin-links":           models.PermissionManagePlayers,
+	"GET /api/spin-links":            models.PermissionManagePlayers,
+	"DELETE /api/spin-links/:id":     models.PermissionManagePlayers,
+	"GET /api/spin-links/:id/qr.png": models.PermissionManagePlayers,
+
+	// Prize vouchers
+	"GET /api/vouchers":             models.PermissionRedeemVoucher,
+	"GET /api/vouchers/outstanding": models.PermissionRedeemVoucher,
+	"GET /api/vouchers/settings":    "",
+	"PUT /api/vouchers/settings":    models.PermissionEditConfig,
+	"POST /api/vouchers/redeem":     models.PermissionRedeemVoucher,
+	"GET /api/vouchers/:code":       models.PermissionRedeemVoucher,
+	"POST /api/vouchers/:code/void": models.PermissionVoidVoucher,
+
+	// Display devices
+	"POST /api/devices":             models.PermissionManageDevices,
+	"PUT /api/devices/:id":          models.PermissionManageDevices,
+	"DELETE /api/devices/:id":       models.PermissionManageDevices,
+	"POST /api/devices/:id/command": models.PermissionManageDevices,
+
+	// Audit log
+	"GET /api/audit":        models.PermissionViewAudit,
+	"GET /api/audit/verify": models.PermissionViewAudit,
+
+	// Account management
+	"GET /api/users":              models.PermissionManageUsers,
+	"POST /api/users":             models.PermissionManageUsers,
+	"PUT /api/users/:username":    models.PermissionManageUsers,
+	"DELETE /api/users/:username": models.PermissionManageUsers,
+
+	// API tokens
+	"GET /api/tokens":        models.PermissionManageTokens,
+	"POST /api/tokens":       models.PermissionManageTokens,
+	"DELETE /api/tokens/:id": models.PermissionManageTokens,
+}
+
+// publicTokenRoutes are public routes that API tokens still need a scope for
+var publicTokenRoutes = map[string]string{
+	"GET /api/history": models.PermissionReadHistory,
+}
+
+// tokenRoutePermission returns the permission an API token needs for a route.
+// ok is false for public routes tokens may call freely.
+func tokenRoutePermission(route string) (permission string, ok bool) {
+	if permission, ok := routePermissions[route]; ok {
+		return permission, true
+	}
+	permission, ok = publicTokenRoutes[route]
+	return permission, ok
+}
+
+// RequireRoutePermissions is middleware checking the logged-in role against routePermissions.
+// It must run after RequireAdmin.
+func (a *AuthHandler) RequireRoutePermissions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		permission, ok := routePermissions[route]
+		if !ok {
+			log.Printf("No permission configured for admin route %s, denying", route)
+			permissionDenied(c, models.PermissionManageUsers)
+			c.Abort()
+			return
+		}
+
+		if permission != "" && !requirePermission(c, permission) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// requirePermission reports whether the logged-in role or API token grants a permission,
+// writing a 403 response if it does not
+func requirePermission(c *gin.Context, permission string) bool {
+	for _, granted := range c.GetStringSlice(contextPermissions) {
+		if granted == permission {
+			return true
+		}
+	}
+	permissionDenied(c, permission)
+	return false
+}
+
+// permissionDenied writes a 403 response the frontend can use to hide or disable the action
+func permissionDenied(c *gin.Context, permission string) {
+	response := gin.H{
+		"error":               "Permission denied: requires " + permission,
+		"permission_denied":   true,
+		"required_permission": permission,
+		"role":                c.GetString(contextRole),
+	}
+	if tokenID := c.GetString(contextTokenID); tokenID != "" {
+		response["token_id"] = tokenID
+	}
+	c.JSON(http.StatusForbidden, response)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestAPIHandler(t *testing.T) *APIHandler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	h, err := NewAPIHandler(store)
+	if err != nil {
+		t.Fatalf("NewAPIHandler: %v", err)
+	}
+	return h
+}
+
+func TestUpdateConfigPermissions(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		body       string
+		wantStatus int
+	}{
+		{name: "staff selects the player", role: models.RoleStaff, body: `{"current_player": 7}`, wantStatus: http.StatusOK},
+		{name: "staff can't set venue spins", role: models.RoleStaff, body: `{"remaining_spins": 99}`, wantStatus: http.StatusForbidden},
+		{name: "staff can't set venue spins with the player", role: models.RoleStaff, body: `{"current_player": 7, "remaining_spins": 99}`, wantStatus: http.StatusForbidden},
+		{name: "staff can't change settings", role: models.RoleStaff, body: `{"mode2_win_rate": 90}`, wantStatus: http.StatusForbidden},
+		{name: "manager sets venue spins", role: models.RoleManager, body: `{"remaining_spins": 99}`, wantStatus: http.StatusOK},
+		{name: "manager changes settings", role: models.RoleManager, body: `{"mode2_win_rate": 90}`, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			start := h.state.Snapshot()
+
+			r := gin.New()
+			r.POST("/api/config", func(c *gin.Context) {
+				c.Set(contextRole, tt.role)
+				c.Set(contextPermissions, models.PermissionsForRole(tt.role))
+			}, h.UpdateConfig)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/config", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", start.ETag())
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if current := h.state.Snapshot(); w.Code != http.StatusOK && current.Config.RemainingSpins != start.Config.RemainingSpins {
+				t.Errorf("refused update changed remaining spins to %d", current.Config.RemainingSpins)
+			}
+		})
+	}
+}
+
+func TestExecuteCommandSetPlayerPermissions(t *testing.T) {
+	player, spins := 7, 99
+
+	tests := []struct {
+		name       string
+		role       string
+		cmd        models.CommandMessage
+		wantStatus int // 0 when the command runs
+	}{
+		{name: "staff selects the player", role: models.RoleStaff, cmd: models.CommandMessage{Command: models.CommandSetPlayer, Player: &player}},
+		{name: "staff can't set venue spins", role: models.RoleStaff, cmd: models.CommandMessage{Command: models.CommandSetPlayer, Player: &player, RemainingSpins: &spins}, wantStatus: http.StatusForbidden},
+		{name: "manager sets venue spins", role: models.RoleManager, cmd: models.CommandMessage{Command: models.CommandSetPlayer, Player: &player, RemainingSpins: &spins}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			start := h.state.Snapshot()
+
+			_, _, err := h.ExecuteCommand(models.AuditActor{Username: "u", Role: tt.role}, tt.cmd)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("ExecuteCommand: %v", err)
+				}
+				return
+			}
+			apiErr, ok := err.(*apiError)
+			if !ok || apiErr.Status != tt.wantStatus {
+				t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+			}
+			if current := h.state.Snapshot(); current.Config.CurrentPlayer != start.Config.CurrentPlayer ||
+				current.Config.RemainingSpins != start.Config.RemainingSpins {
+				t.Errorf("refused command changed the config")
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+// playPageHTML is the customer's phone page for a self-service spin link
+const playPageHTML = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta name="robots" content="noindex">
+    <title>幸运转盘 - 抽奖</title>
+    <style>
+        body { font-family: 'Microsoft YaHei', sans-serif; padding: 20px; background: linear-gradient(135deg, #2c3e50 0%, #34495e 100%); color: white; min-height: 100vh; margin: 0; box-sizing: border-box; text-align: center; }
+        .container { max-width: 360px; margin: 40px auto; background: rgba(255,255,255,0.1); padding: 30px; border-radius: 15px; backdrop-filter: blur(10px); }
+        button { width: 100%; background: #e67e22; color: white; border: none; padding: 18px; border-radius: 10px; margin-top: 16px; font-size: 22px; cursor: pointer; }
+        button:disabled { background: #7f8c8d; }
+        .prize { font-size: 28px; font-weight: bold; margin: 16px 0; }
+        .code { font-family: monospace; font-size: 30px; letter-spacing: 3px; background: white; color: #2c3e50; padding: 10px; border-radius: 8px; margin: 10px 0; }
+        .error { color: #ff7675; min-height: 1.5em; margin-top: 10px; }
+        .hint { color: #dfe6e9; font-size: 14px; }
+        .hidden { display: none; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>🎡 幸运转盘</h1>
+        <p id="who" class="hint"></p>
+        <div id="ready" class="hidden">
+            <p>准备好了吗？点击按钮，转盘会在大屏幕上转动。</p>
+            <button id="spin">开始抽奖</button>
+        </div>
+        <div id="spinning" class="hidden">
+            <p>转盘正在大屏幕上转动……</p>
+        </div>
+        <div id="result" class="hidden">
+            <p>抽奖结果</p>
+            <div id="prize" class="prize"></div>
+            <div id="voucher" class="hidden">
+                <p>请向服务员出示兑奖码领取奖品：</p>
+                <div id="code" class="code"></div>
+                <p id="expires" class="hint"></p>
+            </div>
+        </div>
+        <div id="error" class="error"></div>
+    </div>
+    <script>
+        var token = window.location.pathname.split('/').pop();
+        var api = '/api/play/' + encodeURIComponent(token);
+
+        function show(id) {
+            ['ready', 'spinning', 'result'].forEach(function (name) {
+                document.getElementById(name).classList.toggle('hidden', name !== id);
+            });
+        }
+
+        function showResult(status) {
+            document.getElementById('prize').textContent = status.result ? status.result.prize : '';
+            if (status.voucher) {
+                document.getElementById('code').textContent = status.voucher.code;
+                document.getElementById('expires').textContent = '有效期至 ' + new Date(status.voucher.expires).toLocaleString();
+                document.getElementById('voucher').classList.remove('hidden');
+            }
+            show('result');
+        }
+
+        function showStatus(status) {
+            var who = status.table ? status.table + ' 号桌' : '';
+            if (status.nickname) { who += (who ? ' · ' : '') + status.nickname; }
+            document.getElementById('who').textContent = who;
+
+            if (status.status === 'active') { show('ready'); return; }
+            if (status.status === 'used') { showResult(status); return; }
+            show('');
+            document.getElementById('error').textContent = status.status === 'expired' ? '链接已过期，请联系服务员。' : '链接已失效，请联系服务员。';
+        }
+
+        fetch(api).then(function (r) {
+            return r.json().then(function (body) {
+                if (!r.ok) { throw new Error(body.error || '链接无效'); }
+                showStatus(body);
+            });
+        }).catch(function (err) {
+            document.getElementById('error').textContent = err.message;
+        });
+
+        document.getElementById('spin').addEventListener('click', function () {
+            var button = this;
+            button.disabled = true;
+            document.getElementById('error').textContent = '';
+            fetch(api + '/spin', { method: 'POST' }).then(function (r) {
+                return r.json().then(function (body) {
+                    if (!r.ok) {
+                        if (r.status === 409 && body.result) { showResult(body); return; }
+                        throw new Error(body.error || '抽奖失败');
+                    }
+                    show('spinning');
+                    // Reveal together with the big screen
+                    var wait = new Date(body.timeline.reveal) - new Date(body.server_time);
+                    setTimeout(function () { showResult(body); }, Math.max(0, wait));
+                });
+            }).catch(function (err) {
+                button.disabled = false;
+                document.getElementById('error').textContent = err.message + '，请稍后再试。';
+            });
+        });
+    </script>
+</body>
+</html>
+`
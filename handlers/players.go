@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Player Registry API Endpoints
+
+// GetPlayers returns all registered players, optionally only those at ?table=
+func (h *APIHandler) GetPlayers(c *gin.Context) {
+	players, err := h.storage.GetPlayers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get players: " + err.Error()})
+		return
+	}
+
+	if table := strings.TrimSpace(c.Query("table")); table != "" {
+		atTable := make([]models.Player, 0)
+		for _, player := range players {
+			if player.Table == table {
+				atTable = append(atTable, player)
+			}
+		}
+		players = atTable
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"players": players})
+}
+
+// GetPlayer returns a player with their spin history
+func (h *APIHandler) GetPlayer(c *gin.Context) {
+	playerID := c.Param("id")
+	player, err := h.storage.GetPlayer(playerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get player: " + err.Error()})
+		return
+	}
+
+	history, err := h.storage.GetHistory()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get history: " + err.Error()})
+		return
+	}
+	results := make([]models.SpinResult, 0)
+	for _, result := range history.Results {
+		if result.PlayerID == playerID {
+			results = append(results, result)
+		}
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, models.PlayerDetail{Player: *player, History: results})
+}
+
+// CreatePlayer registers a new player
+func (h *APIHandler) CreatePlayer(c *gin.Context) {
+	var request models.PlayerUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	now := time.Now()
+	player := models.Player{
+		ID:      generateID(),
+		Created: now,
+		Updated: now,
+	}
+	request.Apply(&player)
+
+	if err := h.storage.AddPlayer(player); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to register player: " + err.Error()})
+		return
+	}
+	h.audit(auditActor(c), models.AuditPlayerCreate, player.ID, nil, player)
+	h.broadcastPlayerUpdated(player)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, player)
+}
+
+// UpdatePlayer changes a player's details or spin allowance
+func (h *APIHandler) UpdatePlayer(c *gin.Context) {
+	playerID := c.Param("id")
+
+	var request models.PlayerUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if _, err := h.storage.GetPlayer(playerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get player: " + err.Error()})
+		return
+	}
+
+	before, player, err := h.storage.UpdatePlayer(playerID, request.Apply)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update player: " + err.Error()})
+		return
+	}
+	h.audit(auditActor(c), models.AuditPlayerUpdate, playerID, before, player)
+	h.broadcastPlayerUpdated(player)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, player)
+}
+
+// DeletePlayer removes a player who is not currently selected
+func (h *APIHandler) DeletePlayer(c *gin.Context) {
+	playerID := c.Param("id")
+
+	if h.state.Snapshot().Config.CurrentPlayerID == playerID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Player is selected for the next spin; select another player first"})
+		return
+	}
+
+	before, _ := h.storage.GetPlayer(playerID)
+	if err := h.storage.DeletePlayer(playerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to delete player: " + err.Error()})
+		return
+	}
+	h.audit(auditActor(c), models.AuditPlayerDelete, playerID, before, nil)
+	h.dequeuePlayer(playerID)
+
+	// Broadcast player deletion
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventPlayerDeleted,
+			Data: models.DeletedEvent{ID: playerID},
+		})
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"message": "Player deleted successfully"})
+}
+
+// broadcastPlayerUpdated announces a player change to every screen, with the phone masked
+func (h *APIHandler) broadcastPlayerUpdated(player models.Player) {
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventPlayerUpdated,
+			Data: player.Public(),
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Display Schedule
+//
+// The display schedule is a weekly playlist of time slots, each showing one page
+// or rotating through several. A background loop compares it with the current
+// page every tick and switches the global page like SwitchPage would. A manual
+// page switch pauses the schedule for its override time; the post-spin
+// advertisement break and running spins are never interrupted.
+
+// displayPlaylist holds the display schedule and the manual override pausing it
+type displayPlaylist struct {
+	mutex   sync.Mutex
+	storage *storage.Storage
+	data    models.DisplayScheduleData
+}
+
+// newDisplayPlaylist loads the display schedule from storage
+func newDisplayPlaylist(store *storage.Storage) (*displayPlaylist, error) {
+	data, err := store.GetDisplaySchedule()
+	if err != nil {
+		return nil, err
+	}
+	if data.Schedule.Slots == nil {
+		data.Schedule.Slots = make([]models.ScheduleSlot, 0)
+	}
+	return &displayPlaylist{storage: store, data: *data}, nil
+}
+
+// Snapshot returns the schedule and override; slots are replaced, never edited in place
+func (p *displayPlaylist) Snapshot() models.DisplayScheduleData {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	data := p.data
+	if data.Override != nil {
+		override := *data.Override
+		data.Override = &override
+	}
+	return data
+}
+
+// Replace validates and saves a new schedule if the current one still matches ifMatch
+func (p *displayPlaylist) Replace(schedule models.DisplaySchedule, ifMatch string) (before, after models.DisplaySchedule, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	before = p.data.Schedule
+	if err := checkIfMatch(ifMatch, before); err != nil {
+		return before, before, err
+	}
+
+	if schedule.Slots == nil {
+		schedule.Slots = make([]models.ScheduleSlot, 0)
+	}
+	if err := schedule.Validate(); err != nil {
+		return before, before, newAPIError(http.StatusBadRequest, "Invalid schedule: "+err.Error())
+	}
+	for i := range schedule.Slots {
+		if schedule.Slots[i].ID == "" {
+			schedule.Slots[i].ID = generateID() + "-" + strconv.Itoa(i)
+		}
+	}
+
+	if err := p.saveUnsafe(schedule, p.data.Override); err != nil {
+		return before, before, err
+	}
+	return before, schedule, nil
+}
+
+// Pause sets the override after a manual page switch; it returns nil when the schedule is off or has no override time
+func (p *displayPlaylist) Pause(page string, actor models.AuditActor, now time.Time) (*models.ScheduleOverride, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.data.Schedule.Enabled || p.data.Schedule.OverrideMinutes == 0 {
+		return nil, nil
+	}
+	override := &models.ScheduleOverride{
+		Page:  page,
+		Until: now.Add(time.Duration(p.data.Schedule.OverrideMinutes) * time.Minute),
+		Actor: &actor,
+	}
+	if err := p.saveUnsafe(p.data.Schedule, override); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// Resume clears the override, returning it if it was still in force at now
+func (p *displayPlaylist) Resume(now time.Time) (*models.ScheduleOverride, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	override := p.data.Override
+	if override == nil {
+		return nil, nil
+	}
+	if err := p.saveUnsafe(p.data.Schedule, nil); err != nil {
+		return nil, err
+	}
+	if !now.Before(override.Until) {
+		return nil, nil
+	}
+	return override, nil
+}
+
+// Expire clears an override whose time ran out, reporting whether it did
+func (p *displayPlaylist) Expire(now time.Time) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.data.Override == nil || now.Before(p.data.Override.Until) {
+		return false, nil
+	}
+	if err := p.saveUnsafe(p.data.Schedule, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// saveUnsafe persists and installs the schedule and override (internal use)
+func (p *displayPlaylist) saveUnsafe(schedule models.DisplaySchedule, override *models.ScheduleOverride) error {
+	data := models.DisplayScheduleData{Schedule: schedule, Override: override}
+	if err := p.storage.SaveDisplaySchedule(&data); err != nil {
+		return err
+	}
+	p.data = data
+	return nil
+}
+
+// scheduleStatus describes what the schedule wants at now, including any override in force
+func scheduleStatus(data models.DisplayScheduleData, now time.Time) models.DisplayScheduleStatus {
+	status := data.Schedule.Status(now)
+	if data.Override != nil && now.Before(data.Override.Until) {
+		status.Override = data.Override
+	}
+	return status
+}
+
+// GetDisplaySchedule returns the display schedule with its ETag
+func (h *APIHandler) GetDisplaySchedule(c *gin.Context) {
+	schedule := h.playlist.Snapshot().Schedule
+
+	c.Header("ETag", entityTag(schedule))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateDisplaySchedule replaces the display schedule if it still matches the If-Match ETag
+func (h *APIHandler) UpdateDisplaySchedule(c *gin.Context) {
+	var schedule models.DisplaySchedule
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	before, after, err := h.playlist.Replace(schedule, ifMatch)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditScheduleUpdate, "", before, after)
+	h.broadcastDisplaySchedule(models.ScheduleChangeUpdated)
+
+	// Show the new schedule straight away rather than on the next tick
+	h.applyDisplaySchedule()
+
+	c.Header("ETag", entityTag(after))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, after)
+}
+
+// GetDisplayScheduleStatus returns the slot and page the schedule wants now and any override
+func (h *APIHandler) GetDisplayScheduleStatus(c *gin.Context) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, scheduleStatus(h.playlist.Snapshot(), time.Now()))
+}
+
+// ResumeDisplaySchedule ends a manual override early so the schedule takes over again
+func (h *APIHandler) ResumeDisplaySchedule(c *gin.Context) {
+	override, err := h.playlist.Resume(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resume display schedule: " + err.Error()})
+		return
+	}
+	if override == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Display schedule is not paused"})
+		return
+	}
+	h.audit(auditActor(c), models.AuditScheduleResume, "", override, nil)
+	h.broadcastDisplaySchedule(models.ScheduleChangeOverrideCleared)
+	h.applyDisplaySchedule()
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, scheduleStatus(h.playlist.Snapshot(), time.Now()))
+}
+
+// StartDisplaySchedule starts a supervised loop applying the display schedule every interval
+func (h *APIHandler) StartDisplaySchedule(interval time.Duration) {
+	go supervise("display schedule", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			h.applyDisplaySchedule()
+		}
+	})
+}
+
+// applyDisplaySchedule switches the global page to the one the schedule wants now
+func (h *APIHandler) applyDisplaySchedule() {
+	now := time.Now()
+	expired, err := h.playlist.Expire(now)
+	if err != nil {
+		log.Printf("Failed to expire display schedule override: %v", err)
+		return
+	}
+	if expired {
+		h.broadcastDisplaySchedule(models.ScheduleChangeOverrideExpired)
+	}
+
+	data := h.playlist.Snapshot()
+	if !data.Schedule.Enabled || data.Override != nil {
+		return
+	}
+	status := data.Schedule.Status(now)
+	if status.Page == "" {
+		return
+	}
+
+	// Let the advertisement break after a spin finish first
+	if len(h.scheduler.Pending()) > 0 {
+		return
+	}
+
+	switched := false
+	_, after, err := h.state.Update(func(tx *gameTx) error {
+		if tx.Spinning || tx.Config.CurrentPage == status.Page {
+			return nil
+		}
+		showPage(&tx.Config, status.Page)
+		switched = true
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to apply display schedule: %v", err)
+		return
+	}
+	if switched {
+		h.broadcastPageSwitched(after, models.TransitionReasonSchedule, true)
+	}
+}
+
+// pauseDisplaySchedule starts a manual override after an admin switched the page
+func (h *APIHandler) pauseDisplaySchedule(page string, actor models.AuditActor) {
+	override, err := h.playlist.Pause(page, actor, time.Now())
+	if err != nil {
+		log.Printf("Failed to pause display schedule: %v", err)
+		return
+	}
+	if override != nil {
+		h.broadcastDisplaySchedule(models.ScheduleChangeOverrideSet)
+	}
+}
+
+// broadcastDisplaySchedule announces a change to the display schedule or its override
+func (h *APIHandler) broadcastDisplaySchedule(change string) {
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventDisplayScheduleUpdated,
+			Data: models.DisplayScheduleEvent{
+				Change: change,
+				Status: scheduleStatus(h.playlist.Snapshot(), time.Now()),
+			},
+		})
+	}
+}
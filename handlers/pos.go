@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POS Integration API Endpoints
+//
+// The POS posts each bill once it is paid. The bill rules turn its amount into
+// spins, which are added to the player at that table (registering one if the
+// table has none yet). Bill IDs are remembered, so a retried or replayed post
+// never grants the same bill twice.
+
+// defaultBillListLimit is how many bills GET /api/pos/bills returns without ?limit
+const defaultBillListLimit = 100
+
+// PostBill records a POS bill and grants the spins it earns
+func (h *APIHandler) PostBill(c *gin.Context) {
+	var request models.BillRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if err := request.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bill: " + err.Error()})
+		return
+	}
+
+	ledger, err := h.storage.GetBillLedger()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bill rules: " + err.Error()})
+		return
+	}
+
+	actor := auditActor(c)
+	bill := models.Bill{
+		ID:           request.BillID,
+		Table:        request.Table,
+		Amount:       request.Amount,
+		PlayerID:     request.PlayerID,
+		SpinsGranted: ledger.Rules.SpinsFor(request.Amount),
+		Received:     time.Now(),
+		Actor:        &actor,
+	}
+
+	recorded, player, err := h.storage.RecordBill(bill, generateID())
+	if errors.Is(err, storage.ErrDuplicateBill) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Bill was already processed",
+			"duplicate": true,
+			"bill":      recorded,
+		})
+		return
+	}
+	var partial *storage.PartialWriteError
+	if errors.As(err, &partial) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save bill: " + err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to record bill: " + err.Error()})
+		return
+	}
+	h.audit(actor, models.AuditBillPost, recorded.ID, nil, recorded)
+
+	response := gin.H{"bill": recorded, "spins_granted": recorded.SpinsGranted}
+	if player != nil {
+		response["player"] = player
+		response["remaining_spins"] = player.RemainingSpins()
+		h.broadcastPlayerUpdated(*player)
+
+		// Let the counter display announce the grant
+		if h.wsHandler != nil {
+			h.wsHandler.Broadcast(models.WebSocketMessage{
+				Type: models.EventSpinsGranted,
+				Data: models.SpinsGrantedEvent{
+					BillID:         recorded.ID,
+					Table:          recorded.Table,
+					Player:         player.Public(),
+					SpinsGranted:   recorded.SpinsGranted,
+					RemainingSpins: player.RemainingSpins(),
+				},
+			})
+		}
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBills returns the most recent processed bills, newest first, up to ?limit
+func (h *APIHandler) GetBills(c *gin.Context) {
+	limit := defaultBillListLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	ledger, err := h.storage.GetBillLedger()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bills: " + err.Error()})
+		return
+	}
+
+	bills := make([]models.Bill, 0, limit)
+	for i := len(ledger.Bills) - 1; i >= 0 && len(bills) < limit; i-- {
+		bills = append(bills, ledger.Bills[i])
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"bills": bills})
+}
+
+// GetBillRules returns the rules that turn bill amounts into spins, with their ETag
+func (h *APIHandler) GetBillRules(c *gin.Context) {
+	ledger, err := h.storage.GetBillLedger()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bill rules: " + err.Error()})
+		return
+	}
+
+	c.Header("ETag", entityTag(ledger.Rules))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, ledger.Rules)
+}
+
+// UpdateBillRules replaces the bill rules if they still match the If-Match ETag
+func (h *APIHandler) UpdateBillRules(c *gin.Context) {
+	var rules models.BillRules
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	before, err := h.storage.UpdateBillRules(rules, func(current models.BillRules) error {
+		return checkIfMatch(ifMatch, current)
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditBillRulesUpdate, "", before, rules)
+
+	c.Header("ETag", entityTag(rules))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, rules)
+}
@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// QR Code Encoder
+//
+// A small QR code encoder for the spin link images: byte mode, error correction
+// level M, versions 1 to 10 (up to 213 bytes, plenty for a URL). It follows the
+// layout of ISO/IEC 18004 and picks the mask with the lowest penalty.
+
+// qrVersion describes the error correction blocks of one version at level M
+type qrVersion struct {
+	eccPerBlock int
+	blocks      []int // Data codewords in each block
+	alignment   []int // Alignment pattern centres
+}
+
+var qrVersions = []qrVersion{
+	1:  {10, []int{16}, nil},
+	2:  {16, []int{28}, []int{6, 18}},
+	3:  {26, []int{44}, []int{6, 22}},
+	4:  {18, []int{32, 32}, []int{6, 26}},
+	5:  {24, []int{43, 43}, []int{6, 30}},
+	6:  {16, []int{27, 27, 27, 27}, []int{6, 34}},
+	7:  {18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	8:  {22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	9:  {22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	10: {26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+// errQRTooLong reports data beyond the capacity of the largest supported version
+var errQRTooLong = errors.New("data too long for a QR code")
+
+// qrCode is an encoded QR symbol; modules[y][x] is true for dark
+type qrCode struct {
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// encodeQR encodes data in the smallest version that fits it
+func encodeQR(data []byte) (*qrCode, error) {
+	version := 0
+	for v := 1; v < len(qrVersions); v++ {
+		if qrCapacityBits(v) >= qrDataBits(v, len(data)) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, errQRTooLong
+	}
+
+	codewords := qrAddErrorCorrection(version, qrDataCodewords(version, data))
+
+	size := version*4 + 17
+	qr := &qrCode{size: size, modules: make([][]bool, size), isFunction: make([][]bool, size)}
+	for y := range qr.modules {
+		qr.modules[y] = make([]bool, size)
+		qr.isFunction[y] = make([]bool, size)
+	}
+	qr.drawFunctionPatterns(version)
+	qr.drawCodewords(codewords)
+
+	// Keep the mask that makes the symbol easiest to scan
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		qr.applyMask(mask)
+		qr.drawFormatBits(mask)
+		if penalty := qr.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		qr.applyMask(mask) // Masking twice undoes it
+	}
+	qr.applyMask(best)
+	qr.drawFormatBits(best)
+	return qr, nil
+}
+
+// qrCapacityBits returns the number of data bits a version holds
+func qrCapacityBits(version int) int {
+	total := 0
+	for _, n := range qrVersions[version].blocks {
+		total += n
+	}
+	return total * 8
+}
+
+// qrDataBits returns the bits needed to store n bytes in byte mode
+func qrDataBits(version, n int) int {
+	return 4 + qrCountBits(version) + n*8
+}
+
+// qrCountBits returns the width of the byte mode character count
+func qrCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// qrDataCodewords lays out the mode, length and data, then pads to the version's capacity
+func qrDataCodewords(version int, data []byte) []byte {
+	var bits qrBitBuffer
+	bits.append(0x4, 4) // Byte mode
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := qrCapacityBits(version)
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			codewords[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return codewords
+}
+
+// qrAddErrorCorrection splits the data into blocks, adds Reed-Solomon codewords and interleaves them
+func qrAddErrorCorrection(version int, data []byte) []byte {
+	spec := qrVersions[version]
+	divisor := reedSolomonDivisor(spec.eccPerBlock)
+
+	blocks := make([][]byte, len(spec.blocks))
+	eccs := make([][]byte, len(spec.blocks))
+	offset := 0
+	for i, n := range spec.blocks {
+		blocks[i] = data[offset : offset+n]
+		eccs[i] = reedSolomonRemainder(blocks[i], divisor)
+		offset += n
+	}
+
+	result := make([]byte, 0, len(data)+spec.eccPerBlock*len(blocks))
+	longest := spec.blocks[len(spec.blocks)-1]
+	for i := 0; i < longest; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < spec.eccPerBlock; i++ {
+		for _, ecc := range eccs {
+			result = append(result, ecc[i])
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of the given degree, highest term omitted
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords for data
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns and reserves the format and version areas
+func (qr *qrCode) drawFunctionPatterns(version int) {
+	for i := 0; i < qr.size; i++ {
+		qr.setFunction(6, i, i%2 == 0)
+		qr.setFunction(i, 6, i%2 == 0)
+	}
+
+	qr.drawFinder(3, 3)
+	qr.drawFinder(qr.size-4, 3)
+	qr.drawFinder(3, qr.size-4)
+
+	positions := qrVersions[version].alignment
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			// Skip the three that would overlap a finder
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			qr.drawAlignment(x, y)
+		}
+	}
+
+	qr.drawFormatBits(0)
+	qr.drawVersion(version)
+}
+
+// drawFinder draws a finder pattern with its separator, centred on (x, y)
+func (qr *qrCode) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= qr.size || yy < 0 || yy >= qr.size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			qr.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centred on (x, y)
+func (qr *qrCode) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			qr.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format information for level M and a mask
+func (qr *qrCode) drawFormatBits(mask int) {
+	data := 0<<3 | mask // Level M is 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		qr.setFunction(8, i, qrBit(bits, i))
+	}
+	qr.setFunction(8, 7, qrBit(bits, 6))
+	qr.setFunction(8, 8, qrBit(bits, 7))
+	qr.setFunction(7, 8, qrBit(bits, 8))
+	for i := 9; i < 15; i++ {
+		qr.setFunction(14-i, 8, qrBit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		qr.setFunction(qr.size-1-i, 8, qrBit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		qr.setFunction(8, qr.size-15+i, qrBit(bits, i))
+	}
+	qr.setFunction(8, qr.size-8, true) // Always dark
+}
+
+// drawVersion draws both copies of the version information, present from version 7
+func (qr *qrCode) drawVersion(version int) {
+	if version < 7 {
+		return
+	}
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		a, b := qr.size-11+i%3, i/3
+		qr.setFunction(a, b, qrBit(bits, i))
+		qr.setFunction(b, a, qrBit(bits, i))
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order, two columns at a time from the right
+func (qr *qrCode) drawCodewords(codewords []byte) {
+	i := 0
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < qr.size; vert++ {
+			y := vert
+			if upward {
+				y = qr.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !qr.isFunction[y][x] && i < len(codewords)*8 {
+					qr.modules[y][x] = qrBit(int(codewords[i/8]), 7-i%8)
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by a mask pattern
+func (qr *qrCode) applyMask(mask int) {
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !qr.isFunction[y][x] {
+				qr.modules[y][x] = !qr.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the symbol is to scan: long runs, 2×2 blocks,
+// finder-like patterns and an unbalanced dark ratio
+func (qr *qrCode) penalty() int {
+	penalty := 0
+	dark := 0
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			if qr.modules[y][x] {
+				dark++
+			}
+			if x+1 < qr.size && y+1 < qr.size {
+				c := qr.modules[y][x]
+				if qr.modules[y][x+1] == c && qr.modules[y+1][x] == c && qr.modules[y+1][x+1] == c {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	for _, line := range qr.lines() {
+		run := 1
+		for i := 1; i <= len(line); i++ {
+			if i < len(line) && line[i] == line[i-1] {
+				run++
+				continue
+			}
+			if run >= 5 {
+				penalty += 3 + run - 5
+			}
+			run = 1
+		}
+		penalty += 40 * qrFinderLike(line)
+	}
+
+	total := qr.size * qr.size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return penalty + k*10
+}
+
+// lines returns every row and column of the symbol
+func (qr *qrCode) lines() [][]bool {
+	lines := make([][]bool, 0, qr.size*2)
+	for y := 0; y < qr.size; y++ {
+		lines = append(lines, qr.modules[y])
+	}
+	for x := 0; x < qr.size; x++ {
+		column := make([]bool, qr.size)
+		for y := 0; y < qr.size; y++ {
+			column[y] = qr.modules[y][x]
+		}
+		lines = append(lines, column)
+	}
+	return lines
+}
+
+// qrFinderLike counts dark-light-dark×3-light-dark patterns with four light modules on one side
+func qrFinderLike(line []bool) int {
+	pattern := []bool{true, false, true, true, true, false, true}
+	light := func(from, to int) bool {
+		for i := from; i < to; i++ {
+			if i >= 0 && i < len(line) && line[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	count := 0
+	for i := 0; i+len(pattern) <= len(line); i++ {
+		matches := true
+		for j, dark := range pattern {
+			if line[i+j] != dark {
+				matches = false
+				break
+			}
+		}
+		if matches && (light(i-4, i) || light(i+len(pattern), i+len(pattern)+4)) {
+			count++
+		}
+	}
+	return count
+}
+
+// setFunction sets a function module, which data and masks leave alone
+func (qr *qrCode) setFunction(x, y int, dark bool) {
+	qr.modules[y][x] = dark
+	qr.isFunction[y][x] = true
+}
+
+// PNG renders the symbol with scale pixels per module and the standard 4-module quiet zone
+func (qr *qrCode) PNG(scale int) ([]byte, error) {
+	const quietZone = 4
+	width := (qr.size + quietZone*2) * scale
+	img := image.NewPaletted(image.Rect(0, 0, width, width), color.Palette{color.White, color.Black})
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			if !qr.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+quietZone)*scale+dx, (y+quietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// qrBitBuffer collects bits most significant first
+type qrBitBuffer []bool
+
+func (b *qrBitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, qrBit(value, i))
+	}
+}
+
+// qrBit reports whether bit i of x is set
+func qrBit(x, i int) bool {
+	return (x>>i)&1 != 0
+}
+
+// abs returns the absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
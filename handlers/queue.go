@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Customer Queue
+//
+// Registered players wait in a FIFO queue for their turn at the wheel. Advancing
+// selects the player at the front as the current player, whose allowance the
+// next spins use. Once a spin unlocks and the current player has no spins left,
+// the queue advances by itself.
+
+// GetQueue returns who is spinning now and who is waiting, for the big screen
+func (h *APIHandler) GetQueue(c *gin.Context) {
+	queue, err := h.storage.GetQueue()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get queue: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, h.queueStatus(queue))
+}
+
+// EnqueuePlayer adds a registered player to the back of the queue
+func (h *APIHandler) EnqueuePlayer(c *gin.Context) {
+	var request models.QueueRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	player, err := h.storage.GetPlayer(request.PlayerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get player: " + err.Error()})
+		return
+	}
+
+	entry := models.NewQueueEntry(player, time.Now())
+	status, err := h.updateQueue(models.QueueChangeEnqueued, func(queue *models.PlayerQueue) error {
+		if queue.Index(entry.PlayerID) >= 0 {
+			return newAPIError(http.StatusConflict, "Player is already queued")
+		}
+		queue.Entries = append(queue.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditQueueEnqueue, entry.PlayerID, nil, entry)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, status)
+}
+
+// AdvanceQueue selects the player at the front of the queue to spin next
+func (h *APIHandler) AdvanceQueue(c *gin.Context) {
+	entry, before, status, err := h.advanceQueue()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditQueueAdvance, entry.PlayerID,
+		gin.H{"current_player_id": before.CurrentPlayerID}, gin.H{"current_player_id": entry.PlayerID})
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, status)
+}
+
+// RemoveFromQueue takes a player out of the queue
+func (h *APIHandler) RemoveFromQueue(c *gin.Context) {
+	playerID := c.Param("player_id")
+
+	var removed models.QueueEntry
+	status, err := h.updateQueue(models.QueueChangeRemoved, func(queue *models.PlayerQueue) error {
+		i := queue.Index(playerID)
+		if i < 0 {
+			return newAPIError(http.StatusNotFound, "Player is not queued")
+		}
+		removed = queue.Entries[i]
+		queue.Remove(playerID)
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditQueueRemove, playerID, removed, nil)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, status)
+}
+
+// SkipQueuedPlayer moves a player who isn't ready to the back of the queue
+func (h *APIHandler) SkipQueuedPlayer(c *gin.Context) {
+	playerID := c.Param("player_id")
+
+	var before []models.QueueEntry
+	status, err := h.updateQueue(models.QueueChangeSkipped, func(queue *models.PlayerQueue) error {
+		i := queue.Index(playerID)
+		if i < 0 {
+			return newAPIError(http.StatusNotFound, "Player is not queued")
+		}
+		before = append([]models.QueueEntry{}, queue.Entries...)
+		entry := queue.Entries[i]
+		queue.Remove(playerID)
+		queue.Entries = append(queue.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditQueueSkip, playerID, before, status.Entries)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, status)
+}
+
+// ReorderQueue puts the queue in a new order
+func (h *APIHandler) ReorderQueue(c *gin.Context) {
+	var request models.QueueOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	var before []models.QueueEntry
+	status, err := h.updateQueue(models.QueueChangeReordered, func(queue *models.PlayerQueue) error {
+		before = append([]models.QueueEntry{}, queue.Entries...)
+		if err := queue.Reorder(request.PlayerIDs); err != nil {
+			// The queue probably changed since the client read it
+			return newAPIError(http.StatusConflict, "Invalid order: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditQueueReorder, "", before, status.Entries)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, status)
+}
+
+// advanceQueue selects the player at the front of the queue and takes them out of it,
+// returning their entry, the config before and the queue after
+func (h *APIHandler) advanceQueue() (*models.QueueEntry, *models.GameConfig, models.QueueStatus, error) {
+	// Take the entry off the front in one step so two advances can't select the same player
+	var next models.QueueEntry
+	queue, err := h.storage.UpdateQueue(func(queue *models.PlayerQueue) error {
+		if len(queue.Entries) == 0 {
+			return newAPIError(http.StatusConflict, "Queue is empty")
+		}
+		next = queue.Entries[0]
+		queue.Entries = queue.Entries[1:]
+		return nil
+	})
+	if err != nil {
+		return nil, nil, models.QueueStatus{}, err
+	}
+
+	// Selecting goes through the normal config update, which refuses during a spin
+	before, _, err := h.updateConfig(models.ConfigUpdateRequest{CurrentPlayerID: &next.PlayerID}, "")
+	if err != nil {
+		if _, restoreErr := h.storage.UpdateQueue(func(queue *models.PlayerQueue) error {
+			if queue.Index(next.PlayerID) < 0 {
+				queue.Entries = append([]models.QueueEntry{next}, queue.Entries...)
+			}
+			return nil
+		}); restoreErr != nil {
+			log.Printf("Failed to put %s back at the front of the queue: %v", next.PlayerID, restoreErr)
+		}
+		return nil, nil, models.QueueStatus{}, err
+	}
+
+	return &next, before, h.broadcastQueue(models.QueueChangeAdvanced, queue), nil
+}
+
+// autoAdvanceQueue moves to the next queued player once the current one has no spins left
+func (h *APIHandler) autoAdvanceQueue() {
+	if playerID := h.state.Snapshot().Config.CurrentPlayerID; playerID != "" {
+		if player, err := h.storage.GetPlayer(playerID); err == nil && player.RemainingSpins() > 0 {
+			return
+		}
+	}
+
+	queue, err := h.storage.GetQueue()
+	if err != nil || len(queue.Entries) == 0 {
+		return
+	}
+	if _, _, _, err := h.advanceQueue(); err != nil {
+		log.Printf("Failed to advance queue after spin: %v", err)
+	}
+}
+
+// dequeuePlayer drops a deleted player from the queue
+func (h *APIHandler) dequeuePlayer(playerID string) {
+	queue, err := h.storage.GetQueue()
+	if err != nil || queue.Index(playerID) < 0 {
+		return
+	}
+	if _, err := h.updateQueue(models.QueueChangeRemoved, func(queue *models.PlayerQueue) error {
+		queue.Remove(playerID)
+		return nil
+	}); err != nil {
+		log.Printf("Failed to remove deleted player from queue: %v", err)
+	}
+}
+
+// updateQueue applies a change to the queue and broadcasts the result
+func (h *APIHandler) updateQueue(change string, update func(queue *models.PlayerQueue) error) (models.QueueStatus, error) {
+	queue, err := h.storage.UpdateQueue(update)
+	if err != nil {
+		return models.QueueStatus{}, err
+	}
+	return h.broadcastQueue(change, queue), nil
+}
+
+// broadcastQueue announces the queue after a change and returns its status
+func (h *APIHandler) broadcastQueue(change string, queue *models.PlayerQueue) models.QueueStatus {
+	status := h.queueStatus(queue)
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventQueueUpdated,
+			Data: models.QueueUpdatedEvent{Change: change, QueueStatus: status},
+		})
+	}
+	return status
+}
+
+// queueStatus pairs the queue with the player selected to spin now
+func (h *APIHandler) queueStatus(queue *models.PlayerQueue) models.QueueStatus {
+	status := models.QueueStatus{Entries: queue.Entries}
+	if playerID := h.state.Snapshot().Config.CurrentPlayerID; playerID != "" {
+		if player, err := h.storage.GetPlayer(playerID); err == nil {
+			public := player.Public()
+			status.NowSpinning = &public
+		}
+	}
+	return status
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+// newQueueTest returns a handler with players p1 to p<players>, one spin each,
+// the given player selected and the others queued in order
+func newQueueTest(t *testing.T, players int, selected string, queued ...string) *APIHandler {
+	t.Helper()
+	h := newTestAPIHandler(t)
+	now := time.Now()
+	for i := 1; i <= players; i++ {
+		player := models.Player{ID: "p" + strconv.Itoa(i), Table: "A" + strconv.Itoa(i), SpinsGranted: 1, Created: now, Updated: now}
+		if err := h.storage.AddPlayer(player); err != nil {
+			t.Fatalf("AddPlayer: %v", err)
+		}
+	}
+	if _, _, err := h.state.Update(func(tx *gameTx) error { tx.Config.CurrentPlayerID = selected; return nil }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	var entries []models.QueueEntry
+	for _, id := range queued {
+		player, err := h.storage.GetPlayer(id)
+		if err != nil {
+			t.Fatalf("GetPlayer: %v", err)
+		}
+		entries = append(entries, models.NewQueueEntry(player, now))
+	}
+	if _, err := h.storage.UpdateQueue(func(queue *models.PlayerQueue) error {
+		queue.Entries = entries
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateQueue: %v", err)
+	}
+	return h
+}
+
+// queuedIDs lists the queued players in order
+func queuedIDs(t *testing.T, h *APIHandler) []string {
+	t.Helper()
+	queue, err := h.storage.GetQueue()
+	if err != nil {
+		t.Fatalf("GetQueue: %v", err)
+	}
+	ids := []string{}
+	for _, entry := range queue.Entries {
+		ids = append(ids, entry.PlayerID)
+	}
+	return ids
+}
+
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAdvanceQueue(t *testing.T) {
+	tests := []struct {
+		name         string
+		queued       []string
+		spinning     bool
+		wantStatus   int // 0 when the queue advances
+		wantSelected string
+		wantQueued   []string
+	}{
+		{name: "front player is selected", queued: []string{"p2", "p3"}, wantSelected: "p2", wantQueued: []string{"p3"}},
+		{name: "last player is selected", queued: []string{"p2"}, wantSelected: "p2", wantQueued: []string{}},
+		{name: "empty queue", queued: nil, wantStatus: http.StatusConflict, wantSelected: "p1", wantQueued: []string{}},
+		{name: "during a spin the player stays at the front", queued: []string{"p2", "p3"}, spinning: true, wantStatus: http.StatusLocked, wantSelected: "p1", wantQueued: []string{"p2", "p3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newQueueTest(t, 3, "p1", tt.queued...)
+			if tt.spinning {
+				if _, _, err := h.performSpin(models.AuditActor{Username: "admin"}, ""); err != nil {
+					t.Fatalf("performSpin: %v", err)
+				}
+			}
+
+			entry, _, _, err := h.advanceQueue()
+			if tt.wantStatus != 0 {
+				apiErr, ok := err.(*apiError)
+				if !ok || apiErr.Status != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+			} else if err != nil {
+				t.Fatalf("advanceQueue: %v", err)
+			} else if entry.PlayerID != tt.wantSelected {
+				t.Errorf("advanced to %s, want %s", entry.PlayerID, tt.wantSelected)
+			}
+
+			if selected := h.state.Snapshot().Config.CurrentPlayerID; selected != tt.wantSelected {
+				t.Errorf("selected player = %q, want %q", selected, tt.wantSelected)
+			}
+			if got := queuedIDs(t, h); !sameIDs(got, tt.wantQueued) {
+				t.Errorf("queue = %v, want %v", got, tt.wantQueued)
+			}
+		})
+	}
+}
+
+func TestAdvanceQueueRacingSpin(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		h := newQueueTest(t, 2, "p1", "p2")
+
+		// Staff advance the queue just as p1 presses spin
+		var (
+			wg         sync.WaitGroup
+			result     *models.SpinResult
+			spinErr    error
+			advanceErr error
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			result, _, spinErr = h.performSpin(models.AuditActor{Username: "admin"}, "")
+		}()
+		go func() {
+			defer wg.Done()
+			_, _, _, advanceErr = h.advanceQueue()
+		}()
+		wg.Wait()
+
+		if spinErr != nil {
+			t.Fatalf("performSpin: %v", spinErr)
+		}
+		// Either the advance won and the spin charged p2, or the spin won and the advance waited its turn
+		wantCharged, wantSelected, wantQueued := "p2", "p2", []string{}
+		if advanceErr != nil {
+			if apiErr, ok := advanceErr.(*apiError); !ok || apiErr.Status != http.StatusLocked {
+				t.Fatalf("advanceQueue: %v, want status %d", advanceErr, http.StatusLocked)
+			}
+			wantCharged, wantSelected, wantQueued = "p1", "p1", []string{"p2"}
+		}
+
+		if result.PlayerID != wantCharged {
+			t.Errorf("spin charged %q, want %q", result.PlayerID, wantCharged)
+		}
+		for _, id := range []string{"p1", "p2"} {
+			player, err := h.storage.GetPlayer(id)
+			if err != nil {
+				t.Fatalf("GetPlayer: %v", err)
+			}
+			if used := id == wantCharged; (player.SpinsUsed == 1) != used {
+				t.Errorf("%s used %d spins, charged = %v", id, player.SpinsUsed, used)
+			}
+		}
+		if selected := h.state.Snapshot().Config.CurrentPlayerID; selected != wantSelected {
+			t.Errorf("selected player = %q, want %q", selected, wantSelected)
+		}
+		if got := queuedIDs(t, h); !sameIDs(got, wantQueued) {
+			t.Errorf("queue = %v, want %v", got, wantQueued)
+		}
+	}
+}
+
+func TestAdvanceQueueConcurrentAdvancesSelectEachPlayerOnce(t *testing.T) {
+	queued := []string{"p2", "p3", "p4", "p5", "p6"}
+	h := newQueueTest(t, 6, "p1", queued...)
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		selected = map[string]int{}
+	)
+	for range queued {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry, _, _, err := h.advanceQueue()
+			if err != nil {
+				t.Errorf("advanceQueue: %v", err)
+				return
+			}
+			mutex.Lock()
+			selected[entry.PlayerID]++
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, id := range queued {
+		if selected[id] != 1 {
+			t.Errorf("%s selected %d times, want once", id, selected[id])
+		}
+	}
+	if got := queuedIDs(t, h); len(got) != 0 {
+		t.Errorf("queue = %v, want empty", got)
+	}
+}
+
+func TestAutoAdvanceQueue(t *testing.T) {
+	tests := []struct {
+		name         string
+		selected     string
+		usedUp       bool // Whether the selected player has spent their spins
+		wantSelected string
+		wantQueued   []string
+	}{
+		{name: "player with spins left keeps the wheel", selected: "p1", wantSelected: "p1", wantQueued: []string{"p2"}},
+		{name: "player out of spins makes way", selected: "p1", usedUp: true, wantSelected: "p2", wantQueued: []string{}},
+		{name: "nobody selected", selected: "", wantSelected: "p2", wantQueued: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newQueueTest(t, 2, tt.selected, "p2")
+			if tt.usedUp {
+				if _, _, err := h.storage.UpdatePlayer("p1", func(player *models.Player) {
+					player.SpinsUsed = player.SpinsGranted
+				}); err != nil {
+					t.Fatalf("UpdatePlayer: %v", err)
+				}
+			}
+
+			h.autoAdvanceQueue()
+
+			if selected := h.state.Snapshot().Config.CurrentPlayerID; selected != tt.wantSelected {
+				t.Errorf("selected player = %q, want %q", selected, tt.wantSelected)
+			}
+			if got := queuedIDs(t, h); !sameIDs(got, tt.wantQueued) {
+				t.Errorf("queue = %v, want %v", got, tt.wantQueued)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimiter is a set of token buckets keyed by client (IP, API token, ...)
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	lastPrune time.Time
+}
+
+// rateBucket holds the requests a key may still make
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+	window time.Duration
+}
+
+// newRateLimiter creates an empty rate limiter
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]*rateBucket)}
+}
+
+// allow takes one request from the key's bucket, which refills to limit requests per window.
+// When the bucket is empty it returns false and how long until the next request is allowed.
+func (l *rateLimiter) allow(key string, limit int, window time.Duration, now time.Time) (bool, time.Duration) {
+	if limit <= 0 || window <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, wait := l.refillUnsafe(key, limit, window, now)
+	if wait > 0 {
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// wait reports how long until the key may make a request, without taking one; 0 means now
+func (l *rateLimiter) wait(key string, limit int, window time.Duration, now time.Time) time.Duration {
+	if limit <= 0 || window <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, wait := l.refillUnsafe(key, limit, window, now)
+	return wait
+}
+
+// refillUnsafe tops up the key's bucket for the time since its last request and
+// reports how long until it holds a whole request again
+func (l *rateLimiter) refillUnsafe(key string, limit int, window time.Duration, now time.Time) (*rateBucket, time.Duration) {
+	l.pruneUnsafe(now)
+
+	rate := float64(limit) / window.Seconds() // Requests regained per second
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &rateBucket{tokens: float64(limit), last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.window = window
+	bucket.tokens = math.Min(float64(limit), bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return bucket, time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	}
+	return bucket, 0
+}
+
+// pruneUnsafe drops buckets that have been idle long enough to be full again
+func (l *rateLimiter) pruneUnsafe(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) > bucket.window {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimited writes a 429 response with a Retry-After header in whole seconds
+func rateLimited(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Rate limit exceeded, retry in " + strconv.Itoa(seconds) + "s",
+		"retry_after": seconds,
+	})
+}
+
+// RateBudget is how many requests one client may make per window
+type RateBudget struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseRateBudget parses "<requests>/<window>", e.g. "10/1m" or "30/1h"; "0" or "off" disables the limit
+func ParseRateBudget(value string) (RateBudget, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" || value == "off" {
+		return RateBudget{}, nil
+	}
+
+	countText, windowText, found := strings.Cut(value, "/")
+	if !found {
+		return RateBudget{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<window>, e.g. 10/1m", value)
+	}
+	requests, err := strconv.Atoi(countText)
+	if err != nil || requests < 0 {
+		return RateBudget{}, fmt.Errorf("invalid request count in rate limit %q", value)
+	}
+	window, err := time.ParseDuration(windowText)
+	if err != nil || window <= 0 {
+		return RateBudget{}, fmt.Errorf("invalid window in rate limit %q", value)
+	}
+	return RateBudget{Requests: requests, Window: window}, nil
+}
+
+// String formats the budget the way ParseRateBudget reads it
+func (b RateBudget) String() string {
+	if b.Requests <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", b.Requests, b.Window)
+}
+
+// RateLimits applies per-client request budgets to individual routes.
+// Clients are API tokens when one is used, otherwise IP addresses.
+type RateLimits struct {
+	limiter   *rateLimiter
+	allowlist []*net.IPNet // Clients never limited, e.g. the counter keypad
+}
+
+// NewRateLimits creates route rate limits; allowlist entries are IP addresses or CIDR ranges
+func NewRateLimits(allowlist []string) (*RateLimits, error) {
+	limits := &RateLimits{limiter: newRateLimiter()}
+
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry %q: %w", entry, err)
+		}
+		limits.allowlist = append(limits.allowlist, network)
+	}
+
+	return limits, nil
+}
+
+// Limit is middleware enforcing budget on a route; name separates the counters of different routes
+func (l *RateLimits) Limit(name string, budget RateBudget) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if budget.Requests <= 0 || l.allowlisted(c.ClientIP()) {
+			c.Next()
+			return
+		}
+
+		client := "ip:" + c.ClientIP()
+		if tokenID := c.GetString(contextTokenID); tokenID != "" {
+			client = "token:" + tokenID
+		}
+
+		if ok, wait := l.limiter.allow(name+"|"+client, budget.Requests, budget.Window, time.Now()); !ok {
+			rateLimited(c, wait)
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowlisted reports whether an IP is exempt from rate limits
+func (l *RateLimits) allowlisted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range l.allowlist {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// FailureLimit budgets failed attempts, e.g. wrong passwords, per client IP and per account.
+// Successful attempts cost nothing, so only guessing runs out the budget.
+type FailureLimit struct {
+	limits *RateLimits
+	name   string
+	budget RateBudget
+}
+
+// Failures creates a failure budget; name separates its counters from those of routes
+func (l *RateLimits) Failures(name string, budget RateBudget) *FailureLimit {
+	return &FailureLimit{limits: l, name: name, budget: budget}
+}
+
+// Blocked reports whether the client or the account has no failures left and how long until it has.
+// A nil limit never blocks.
+func (f *FailureLimit) Blocked(ip, account string, now time.Time) (bool, time.Duration) {
+	if f == nil || f.budget.Requests <= 0 {
+		return false, 0
+	}
+	var longest time.Duration
+	for _, key := range f.keys(ip, account) {
+		if wait := f.limits.limiter.wait(key, f.budget.Requests, f.budget.Window, now); wait > longest {
+			longest = wait
+		}
+	}
+	return longest > 0, longest
+}
+
+// Fail takes one failure from the client's and the account's budgets
+func (f *FailureLimit) Fail(ip, account string, now time.Time) {
+	if f == nil || f.budget.Requests <= 0 {
+		return
+	}
+	for _, key := range f.keys(ip, account) {
+		f.limits.limiter.allow(key, f.budget.Requests, f.budget.Window, now)
+	}
+}
+
+// keys lists the buckets an attempt counts against. Allowlisted IPs skip the client bucket,
+// but the account bucket still applies so a trusted device can't be used to guess passwords.
+func (f *FailureLimit) keys(ip, account string) []string {
+	keys := []string{f.name + "|account:" + strings.ToLower(strings.TrimSpace(account))}
+	if !f.limits.allowlisted(ip) {
+		keys = append(keys, f.name+"|ip:"+ip)
+	}
+	return keys
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	// Steps run in order against one limiter allowing 3 requests a minute per key
+	steps := []struct {
+		name     string
+		key      string
+		at       time.Duration // Since start
+		want     bool
+		wantWait time.Duration
+	}{
+		{name: "first request", key: "a", at: 0, want: true},
+		{name: "second request", key: "a", at: 0, want: true},
+		{name: "third request", key: "a", at: 0, want: true},
+		{name: "burst used up", key: "a", at: 0, want: false, wantWait: 20 * time.Second},
+		{name: "other key has its own bucket", key: "b", at: 0, want: true},
+		{name: "part way to the next request", key: "a", at: 15 * time.Second, want: false, wantWait: 5 * time.Second},
+		{name: "one request regained", key: "a", at: 20 * time.Second, want: true},
+		{name: "and spent", key: "a", at: 20 * time.Second, want: false, wantWait: 20 * time.Second},
+		{name: "idle key refills to the limit, not beyond", key: "a", at: 10 * time.Minute, want: true},
+		{name: "second after idling", key: "a", at: 10 * time.Minute, want: true},
+		{name: "third after idling", key: "a", at: 10 * time.Minute, want: true},
+		{name: "fourth after idling", key: "a", at: 10 * time.Minute, want: false, wantWait: 20 * time.Second},
+	}
+
+	l := newRateLimiter()
+	for _, step := range steps {
+		got, wait := l.allow(step.key, 3, time.Minute, start.Add(step.at))
+		if got != step.want || (wait-step.wantWait).Abs() > time.Millisecond {
+			t.Fatalf("%s: allow = %v, %v; want %v, %v", step.name, got, wait, step.want, step.wantWait)
+		}
+	}
+
+	if ok, _ := l.allow("a", 0, time.Minute, start); !ok {
+		t.Error("a zero limit refused a request")
+	}
+}
+
+func TestParseRateBudget(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    RateBudget
+		wantErr bool
+	}{
+		{value: "10/1m", want: RateBudget{Requests: 10, Window: time.Minute}},
+		{value: " 30/1h ", want: RateBudget{Requests: 30, Window: time.Hour}},
+		{value: "off", want: RateBudget{}},
+		{value: "0", want: RateBudget{}},
+		{value: "", want: RateBudget{}},
+		{value: "10", wantErr: true},
+		{value: "ten/1m", wantErr: true},
+		{value: "-1/1m", wantErr: true},
+		{value: "10/minute", wantErr: true},
+		{value: "10/0s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRateBudget(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRateBudget(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRateBudget(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+		if !tt.wantErr {
+			if again, err := ParseRateBudget(got.String()); err != nil || again != got {
+				t.Errorf("ParseRateBudget(%q.String()) = %+v, %v; want %+v", tt.value, again, err, got)
+			}
+		}
+	}
+}
+
+func TestRateLimitsLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		ip        string
+		tokenID   string // Set as if AuthenticateAPIToken had run
+		requests  int
+		wantOK    int // Requests answered before the first 429
+	}{
+		{name: "client over budget", ip: "10.0.0.1", requests: 3, wantOK: 2},
+		{name: "allowlisted address", allowlist: []string{"10.0.0.1"}, ip: "10.0.0.1", requests: 3, wantOK: 3},
+		{name: "allowlisted range", allowlist: []string{"10.0.0.0/24"}, ip: "10.0.0.77", requests: 3, wantOK: 3},
+		{name: "outside the allowlisted range", allowlist: []string{"10.0.0.0/24"}, ip: "10.0.1.1", requests: 3, wantOK: 2},
+		{name: "token over budget", ip: "10.0.0.1", tokenID: "t1", requests: 3, wantOK: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := NewRateLimits(tt.allowlist)
+			if err != nil {
+				t.Fatalf("NewRateLimits: %v", err)
+			}
+			r := gin.New()
+			r.POST("/api/spin", func(c *gin.Context) {
+				if tt.tokenID != "" {
+					c.Set(contextTokenID, tt.tokenID)
+				}
+			}, limits.Limit("spin", RateBudget{Requests: 2, Window: time.Hour}), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			answered := 0
+			for i := 0; i < tt.requests; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/api/spin", nil)
+				req.RemoteAddr = tt.ip + ":40000"
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code == http.StatusTooManyRequests {
+					break
+				}
+				answered++
+			}
+			if answered != tt.wantOK {
+				t.Errorf("answered %d requests, want %d", answered, tt.wantOK)
+			}
+
+			// A different address is unaffected, unless it uses the same token
+			req := httptest.NewRequest(http.MethodPost, "/api/spin", nil)
+			req.RemoteAddr = "10.9.9.9:40000"
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if wantLimited := tt.tokenID != ""; (w.Code == http.StatusTooManyRequests) != wantLimited {
+				t.Errorf("other address got %d, want limited %v", w.Code, wantLimited)
+			}
+		})
+	}
+}
+
+func TestNewRateLimitsRejectsInvalidAllowlist(t *testing.T) {
+	for _, entry := range []string{"10.0.0", "10.0.0.0/33", "counter"} {
+		if _, err := NewRateLimits([]string{entry}); err == nil {
+			t.Errorf("NewRateLimits(%q) accepted an invalid entry", entry)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+)
+
+// pageScheduler holds the pending page transitions and fires each one when it is due.
+// Every change is written to storage first, so pending transitions survive a restart;
+// transitions that fell due while the server was down fire as soon as it starts.
+type pageScheduler struct {
+	mutex   sync.Mutex
+	storage *storage.Storage
+	pending []models.PageTransition // Sorted by time
+	timer   *time.Timer
+	apply   func(transition models.PageTransition, pending []models.PageTransition)
+}
+
+// newPageScheduler loads the pending transitions; nothing fires until Start
+func newPageScheduler(store *storage.Storage) (*pageScheduler, error) {
+	pending, err := store.GetPageTransitions()
+	if err != nil {
+		return nil, err
+	}
+	sortTransitions(pending)
+
+	return &pageScheduler{storage: store, pending: pending}, nil
+}
+
+// Start begins firing transitions through apply, which also receives what is still pending
+func (s *pageScheduler) Start(apply func(transition models.PageTransition, pending []models.PageTransition)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.apply = apply
+	s.armUnsafe()
+}
+
+// Pending returns the transitions waiting to fire
+func (s *pageScheduler) Pending() []models.PageTransition {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]models.PageTransition{}, s.pending...)
+}
+
+// Replace cancels every pending transition and schedules the given ones instead
+func (s *pageScheduler) Replace(transitions []models.PageTransition) ([]models.PageTransition, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	next := append([]models.PageTransition{}, transitions...)
+	sortTransitions(next)
+	if err := s.setUnsafe(next); err != nil {
+		return nil, err
+	}
+	return append([]models.PageTransition{}, s.pending...), nil
+}
+
+// Cancel removes one pending transition, reporting whether it was pending
+func (s *pageScheduler) Cancel(id string) ([]models.PageTransition, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	next := make([]models.PageTransition, 0, len(s.pending))
+	for _, transition := range s.pending {
+		if transition.ID != id {
+			next = append(next, transition)
+		}
+	}
+	if len(next) == len(s.pending) {
+		return append([]models.PageTransition{}, s.pending...), false, nil
+	}
+	if err := s.setUnsafe(next); err != nil {
+		return nil, false, err
+	}
+	return append([]models.PageTransition{}, s.pending...), true, nil
+}
+
+// CancelAll removes every pending transition and returns how many there were
+func (s *pageScheduler) CancelAll() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cancelled := len(s.pending)
+	if cancelled == 0 {
+		return 0, nil
+	}
+	if err := s.setUnsafe(nil); err != nil {
+		return 0, err
+	}
+	return cancelled, nil
+}
+
+// fire applies every transition that is due, in order
+func (s *pageScheduler) fire() {
+	s.mutex.Lock()
+	now := time.Now()
+	due := 0
+	for due < len(s.pending) && !s.pending[due].At.After(now) {
+		due++
+	}
+	if due == 0 {
+		s.mutex.Unlock()
+		return
+	}
+	fired := append([]models.PageTransition{}, s.pending[:due]...)
+	rest := append([]models.PageTransition{}, s.pending[due:]...)
+	if err := s.setUnsafe(rest); err != nil {
+		// Apply them anyway; at worst they fire again after a restart
+		log.Printf("Failed to save page transitions: %v", err)
+		s.pending = rest
+		s.armUnsafe()
+	}
+	apply := s.apply
+	pending := append([]models.PageTransition{}, s.pending...)
+	s.mutex.Unlock()
+
+	for _, transition := range fired {
+		apply(transition, pending)
+	}
+}
+
+// setUnsafe persists and installs a new pending list, then re-arms the timer (internal use)
+func (s *pageScheduler) setUnsafe(next []models.PageTransition) error {
+	if err := s.storage.SavePageTransitions(next); err != nil {
+		return err
+	}
+	s.pending = next
+	s.armUnsafe()
+	return nil
+}
+
+// armUnsafe points the timer at the earliest pending transition (internal use)
+func (s *pageScheduler) armUnsafe() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if len(s.pending) == 0 || s.apply == nil {
+		return
+	}
+	s.timer = time.AfterFunc(time.Until(s.pending[0].At), s.fire)
+}
+
+// sortTransitions orders transitions by the time they are due
+func sortTransitions(transitions []models.PageTransition) {
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].At.Before(transitions[j].At)
+	})
+}
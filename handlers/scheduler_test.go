@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+)
+
+func newTestPageScheduler(t *testing.T) (*pageScheduler, *storage.Storage) {
+	t.Helper()
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	s, err := newPageScheduler(store)
+	if err != nil {
+		t.Fatalf("newPageScheduler: %v", err)
+	}
+	return s, store
+}
+
+// transitionIDs lists transition IDs in order
+func transitionIDs(transitions []models.PageTransition) []string {
+	ids := make([]string, 0, len(transitions))
+	for _, transition := range transitions {
+		ids = append(ids, transition.ID)
+	}
+	return ids
+}
+
+func TestPageSchedulerFiresInOrder(t *testing.T) {
+	s, store := newTestPageScheduler(t)
+	now := time.Now()
+	if _, err := s.Replace([]models.PageTransition{
+		{ID: "late", At: now.Add(60 * time.Millisecond)},
+		{ID: "overdue", At: now.Add(-time.Minute)},
+		{ID: "soon", At: now.Add(30 * time.Millisecond)},
+	}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	type firing struct {
+		id      string
+		pending []string
+	}
+	fired := make(chan firing, 3)
+	s.Start(func(transition models.PageTransition, pending []models.PageTransition) {
+		fired <- firing{id: transition.ID, pending: transitionIDs(pending)}
+	})
+
+	want := []firing{
+		{id: "overdue", pending: []string{"soon", "late"}},
+		{id: "soon", pending: []string{"late"}},
+		{id: "late", pending: []string{}},
+	}
+	for _, w := range want {
+		select {
+		case got := <-fired:
+			if got.id != w.id || !sameIDs(got.pending, w.pending) {
+				t.Fatalf("fired %s with %v pending, want %s with %v", got.id, got.pending, w.id, w.pending)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s never fired", w.id)
+		}
+	}
+
+	if saved, err := store.GetPageTransitions(); err != nil || len(saved) != 0 {
+		t.Errorf("saved transitions = %v, %v; want none", transitionIDs(saved), err)
+	}
+}
+
+func TestPageSchedulerCancel(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		wantFound   bool
+		wantPending []string
+	}{
+		{name: "pending transition", id: "a", wantFound: true, wantPending: []string{"b"}},
+		{name: "unknown transition", id: "c", wantFound: false, wantPending: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store := newTestPageScheduler(t)
+			now := time.Now()
+			if _, err := s.Replace([]models.PageTransition{{ID: "a", At: now.Add(time.Hour)}, {ID: "b", At: now.Add(2 * time.Hour)}}); err != nil {
+				t.Fatalf("Replace: %v", err)
+			}
+
+			pending, found, err := s.Cancel(tt.id)
+			if err != nil {
+				t.Fatalf("Cancel: %v", err)
+			}
+			if found != tt.wantFound || !sameIDs(transitionIDs(pending), tt.wantPending) {
+				t.Errorf("Cancel(%q) = %v, %v; want %v, %v", tt.id, transitionIDs(pending), found, tt.wantPending, tt.wantFound)
+			}
+			saved, err := store.GetPageTransitions()
+			if err != nil {
+				t.Fatalf("GetPageTransitions: %v", err)
+			}
+			if !sameIDs(transitionIDs(saved), tt.wantPending) {
+				t.Errorf("saved %v, want %v", transitionIDs(saved), tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestPageSchedulerSurvivesRestart(t *testing.T) {
+	s, store := newTestPageScheduler(t)
+	now := time.Now()
+	if _, err := s.Replace([]models.PageTransition{
+		{ID: "return", At: now.Add(time.Hour)},
+		{ID: "ad", At: now.Add(-time.Second)}, // Fell due while the server was down
+	}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	restarted, err := newPageScheduler(store)
+	if err != nil {
+		t.Fatalf("newPageScheduler: %v", err)
+	}
+	if pending := transitionIDs(restarted.Pending()); !sameIDs(pending, []string{"ad", "return"}) {
+		t.Fatalf("restored %v, want [ad return]", pending)
+	}
+
+	fired := make(chan string, 2)
+	restarted.Start(func(transition models.PageTransition, pending []models.PageTransition) {
+		fired <- transition.ID
+	})
+	select {
+	case id := <-fired:
+		if id != "ad" {
+			t.Errorf("fired %s first, want ad", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("overdue transition never fired after the restart")
+	}
+	if pending := transitionIDs(restarted.Pending()); !sameIDs(pending, []string{"return"}) {
+		t.Errorf("pending %v, want [return]", pending)
+	}
+}
@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Self-Service Spin Links
+//
+// Staff mint a single-use link for a player's spin and print or show its QR code
+// at the table. The customer scans it and spins from their phone at /play/<token>;
+// the wheel turns on the big screen exactly as for a spin from the counter. Tokens
+// are signed with a secret kept in data/spinlinks.json, so links can't be forged
+// or moved to another player.
+
+// CreateSpinLink mints a spin link for a player, or the player most recently registered at a table
+func (h *APIHandler) CreateSpinLink(c *gin.Context) {
+	var request models.SpinLinkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if err := request.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	player, err := h.spinLinkPlayer(request)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if player.RemainingSpins() <= 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Player has no spins remaining"})
+		return
+	}
+
+	minutes := request.ExpiresMinutes
+	if minutes == 0 {
+		minutes = models.DefaultSpinLinkMinutes
+	}
+	now := time.Now()
+	link := models.SpinLink{
+		ID:        generateID(),
+		PlayerID:  player.ID,
+		Table:     player.Table,
+		Created:   now,
+		CreatedBy: auditActor(c),
+		Expires:   now.Add(time.Duration(minutes) * time.Minute),
+	}
+
+	book, err := h.storage.GetSpinLinkBook()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get spin links: " + err.Error()})
+		return
+	}
+	if err := h.storage.AddSpinLink(link); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save spin link: " + err.Error()})
+		return
+	}
+	h.audit(link.CreatedBy, models.AuditSpinLinkCreate, link.ID, nil, link)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, h.spinLinkView(c, book.Secret, link, now))
+}
+
+// GetSpinLinks returns spin links, newest first, optionally filtered by ?status= and ?player_id=
+func (h *APIHandler) GetSpinLinks(c *gin.Context) {
+	book, err := h.storage.GetSpinLinkBook()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get spin links: " + err.Error()})
+		return
+	}
+
+	status := c.Query("status")
+	playerID := c.Query("player_id")
+	now := time.Now()
+	links := make([]models.SpinLinkView, 0)
+	for i := len(book.Links) - 1; i >= 0; i-- {
+		view := h.spinLinkView(c, book.Secret, book.Links[i], now)
+		if status != "" && view.Status != status {
+			continue
+		}
+		if playerID != "" && view.PlayerID != playerID {
+			continue
+		}
+		links = append(links, view)
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// RevokeSpinLink stops an unused spin link from working, e.g. when the customer left
+func (h *APIHandler) RevokeSpinLink(c *gin.Context) {
+	now := time.Now()
+	before, link, err := h.storage.UpdateSpinLink(c.Param("id"), func(link *models.SpinLink) error {
+		if status := link.StatusAt(now); status != models.SpinLinkStatusActive {
+			return newAPIError(http.StatusConflict, "Spin link is already "+status)
+		}
+		link.Revoked = &now
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, spinLinkLookupError(err))
+		return
+	}
+	h.audit(auditActor(c), models.AuditSpinLinkRevoke, link.ID, before, link)
+
+	link.Status = link.StatusAt(now)
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, link)
+}
+
+// GetSpinLinkQR returns a PNG QR code of a spin link's URL, ?scale= pixels per module (default 8)
+func (h *APIHandler) GetSpinLinkQR(c *gin.Context) {
+	scale := 8
+	if value := c.Query("scale"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 32 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "scale must be between 1 and 32"})
+			return
+		}
+		scale = parsed
+	}
+
+	book, err := h.storage.GetSpinLinkBook()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get spin links: " + err.Error()})
+		return
+	}
+	var link *models.SpinLink
+	for i := range book.Links {
+		if book.Links[i].ID == c.Param("id") {
+			link = &book.Links[i]
+			break
+		}
+	}
+	if link == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Spin link not found"})
+		return
+	}
+
+	qr, err := encodeQR([]byte(h.spinLinkURL(c, book.Secret, *link)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode QR code: " + err.Error()})
+		return
+	}
+	image, err := qr.PNG(scale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code: " + err.Error()})
+		return
+	}
+
+	// The image is as good as the link itself
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+// GetPlayLink returns what a customer's phone shows for a spin link: whose spin it is and, once used, the prize
+func (h *APIHandler) GetPlayLink(c *gin.Context) {
+	link, err := h.findSpinLink(c.Param("token"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, h.spinLinkStatus(link, time.Now()))
+}
+
+// PlaySpinLink takes the spin of a spin link from the customer's phone. The link is claimed
+// first so it can only spin once, and given back if the spin fails (e.g. the wheel is busy).
+func (h *APIHandler) PlaySpinLink(c *gin.Context) {
+	link, err := h.findSpinLink(c.Param("token"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	now := time.Now()
+	if _, _, err := h.storage.UpdateSpinLink(link.ID, func(link *models.SpinLink) error {
+		if status := link.StatusAt(now); status != models.SpinLinkStatusActive {
+			return spinLinkStatusError(link, status)
+		}
+		link.Claimed = &now
+		return nil
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, spinLinkLookupError(err))
+		return
+	}
+
+	actor := models.AuditActor{Username: "spin-link:" + link.ID, IP: c.ClientIP()}
+	result, config, err := h.performSpin(actor, link.PlayerID)
+	if err != nil {
+		if _, _, releaseErr := h.storage.UpdateSpinLink(link.ID, func(link *models.SpinLink) error {
+			link.Claimed = nil
+			return nil
+		}); releaseErr != nil {
+			log.Printf("Failed to release spin link %s after a failed spin: %v", link.ID, releaseErr)
+		}
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, used, err := h.storage.UpdateSpinLink(link.ID, func(link *models.SpinLink) error {
+		link.Result = result
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to record the result of spin link %s: %v", link.ID, err)
+		used = *link
+		used.Claimed = &now
+		used.Result = result
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, models.SpinLinkSpinResponse{
+		SpinLinkStatus: h.spinLinkStatus(&used, now),
+		Timeline:       config.SpinTimeline(result.Timestamp),
+		ServerTime:     time.Now(),
+	})
+}
+
+// PlayPage serves the customer's phone page for a spin link
+func (h *APIHandler) PlayPage(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.String(http.StatusOK, playPageHTML)
+}
+
+// SetPublicURL sets the address customers' phones reach the server at, used in spin link
+// URLs (e.g. http://192.168.1.20:8080). Empty uses the address of the request.
+func (h *APIHandler) SetPublicURL(url string) {
+	h.publicURL = strings.TrimRight(url, "/")
+}
+
+// spinLinkPlayer returns the player a spin link request is for
+func (h *APIHandler) spinLinkPlayer(request models.SpinLinkRequest) (*models.Player, error) {
+	if playerID := strings.TrimSpace(request.PlayerID); playerID != "" {
+		player, err := h.storage.GetPlayer(playerID)
+		if err != nil {
+			return nil, newAPIError(http.StatusNotFound, "Failed to get player: "+err.Error())
+		}
+		return player, nil
+	}
+
+	players, err := h.storage.GetPlayers()
+	if err != nil {
+		return nil, err
+	}
+	table := strings.TrimSpace(request.Table)
+	var latest *models.Player
+	for i, player := range players {
+		if player.Table == table && (latest == nil || !player.Created.Before(latest.Created)) {
+			latest = &players[i]
+		}
+	}
+	if latest == nil {
+		return nil, newAPIError(http.StatusNotFound, "No player registered at table "+table)
+	}
+	return latest, nil
+}
+
+// findSpinLink returns the link a token was signed for. Unknown and forged tokens are
+// both 404, so the response doesn't tell which link IDs exist.
+func (h *APIHandler) findSpinLink(token string) (*models.SpinLink, error) {
+	book, err := h.storage.GetSpinLinkBook()
+	if err != nil {
+		return nil, err
+	}
+	id := models.SpinLinkID(token)
+	for i := range book.Links {
+		if book.Links[i].ID == id && book.Links[i].VerifyToken(book.Secret, token) {
+			return &book.Links[i], nil
+		}
+	}
+	return nil, newAPIError(http.StatusNotFound, "Spin link not found")
+}
+
+// spinLinkStatus is the view of a spin link for the customer's phone
+func (h *APIHandler) spinLinkStatus(link *models.SpinLink, now time.Time) models.SpinLinkStatus {
+	status := models.SpinLinkStatus{
+		Status:  link.StatusAt(now),
+		Table:   link.Table,
+		Expires: link.Expires,
+		Result:  link.Result,
+	}
+	if player, err := h.storage.GetPlayer(link.PlayerID); err == nil {
+		status.Nickname = player.Nickname
+	}
+	// The phone that spun holds the winning voucher
+	if link.Result != nil && link.Result.VoucherCode != "" {
+		if voucher, err := h.storage.GetVoucher(link.Result.VoucherCode); err == nil {
+			voucher.Expired = voucher.IsExpired(now)
+			status.Voucher = voucher
+		}
+	}
+	return status
+}
+
+// spinLinkView adds the status, URL and QR image address to a spin link
+func (h *APIHandler) spinLinkView(c *gin.Context, secret string, link models.SpinLink, now time.Time) models.SpinLinkView {
+	link.Status = link.StatusAt(now)
+	return models.SpinLinkView{
+		SpinLink: link,
+		URL:      h.spinLinkURL(c, secret, link),
+		QRURL:    "/api/spin-links/" + link.ID + "/qr.png",
+	}
+}
+
+// spinLinkURL returns the address a customer opens for a spin link
+func (h *APIHandler) spinLinkURL(c *gin.Context, secret string, link models.SpinLink) string {
+	base := h.publicURL
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	return base + "/play/" + link.Token(secret)
+}
+
+// spinLinkStatusError explains why a spin link can no longer be used
+func spinLinkStatusError(link *models.SpinLink, status string) *apiError {
+	switch status {
+	case models.SpinLinkStatusUsed:
+		return &apiError{
+			Status:  http.StatusConflict,
+			Message: "This spin has already been taken",
+			Details: gin.H{"claimed": link.Claimed, "result": link.Result},
+		}
+	case models.SpinLinkStatusExpired:
+		return &apiError{
+			Status:  http.StatusGone,
+			Message: "Spin link has expired",
+			Details: gin.H{"expires": link.Expires},
+		}
+	}
+	return newAPIError(http.StatusGone, "Spin link was cancelled")
+}
+
+// spinLinkLookupError turns an unknown link ID into a 404
+func spinLinkLookupError(err error) error {
+	if errors.Is(err, storage.ErrSpinLinkNotFound) {
+		return newAPIError(http.StatusNotFound, "Spin link not found")
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newSpinLinkTest returns a handler with one player holding spins and an active link for them
+func newSpinLinkTest(t *testing.T, spins int) (*APIHandler, *gin.Engine, models.SpinLink, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	h, err := NewAPIHandler(store)
+	if err != nil {
+		t.Fatalf("NewAPIHandler: %v", err)
+	}
+
+	now := time.Now()
+	if err := store.AddPlayer(models.Player{ID: "p1", Table: "A3", SpinsGranted: spins, Created: now, Updated: now}); err != nil {
+		t.Fatalf("AddPlayer: %v", err)
+	}
+	link := models.SpinLink{ID: "l1", PlayerID: "p1", Table: "A3", Created: now, Expires: now.Add(time.Hour)}
+	if err := store.AddSpinLink(link); err != nil {
+		t.Fatalf("AddSpinLink: %v", err)
+	}
+	book, err := store.GetSpinLinkBook()
+	if err != nil {
+		t.Fatalf("GetSpinLinkBook: %v", err)
+	}
+
+	r := gin.New()
+	r.POST("/api/play/:token/spin", h.PlaySpinLink)
+	return h, r, link, book.Secret
+}
+
+func playSpinLink(r *gin.Engine, token string) int {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/play/"+token+"/spin", nil))
+	return w.Code
+}
+
+func TestPlaySpinLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		spins      int
+		prepare    func(t *testing.T, h *APIHandler, link models.SpinLink)
+		token      func(link models.SpinLink, secret string) string
+		wantStatus int
+		wantLink   string // Link status afterwards
+	}{
+		{
+			name:       "active link spins once",
+			spins:      1,
+			wantStatus: http.StatusOK,
+			wantLink:   models.SpinLinkStatusUsed,
+		},
+		{
+			name:       "forged token",
+			spins:      1,
+			token:      func(link models.SpinLink, secret string) string { return link.Token("not-the-secret") },
+			wantStatus: http.StatusNotFound,
+			wantLink:   models.SpinLinkStatusActive,
+		},
+		{
+			name:  "token for another player",
+			spins: 1,
+			token: func(link models.SpinLink, secret string) string {
+				link.PlayerID = "p2"
+				return link.Token(secret)
+			},
+			wantStatus: http.StatusNotFound,
+			wantLink:   models.SpinLinkStatusActive,
+		},
+		{
+			name:  "expired link",
+			spins: 1,
+			prepare: func(t *testing.T, h *APIHandler, link models.SpinLink) {
+				// Expiry is signed, so an expired link is issued rather than edited
+				link.ID = "l2"
+				link.Expires = time.Now().Add(-time.Minute)
+				if err := h.storage.AddSpinLink(link); err != nil {
+					t.Fatalf("AddSpinLink: %v", err)
+				}
+			},
+			token: func(link models.SpinLink, secret string) string {
+				link.ID = "l2"
+				link.Expires = time.Now().Add(-time.Minute)
+				return link.Token(secret)
+			},
+			wantStatus: http.StatusGone,
+			wantLink:   models.SpinLinkStatusActive,
+		},
+		{
+			name:  "revoked link",
+			spins: 1,
+			prepare: func(t *testing.T, h *APIHandler, link models.SpinLink) {
+				revoked := time.Now()
+				if _, _, err := h.storage.UpdateSpinLink(link.ID, func(link *models.SpinLink) error {
+					link.Revoked = &revoked
+					return nil
+				}); err != nil {
+					t.Fatalf("UpdateSpinLink: %v", err)
+				}
+			},
+			wantStatus: http.StatusGone,
+			wantLink:   models.SpinLinkStatusRevoked,
+		},
+		{
+			name:       "failed spin gives the link back",
+			spins:      0,
+			wantStatus: http.StatusBadRequest,
+			wantLink:   models.SpinLinkStatusActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, r, link, secret := newSpinLinkTest(t, tt.spins)
+			if tt.prepare != nil {
+				tt.prepare(t, h, link)
+			}
+			token := link.Token(secret)
+			if tt.token != nil {
+				token = tt.token(link, secret)
+			}
+
+			if status := playSpinLink(r, token); status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tt.wantStatus)
+			}
+
+			book, err := h.storage.GetSpinLinkBook()
+			if err != nil {
+				t.Fatalf("GetSpinLinkBook: %v", err)
+			}
+			for _, stored := range book.Links {
+				if stored.ID == link.ID {
+					if status := stored.StatusAt(time.Now()); status != tt.wantLink {
+						t.Errorf("link status = %q, want %q", status, tt.wantLink)
+					}
+					if tt.wantLink == models.SpinLinkStatusUsed && stored.Result == nil {
+						t.Error("used link has no result")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlaySpinLinkIsSingleUse(t *testing.T) {
+	// Enough spins that only the link, not the allowance, can stop a second spin
+	h, r, link, secret := newSpinLinkTest(t, 5)
+	token := link.Token(secret)
+
+	if status := playSpinLink(r, token); status != http.StatusOK {
+		t.Fatalf("first spin status = %d, want %d", status, http.StatusOK)
+	}
+
+	// Free the wheel so a second spin could otherwise go ahead
+	if _, _, err := h.state.Update(func(tx *gameTx) error {
+		tx.Spinning = false
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if status := playSpinLink(r, token); status != http.StatusConflict {
+		t.Fatalf("second spin status = %d, want %d", status, http.StatusConflict)
+	}
+	player, err := h.storage.GetPlayer(link.PlayerID)
+	if err != nil {
+		t.Fatalf("GetPlayer: %v", err)
+	}
+	if player.SpinsUsed != 1 {
+		t.Errorf("SpinsUsed = %d, want 1", player.SpinsUsed)
+	}
+}
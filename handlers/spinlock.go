@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Spin Lock Management
+
+// spinLockGrace is how long past its unlock time a spin lock may be held before it is considered stale
+const spinLockGrace = 4 * time.Second
+
+// GetSpinLock returns the current spin lock: who holds it, when it was taken and when it should end
+func (h *APIHandler) GetSpinLock(c *gin.Context) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, h.state.Snapshot().SpinLock())
+}
+
+// ForceUnlockSpin releases the spin lock immediately, whoever holds it
+func (h *APIHandler) ForceUnlockSpin(c *gin.Context) {
+	before, _, released := h.releaseSpinLock(func(tx *gameTx) bool {
+		return tx.Spinning
+	})
+	if !released {
+		c.JSON(http.StatusConflict, gin.H{"error": "Spin lock is not held"})
+		return
+	}
+	h.audit(auditActor(c), models.AuditSpinForceUnlock, "", before.SpinLock(), nil)
+
+	// Broadcast unlock to all clients
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventSpinLockRecovered,
+			Data: models.SpinLockEvent{
+				IsSpinning: false,
+				Recovered:  true,
+				Forced:     true,
+			},
+		})
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"message": "Spin lock released", "lock": models.SpinLock{}})
+}
+
+// StartSpinLockWatchdog resumes a spin lock restored from disk and starts a supervised
+// background check that recovers stale locks every interval
+func (h *APIHandler) StartSpinLockWatchdog(interval time.Duration) {
+	// A lock restored after a restart has no goroutine waiting to clear it
+	if state := h.state.Snapshot(); state.Spinning {
+		log.Printf("Resuming spin lock held by the previous run, unlocking at %s", state.Timeline.Unlock.Format(time.RFC3339))
+		go h.clearSpinLock(state.Timeline)
+	}
+
+	go supervise("spin lock watchdog", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			h.CheckAndRecoverSpinLock()
+		}
+	})
+}
+
+// CheckAndRecoverSpinLock checks for stale spin locks and recovers them
+func (h *APIHandler) CheckAndRecoverSpinLock() {
+	before, _, recovered := h.releaseSpinLock(func(tx *gameTx) bool {
+		// If still locked well after the timeline's unlock, assume something went wrong
+		return tx.Spinning && time.Since(tx.Timeline.Unlock) > spinLockGrace
+	})
+	if !recovered {
+		return
+	}
+	fmt.Printf("Recovering stale spin lock (duration: %v)\n", time.Since(before.Timeline.Start))
+
+	// Broadcast unlock to all clients
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventSpinLockRecovered,
+			Data: models.SpinLockEvent{
+				IsSpinning: false,
+				Recovered:  true,
+			},
+		})
+	}
+}
+
+// clearSpinLock releases the lock taken by a spin when its timeline unlocks, reporting whether it did
+func (h *APIHandler) clearSpinLock(timeline models.SpinTimeline) bool {
+	time.Sleep(time.Until(timeline.Unlock))
+
+	_, _, cleared := h.releaseSpinLock(func(tx *gameTx) bool {
+		// The lock may already have been recovered and taken by a newer spin
+		return tx.Spinning && tx.Timeline.Start.Equal(timeline.Start)
+	})
+
+	// Broadcast lock cleared
+	if cleared && h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventSpinLockCleared,
+			Data: models.SpinLockEvent{
+				IsSpinning: false,
+			},
+		})
+	}
+	return cleared
+}
+
+// releaseSpinLock clears the spin lock if should approves, reporting whether it did
+func (h *APIHandler) releaseSpinLock(should func(tx *gameTx) bool) (before, after gameState, released bool) {
+	before, after, _ = h.state.Update(func(tx *gameTx) error {
+		if should(tx) {
+			tx.Spinning = false
+			tx.Timeline = models.SpinTimeline{}
+			tx.Holder = nil
+			released = true
+		}
+		return nil
+	})
+	return before, after, released
+}
+
+// spinLockedError builds the 423 response returned while a spin is in progress
+func spinLockedError(message string, timeline models.SpinTimeline) *apiError {
+	return &apiError{
+		Status:  http.StatusLocked,
+		Message: message,
+		Details: gin.H{
+			"spinning":   true,
+			"spin_time":  time.Since(timeline.Start).Seconds(),
+			"unlocks_at": timeline.Unlock,
+		},
+	}
+}
+
+// supervise runs a background loop, restarting it if it panics
+func supervise(name string, loop func()) {
+	for {
+		if !runRecovered(name, loop) {
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// runRecovered runs fn and reports whether it panicked
+func runRecovered(name string, fn func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("%s panicked, restarting: %v", name, r)
+			panicked = true
+		}
+	}()
+	fn()
+	return false
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lockSpin takes the spin lock for a spin that started 10 seconds ago and unlocks at unlock
+func lockSpin(t *testing.T, h *APIHandler, unlock time.Time) models.SpinTimeline {
+	t.Helper()
+	timeline := models.SpinTimeline{Start: time.Now().Add(-10 * time.Second), Stop: unlock, Reveal: unlock, Unlock: unlock}
+	if _, _, err := h.state.Update(func(tx *gameTx) error {
+		tx.Spinning = true
+		tx.Timeline = timeline
+		tx.Holder = &models.AuditActor{Username: "staff1"}
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	return timeline
+}
+
+func TestCheckAndRecoverSpinLock(t *testing.T) {
+	tests := []struct {
+		name          string
+		locked        bool
+		unlock        time.Duration // From now
+		wantRecovered bool
+	}{
+		{name: "no lock", wantRecovered: false},
+		{name: "spin still running", locked: true, unlock: 5 * time.Second, wantRecovered: false},
+		{name: "unlock just passed", locked: true, unlock: -time.Second, wantRecovered: false},
+		{name: "unlock passed within the grace period", locked: true, unlock: -spinLockGrace + time.Second, wantRecovered: false},
+		{name: "stale lock", locked: true, unlock: -spinLockGrace - time.Second, wantRecovered: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			if tt.locked {
+				lockSpin(t, h, time.Now().Add(tt.unlock))
+			}
+
+			h.CheckAndRecoverSpinLock()
+
+			wantLocked := tt.locked && !tt.wantRecovered
+			if locked := h.state.Snapshot().Spinning; locked != wantLocked {
+				t.Errorf("locked = %v, want %v", locked, wantLocked)
+			}
+			saved, err := h.storage.GetSpinLock()
+			if err != nil {
+				t.Fatalf("GetSpinLock: %v", err)
+			}
+			if saved.Locked != wantLocked {
+				t.Errorf("saved lock = %v, want %v", saved.Locked, wantLocked)
+			}
+		})
+	}
+}
+
+func TestClearSpinLockLeavesANewerSpin(t *testing.T) {
+	h := newTestAPIHandler(t)
+	old := lockSpin(t, h, time.Now().Add(-time.Minute))
+
+	// The old lock is recovered and a new spin takes the wheel before the old spin's clear runs
+	h.CheckAndRecoverSpinLock()
+	newer := lockSpin(t, h, time.Now().Add(time.Minute))
+	newer.Start = newer.Start.Add(5 * time.Second)
+	if _, _, err := h.state.Update(func(tx *gameTx) error { tx.Timeline = newer; return nil }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if h.clearSpinLock(old) {
+		t.Error("old spin cleared the newer spin's lock")
+	}
+	if state := h.state.Snapshot(); !state.Spinning || !state.Timeline.Start.Equal(newer.Start) {
+		t.Errorf("lock = %v %+v, want the newer spin's", state.Spinning, state.Timeline)
+	}
+}
+
+func TestSpinLockSurvivesRestart(t *testing.T) {
+	h := newTestAPIHandler(t)
+	timeline := lockSpin(t, h, time.Now().Add(time.Minute))
+
+	restarted, err := newGameStateManager(h.storage)
+	if err != nil {
+		t.Fatalf("newGameStateManager: %v", err)
+	}
+	state := restarted.Snapshot()
+	if !state.Spinning || !state.Timeline.Unlock.Equal(timeline.Unlock) || state.Holder == nil || state.Holder.Username != "staff1" {
+		t.Errorf("restored lock = %+v, want the lock held by staff1 until %v", state.SpinLock(), timeline.Unlock)
+	}
+}
+
+func TestForceUnlockSpin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		locked     bool
+		wantStatus int
+	}{
+		{name: "held lock", locked: true, wantStatus: http.StatusOK},
+		{name: "no lock", locked: false, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			if tt.locked {
+				lockSpin(t, h, time.Now().Add(time.Minute))
+			}
+
+			r := gin.New()
+			r.DELETE("/api/spin/lock", h.ForceUnlockSpin)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/spin/lock", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if h.state.Snapshot().Spinning {
+				t.Error("lock still held")
+			}
+		})
+	}
+}
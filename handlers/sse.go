@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	eventBufferSize      = 256              // Events kept for Last-Event-ID resume
+	sseSubscriberBacklog = 64               // Events queued per SSE client before it is dropped
+	sseKeepAliveInterval = 15 * time.Second // Comment lines keep proxies from closing idle streams
+)
+
+// streamEvent is one published event together with its audience
+type streamEvent struct {
+	ID       string
+	Seq      uint64
+	Message  models.WebSocketMessage
+	Topic    string          // Empty for targeted events
+	DeviceID string          // Set when the event is for a single device only
+	Excluded map[string]bool // Devices that must not receive a broadcast
+}
+
+// deliverableTo reports whether a client with the given device and subscriptions receives the event
+func (e *streamEvent) deliverableTo(deviceID string, isSubscribed func(topic string) bool) bool {
+	if e.DeviceID != "" {
+		return deviceID == e.DeviceID
+	}
+	if deviceID != "" && e.Excluded[deviceID] {
+		return false
+	}
+	return isSubscribed(e.Topic)
+}
+
+// sseSubscriber is one open /events stream
+type sseSubscriber struct {
+	deviceID string
+	topics   map[string]bool
+	events   chan streamEvent
+}
+
+// isSubscribed reports whether the stream receives events of a topic
+func (s *sseSubscriber) isSubscribed(topic string) bool {
+	return topic == "" || s.topics[topic]
+}
+
+// eventStream numbers published events, keeps a replay buffer and feeds SSE subscribers
+type eventStream struct {
+	mu          sync.Mutex
+	bootID      string // Distinguishes IDs issued before a restart
+	nextSeq     uint64
+	buffer      []streamEvent
+	subscribers map[*sseSubscriber]bool
+}
+
+// newEventStream creates an empty event stream
+func newEventStream() *eventStream {
+	return &eventStream{
+		bootID:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		nextSeq:     1,
+		buffer:      make([]streamEvent, 0, eventBufferSize),
+		subscribers: make(map[*sseSubscriber]bool),
+	}
+}
+
+// publish assigns the next event ID, buffers the event and hands it to SSE subscribers,
+// returning the numbered event and how many subscribers received it
+func (s *eventStream) publish(event streamEvent) (streamEvent, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.Seq = s.nextSeq
+	event.ID = fmt.Sprintf("%s-%d", s.bootID, event.Seq)
+	s.nextSeq++
+
+	if len(s.buffer) == eventBufferSize {
+		s.buffer = append(s.buffer[:0], s.buffer[1:]...)
+	}
+	s.buffer = append(s.buffer, event)
+
+	delivered := 0
+	for sub := range s.subscribers {
+		if !event.deliverableTo(sub.deviceID, sub.isSubscribed) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+			delivered++
+		default:
+			// Slow consumer: drop it, the client reconnects with Last-Event-ID
+			log.Printf("Dropping slow SSE client")
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+	}
+
+	return event, delivered
+}
+
+// subscribe registers a subscriber and returns the buffered events after lastEventID.
+// resumed is false when lastEventID is set but can no longer be replayed.
+func (s *eventStream) subscribe(sub *sseSubscriber, lastEventID string) (replay []streamEvent, resumed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribers[sub] = true
+
+	if lastEventID == "" {
+		return nil, true
+	}
+
+	bootID, seqText, found := strings.Cut(lastEventID, "-")
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if !found || err != nil || bootID != s.bootID {
+		return nil, false
+	}
+
+	// Events after seq must all still be in the buffer
+	if seq+1 < s.nextSeq && (len(s.buffer) == 0 || s.buffer[0].Seq > seq+1) {
+		return nil, false
+	}
+
+	for _, event := range s.buffer {
+		if event.Seq > seq && event.deliverableTo(sub.deviceID, sub.isSubscribed) {
+			replay = append(replay, event)
+		}
+	}
+	return replay, true
+}
+
+// unsubscribe removes a subscriber
+func (s *eventStream) unsubscribe(sub *sseSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscribers[sub] {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// hasDevice reports whether a device has an open SSE stream
+func (s *eventStream) hasDevice(deviceID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers {
+		if sub.deviceID == deviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleEvents serves the Server-Sent Events fallback for read-only displays.
+// Event names and payloads match the WebSocket messages; ?device= and ?topics= work as on /ws.
+func (h *WebSocketHandler) HandleEvents(c *gin.Context) {
+	sub := &sseSubscriber{
+		deviceID: c.Query("device"),
+		topics:   make(map[string]bool),
+		events:   make(chan streamEvent, sseSubscriberBacklog),
+	}
+
+	topics := models.AllTopics
+	if requested := c.Query("topics"); requested != "" {
+		topics = strings.Split(requested, ",")
+	}
+	for _, topic := range topics {
+		if !models.IsValidTopic(topic) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown topic: " + topic})
+			return
+		}
+		sub.topics[topic] = true
+	}
+
+	// EventSource sends Last-Event-ID on reconnect; polyfills often use a query parameter
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	c.Header("Content-Type", "text/event-stream; charset=utf-8")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	replay, resumed := h.stream.subscribe(sub, lastEventID)
+	defer h.stream.unsubscribe(sub)
+
+	client := &wsClient{deviceID: sub.deviceID}
+	h.deviceSeen(client, PresenceConnected)
+	defer h.deviceSeen(client, PresenceDisconnected)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: 3000\n\n")
+	writeSSE(w, "", models.WebSocketMessage{
+		Type: models.EventConnected,
+		Data: models.ConnectedEvent{
+			Message:           "Connected to spinner wheel server",
+			DeviceID:          sub.deviceID,
+			ProtocolVersion:   models.ProtocolVersion,
+			SupportedVersions: models.SupportedProtocolVersions,
+			ServerTime:        time.Now(),
+		},
+	})
+	if !resumed {
+		writeSSE(w, "", models.WebSocketMessage{
+			Type: models.EventError,
+			Data: models.ErrorEvent{
+				Code:    "resume_unavailable",
+				Message: "Events since " + lastEventID + " are no longer available; reload current state",
+			},
+		})
+	}
+	for _, event := range replay {
+		writeSSE(w, event.ID, event.Message)
+	}
+	w.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, event.ID, event.Message); err != nil {
+				return
+			}
+			w.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprintf(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+			h.deviceSeen(client, PresenceHeartbeat)
+		}
+	}
+}
+
+// writeSSE writes one event in text/event-stream format
+func writeSSE(w io.Writer, id string, message models.WebSocketMessage) error {
+	payload, err := json.Marshal(message.Data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", message.Type, payload)
+	return err
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"strconv"
+	"testing"
+
+	"spinner-wheel/models"
+)
+
+func TestStreamEventDeliverableTo(t *testing.T) {
+	subscribed := func(topics ...string) func(topic string) bool {
+		return (&sseSubscriber{topics: topicSet(topics)}).isSubscribed
+	}
+
+	tests := []struct {
+		name       string
+		event      streamEvent
+		deviceID   string
+		subscribed func(topic string) bool
+		want       bool
+	}{
+		{name: "subscribed topic", event: streamEvent{Topic: models.TopicSpin}, subscribed: subscribed(models.TopicSpin), want: true},
+		{name: "other topic", event: streamEvent{Topic: models.TopicGame}, subscribed: subscribed(models.TopicSpin), want: false},
+		{name: "event without a topic", event: streamEvent{}, subscribed: subscribed(), want: true},
+		{name: "targeted at this device", event: streamEvent{DeviceID: "tv1"}, deviceID: "tv1", subscribed: subscribed(), want: true},
+		{name: "targeted at another device", event: streamEvent{DeviceID: "tv2", Topic: models.TopicSpin}, deviceID: "tv1", subscribed: subscribed(models.TopicSpin), want: false},
+		{name: "targeted event on an anonymous stream", event: streamEvent{DeviceID: "tv1"}, subscribed: subscribed(models.TopicSpin), want: false},
+		{name: "device excluded", event: streamEvent{Topic: models.TopicGame, Excluded: map[string]bool{"tv1": true}}, deviceID: "tv1", subscribed: subscribed(models.TopicGame), want: false},
+		{name: "other device excluded", event: streamEvent{Topic: models.TopicGame, Excluded: map[string]bool{"tv2": true}}, deviceID: "tv1", subscribed: subscribed(models.TopicGame), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.deliverableTo(tt.deviceID, tt.subscribed); got != tt.want {
+				t.Errorf("deliverableTo(%q) = %v, want %v", tt.deviceID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventStreamResume(t *testing.T) {
+	tests := []struct {
+		name        string
+		published   int                              // Events published before reconnecting, alternating game and spin
+		lastEventID func(stream *eventStream) string // Last-Event-ID sent on reconnect
+		wantResumed bool
+		wantSeqs    []uint64 // Spin events replayed
+	}{
+		{
+			name:        "fresh connection",
+			published:   4,
+			lastEventID: func(stream *eventStream) string { return "" },
+			wantResumed: true,
+		},
+		{
+			name:        "missed events are replayed",
+			published:   6,
+			lastEventID: func(stream *eventStream) string { return streamEventID(stream, 2) },
+			wantResumed: true,
+			wantSeqs:    []uint64{4, 6},
+		},
+		{
+			name:        "nothing missed",
+			published:   6,
+			lastEventID: func(stream *eventStream) string { return streamEventID(stream, 6) },
+			wantResumed: true,
+		},
+		{
+			name:        "oldest buffered event is the next one",
+			published:   eventBufferSize + 2,
+			lastEventID: func(stream *eventStream) string { return streamEventID(stream, 2) },
+			wantResumed: true,
+			wantSeqs:    spinSeqs(4, eventBufferSize+2),
+		},
+		{
+			name:        "missed events left the buffer",
+			published:   eventBufferSize + 2,
+			lastEventID: func(stream *eventStream) string { return streamEventID(stream, 1) },
+			wantResumed: false,
+		},
+		{
+			name:        "ID from before a restart",
+			published:   4,
+			lastEventID: func(stream *eventStream) string { return "otherboot-2" },
+			wantResumed: false,
+		},
+		{
+			name:        "malformed ID",
+			published:   4,
+			lastEventID: func(stream *eventStream) string { return "garbage" },
+			wantResumed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := newEventStream()
+			for i := 1; i <= tt.published; i++ {
+				topic := models.TopicGame
+				if i%2 == 0 {
+					topic = models.TopicSpin
+				}
+				stream.publish(streamEvent{Topic: topic})
+			}
+
+			sub := &sseSubscriber{topics: topicSet([]string{models.TopicSpin}), events: make(chan streamEvent, sseSubscriberBacklog)}
+			replay, resumed := stream.subscribe(sub, tt.lastEventID(stream))
+			if resumed != tt.wantResumed {
+				t.Fatalf("resumed = %v, want %v", resumed, tt.wantResumed)
+			}
+			var seqs []uint64
+			for _, event := range replay {
+				seqs = append(seqs, event.Seq)
+			}
+			if len(seqs) != len(tt.wantSeqs) {
+				t.Fatalf("replayed %v, want %v", seqs, tt.wantSeqs)
+			}
+			for i := range seqs {
+				if seqs[i] != tt.wantSeqs[i] {
+					t.Fatalf("replayed %v, want %v", seqs, tt.wantSeqs)
+				}
+			}
+
+			// Live events follow the replay
+			event, delivered := stream.publish(streamEvent{Topic: models.TopicSpin})
+			if delivered != 1 || (<-sub.events).ID != event.ID {
+				t.Errorf("live event %s not delivered after the replay", event.ID)
+			}
+		})
+	}
+}
+
+func TestEventStreamDropsSlowSubscribers(t *testing.T) {
+	stream := newEventStream()
+	slow := &sseSubscriber{topics: topicSet(models.AllTopics), events: make(chan streamEvent, 1)}
+	stream.subscribe(slow, "")
+
+	stream.publish(streamEvent{Topic: models.TopicSpin})
+	if _, delivered := stream.publish(streamEvent{Topic: models.TopicSpin}); delivered != 0 {
+		t.Errorf("delivered to %d subscribers, want the full one dropped", delivered)
+	}
+
+	<-slow.events
+	if _, open := <-slow.events; open {
+		t.Error("dropped subscriber's channel is still open")
+	}
+	stream.unsubscribe(slow) // The handler still unsubscribes when its stream ends
+}
+
+// topicSet turns a topic list into a subscription set
+func topicSet(topics []string) map[string]bool {
+	set := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		set[topic] = true
+	}
+	return set
+}
+
+// streamEventID returns the ID the stream gave its event with sequence number seq
+func streamEventID(stream *eventStream, seq uint64) string {
+	return stream.bootID + "-" + strconv.FormatUint(seq, 10)
+}
+
+// spinSeqs lists the even sequence numbers from first to last, the spin events in TestEventStreamResume
+func spinSeqs(first, last int) []uint64 {
+	var seqs []uint64
+	for seq := first; seq <= last; seq += 2 {
+		seqs = append(seqs, uint64(seq))
+	}
+	return seqs
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API Tokens for external integrations (e.g. the POS system)
+
+// AuthenticateAPIToken is middleware for the /api group accepting "Authorization: Bearer swt_..." tokens.
+// Requests without an API token pass through untouched so admin sessions keep working.
+func (a *AuthHandler) AuthenticateAPIToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := bearerToken(c.Request)
+		if !strings.HasPrefix(raw, models.APITokenPrefix) {
+			c.Next()
+			return
+		}
+
+		token, err := a.storage.UseAPIToken(models.HashToken(raw), c.ClientIP(), time.Now())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":         "Invalid API token: " + err.Error(),
+				"auth_required": true,
+			})
+			return
+		}
+
+		c.Set(contextTokenID, token.ID)
+		c.Set(contextUsername, "token:"+token.Name)
+		c.Set(contextPermissions, token.Permissions())
+
+		// Tokens may only call routes one of their scopes covers
+		if permission, ok := tokenRoutePermission(c.Request.Method + " " + c.FullPath()); ok {
+			if permission == "" {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error":             "API tokens cannot use this endpoint",
+					"permission_denied": true,
+				})
+				return
+			}
+			if !requirePermission(c, permission) {
+				c.Abort()
+				return
+			}
+		}
+
+		limit := token.RateLimit
+		if limit == 0 {
+			limit = models.DefaultTokenRateLimit
+		}
+		if ok, wait := a.tokenLimiter.allow(token.ID, limit, time.Minute, time.Now()); !ok {
+			rateLimited(c, wait)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetTokens lists API tokens without their hashes
+func (a *AuthHandler) GetTokens(c *gin.Context) {
+	tokens, err := a.storage.GetAPITokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get API tokens: " + err.Error()})
+		return
+	}
+
+	for i := range tokens {
+		tokens[i].TokenHash = ""
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// CreateToken issues an API token; the plain token is only returned in this response
+func (a *AuthHandler) CreateToken(c *gin.Context) {
+	var request models.CreateTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token: " + err.Error()})
+		return
+	}
+
+	raw, tokenHash, err := models.NewAPIToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rateLimit := request.RateLimit
+	if rateLimit == 0 {
+		rateLimit = models.DefaultTokenRateLimit
+	}
+
+	token := models.APIToken{
+		ID:        generateID(),
+		Name:      strings.TrimSpace(request.Name),
+		Prefix:    raw[:len(models.APITokenPrefix)+6],
+		TokenHash: tokenHash,
+		Scopes:    request.Scopes,
+		RateLimit: rateLimit,
+		Created:   time.Now(),
+		CreatedBy: c.GetString(contextUsername),
+	}
+	if err := a.storage.AddAPIToken(token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save API token: " + err.Error()})
+		return
+	}
+
+	token.TokenHash = ""
+	a.audit(auditActor(c), models.AuditTokenCreate, token.ID, nil, token)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{
+		"token":   raw,
+		"details": token,
+	})
+}
+
+// RevokeToken permanently disables an API token
+func (a *AuthHandler) RevokeToken(c *gin.Context) {
+	tokenID := c.Param("id")
+	if tokenID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token ID is required"})
+		return
+	}
+
+	token, err := a.storage.RevokeAPIToken(tokenID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to revoke API token: " + err.Error()})
+		return
+	}
+
+	token.TokenHash = ""
+	a.audit(auditActor(c), models.AuditTokenRevoke, token.ID, nil, token)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully", "details": token})
+}
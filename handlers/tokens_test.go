@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTokenTest returns a router behind AuthenticateAPIToken whose routes answer 200, and
+// adds a token with the given scopes and rate limit, returning the raw token
+func newTokenTest(t *testing.T, scopes []string, rateLimit int, revoked bool) (*gin.Engine, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+
+	raw, hash, err := models.NewAPIToken()
+	if err != nil {
+		t.Fatalf("NewAPIToken: %v", err)
+	}
+	token := models.APIToken{ID: "t1", Name: "POS", Prefix: raw[:8], TokenHash: hash, Scopes: scopes, RateLimit: rateLimit, Created: time.Now()}
+	if err := store.AddAPIToken(token); err != nil {
+		t.Fatalf("AddAPIToken: %v", err)
+	}
+	if revoked {
+		if _, err := store.RevokeAPIToken("t1", time.Now()); err != nil {
+			t.Fatalf("RevokeAPIToken: %v", err)
+		}
+	}
+
+	a := NewAuthHandler(store, time.Hour)
+	r := gin.New()
+	r.Use(a.AuthenticateAPIToken())
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"username": c.GetString(contextUsername)}) }
+	r.POST("/api/spin", ok)
+	r.GET("/api/history", ok)
+	r.GET("/api/restaurant", ok)
+	r.GET("/api/tokens", ok)
+	r.GET("/api/spin/lock", ok)
+	return r, raw
+}
+
+func TestAuthenticateAPIToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		revoked    bool
+		route      string // "METHOD /path"
+		bearer     func(raw string) string
+		wantStatus int
+	}{
+		{name: "scope covers the route", scopes: []string{models.ScopeSpin}, route: "POST /api/spin", wantStatus: http.StatusOK},
+		{name: "scope missing", scopes: []string{models.ScopeReadHistory}, route: "POST /api/spin", wantStatus: http.StatusForbidden},
+		{name: "public route needing a scope", scopes: []string{models.ScopeReadHistory}, route: "GET /api/history", wantStatus: http.StatusOK},
+		{name: "public route without its scope", scopes: []string{models.ScopeSpin}, route: "GET /api/history", wantStatus: http.StatusForbidden},
+		{name: "public route", scopes: []string{models.ScopeSpin}, route: "GET /api/restaurant", wantStatus: http.StatusOK},
+		{name: "admin route no scope grants", scopes: []string{models.ScopeSpin, models.ScopeManageMenu}, route: "GET /api/tokens", wantStatus: http.StatusForbidden},
+		{name: "login-only route", scopes: []string{models.ScopeSpin}, route: "GET /api/spin/lock", wantStatus: http.StatusForbidden},
+		{name: "revoked token", scopes: []string{models.ScopeSpin}, revoked: true, route: "POST /api/spin", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "unknown token",
+			scopes:     []string{models.ScopeSpin},
+			route:      "POST /api/spin",
+			bearer:     func(raw string) string { return models.APITokenPrefix + "0000" },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "session bearer passes through",
+			scopes:     []string{models.ScopeSpin},
+			route:      "GET /api/tokens",
+			bearer:     func(raw string) string { return "session-token" },
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, raw := newTokenTest(t, tt.scopes, 0, tt.revoked)
+			bearer := raw
+			if tt.bearer != nil {
+				bearer = tt.bearer(raw)
+			}
+
+			method, path, _ := strings.Cut(tt.route, " ")
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set("Authorization", "Bearer "+bearer)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPITokenRateLimit(t *testing.T) {
+	r, raw := newTokenTest(t, []string{models.ScopeSpin}, 2, false)
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodPost, "/api/spin", nil)
+		req.Header.Set("Authorization", "Bearer "+raw)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, want)
+		}
+		if want == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Error("429 without Retry-After")
+		}
+	}
+}
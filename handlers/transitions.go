@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scheduled Page Transitions
+
+// GetPageTransitions returns the page switches waiting to happen
+func (h *APIHandler) GetPageTransitions(c *gin.Context) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, models.PageTransitionList{Transitions: h.scheduler.Pending()})
+}
+
+// CancelPageTransition cancels one pending page switch
+func (h *APIHandler) CancelPageTransition(c *gin.Context) {
+	transitionID := c.Param("id")
+	if transitionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transition ID is required"})
+		return
+	}
+
+	before := findTransition(h.scheduler.Pending(), transitionID)
+	pending, found, err := h.scheduler.Cancel(transitionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel page transition: " + err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Page transition not found"})
+		return
+	}
+	h.audit(auditActor(c), models.AuditPageTransitionCancel, transitionID, before, nil)
+	h.broadcastPageTransitions(models.TransitionChangeCancelled, pending)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, models.PageTransitionList{Transitions: pending})
+}
+
+// CancelPageTransitions cancels every pending page switch
+func (h *APIHandler) CancelPageTransitions(c *gin.Context) {
+	before := h.scheduler.Pending()
+	cancelled, err := h.scheduler.CancelAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel page transitions: " + err.Error()})
+		return
+	}
+	if cancelled > 0 {
+		h.audit(auditActor(c), models.AuditPageTransitionCancel, "", before, nil)
+		h.broadcastPageTransitions(models.TransitionChangeCancelled, nil)
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"cancelled": cancelled, "transitions": []models.PageTransition{}})
+}
+
+// StartPageScheduler starts firing scheduled page transitions, including any restored from disk
+func (h *APIHandler) StartPageScheduler() {
+	h.scheduler.Start(h.runPageTransition)
+}
+
+// runPageTransition switches the global page for a transition that fell due
+func (h *APIHandler) runPageTransition(transition models.PageTransition, pending []models.PageTransition) {
+	_, after, err := h.state.Update(func(tx *gameTx) error {
+		// Without an explicit page, return to the lottery page of the current mode
+		page := transition.Page
+		if page == "" {
+			page = "lottery1"
+			if tx.Config.Mode == 2 {
+				page = "lottery2"
+			} else if tx.Config.Mode == 3 {
+				page = "lottery3"
+			}
+		}
+
+		showPage(&tx.Config, page)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to run page transition %s: %v", transition.ID, err)
+		return
+	}
+
+	h.broadcastPageSwitched(after, transition.Reason, true)
+	h.broadcastPageTransitions(models.TransitionChangeFired, pending)
+}
+
+// scheduleAutoSwitchAfterSpin replaces any pending transitions with the advertisement
+// break that follows a spin: the advertisement once the wheel unlocks, then the lottery
+// page again after the configured advertisement time
+func (h *APIHandler) scheduleAutoSwitchAfterSpin(timeline models.SpinTimeline) {
+	// Get restaurant configuration to check if auto-switch is enabled
+	restaurantData, err := h.storage.GetRestaurantData()
+	if err != nil || !restaurantData.Config.EnableAutoSwitch {
+		return
+	}
+
+	now := time.Now()
+	id := generateID()
+	transitions := []models.PageTransition{{
+		ID:      id + "-ad",
+		Page:    "advertisement",
+		At:      timeline.Unlock,
+		Reason:  models.TransitionReasonAfterSpin,
+		Created: now,
+	}}
+	if autoSwitchTime := time.Duration(restaurantData.Config.AutoSwitchTime) * time.Second; autoSwitchTime > 0 {
+		transitions = append(transitions, models.PageTransition{
+			ID:      id + "-return",
+			At:      timeline.Unlock.Add(autoSwitchTime),
+			Reason:  models.TransitionReasonAdvertisementFinish,
+			Created: now,
+		})
+	}
+
+	pending, err := h.scheduler.Replace(transitions)
+	if err != nil {
+		log.Printf("Failed to schedule page transitions after spin: %v", err)
+		return
+	}
+	h.broadcastPageTransitions(models.TransitionChangeScheduled, pending)
+}
+
+// supersedePageTransitions drops pending transitions after a manual page switch
+func (h *APIHandler) supersedePageTransitions() {
+	cancelled, err := h.scheduler.CancelAll()
+	if err != nil {
+		log.Printf("Failed to cancel page transitions: %v", err)
+		return
+	}
+	if cancelled > 0 {
+		h.broadcastPageTransitions(models.TransitionChangeSuperseded, nil)
+	}
+}
+
+// broadcastPageTransitions announces the pending transitions after a change
+func (h *APIHandler) broadcastPageTransitions(change string, pending []models.PageTransition) {
+	if pending == nil {
+		pending = make([]models.PageTransition, 0)
+	}
+	if h.wsHandler != nil {
+		h.wsHandler.Broadcast(models.WebSocketMessage{
+			Type: models.EventPageTransitionsUpdated,
+			Data: models.PageTransitionsEvent{
+				Change:      change,
+				Transitions: pending,
+			},
+		})
+	}
+}
+
+// findTransition returns the pending transition with an ID, for audit "before" values
+func findTransition(transitions []models.PageTransition, id string) interface{} {
+	for _, transition := range transitions {
+		if transition.ID == id {
+			return transition
+		}
+	}
+	return nil
+}
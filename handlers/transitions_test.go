@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestScheduleAutoSwitchAfterSpin(t *testing.T) {
+	tests := []struct {
+		name           string
+		enabled        bool
+		autoSwitchTime int
+		want           []models.PageTransition // IDs are not compared
+	}{
+		{name: "auto-switch off", enabled: false, autoSwitchTime: 30},
+		{
+			name:           "advertisement then back to the lottery",
+			enabled:        true,
+			autoSwitchTime: 30,
+			want: []models.PageTransition{
+				{Page: "advertisement", Reason: models.TransitionReasonAfterSpin},
+				{Page: "", Reason: models.TransitionReasonAdvertisementFinish},
+			},
+		},
+		{
+			name:           "advertisement without a return",
+			enabled:        true,
+			autoSwitchTime: 0,
+			want:           []models.PageTransition{{Page: "advertisement", Reason: models.TransitionReasonAfterSpin}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			data, err := h.storage.GetRestaurantData()
+			if err != nil {
+				t.Fatalf("GetRestaurantData: %v", err)
+			}
+			data.Config.EnableAutoSwitch = tt.enabled
+			data.Config.AutoSwitchTime = tt.autoSwitchTime
+			if err := h.storage.SaveRestaurantData(data); err != nil {
+				t.Fatalf("SaveRestaurantData: %v", err)
+			}
+
+			unlock := time.Now().Add(time.Hour)
+			h.scheduleAutoSwitchAfterSpin(models.SpinTimeline{Unlock: unlock})
+
+			pending := h.scheduler.Pending()
+			if len(pending) != len(tt.want) {
+				t.Fatalf("scheduled %+v, want %+v", pending, tt.want)
+			}
+			for i, want := range tt.want {
+				got := pending[i]
+				wantAt := unlock
+				if i > 0 {
+					wantAt = unlock.Add(time.Duration(tt.autoSwitchTime) * time.Second)
+				}
+				if got.Page != want.Page || got.Reason != want.Reason || !got.At.Equal(wantAt) {
+					t.Errorf("transition %d = %+v, want page %q reason %q at %v", i, got, want.Page, want.Reason, wantAt)
+				}
+			}
+		})
+	}
+}
+
+func TestManualSwitchSupersedesTransitions(t *testing.T) {
+	h := newTestAPIHandler(t)
+	if _, err := h.scheduler.Replace([]models.PageTransition{{ID: "return", At: time.Now().Add(time.Hour)}}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	if _, _, err := h.switchPage(models.AuditActor{Username: "admin"}, models.PageSwitchRequest{Page: "advertisement"}, ""); err != nil {
+		t.Fatalf("switchPage: %v", err)
+	}
+	if pending := h.scheduler.Pending(); len(pending) != 0 {
+		t.Errorf("pending after a manual switch = %+v, want none", pending)
+	}
+}
+
+func TestRunPageTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     int
+		page     string
+		wantPage string
+		wantMode int
+	}{
+		{name: "explicit page", mode: 2, page: "advertisement", wantPage: "advertisement", wantMode: 2},
+		{name: "return in mode 1", mode: 1, wantPage: "lottery1", wantMode: 1},
+		{name: "return in mode 2", mode: 2, wantPage: "lottery2", wantMode: 2},
+		{name: "return in mode 3", mode: 3, wantPage: "lottery3", wantMode: 3},
+		{name: "lottery page sets the mode", mode: 1, page: "lottery3", wantPage: "lottery3", wantMode: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			if _, _, err := h.state.Update(func(tx *gameTx) error {
+				tx.Config.Mode = tt.mode
+				tx.Config.CurrentPage = "advertisement"
+				return nil
+			}); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			h.runPageTransition(models.PageTransition{ID: "t1", Page: tt.page, Reason: models.TransitionReasonAdvertisementFinish}, nil)
+
+			config := h.state.Snapshot().Config
+			if config.CurrentPage != tt.wantPage || config.Mode != tt.wantMode {
+				t.Errorf("page %q mode %d, want %q mode %d", config.CurrentPage, config.Mode, tt.wantPage, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestCancelPageTransition(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		wantStatus  int
+		wantPending int
+	}{
+		{name: "pending transition", id: "ad", wantStatus: http.StatusOK, wantPending: 1},
+		{name: "unknown transition", id: "missing", wantStatus: http.StatusNotFound, wantPending: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			now := time.Now()
+			if _, err := h.scheduler.Replace([]models.PageTransition{
+				{ID: "ad", Page: "advertisement", At: now.Add(time.Hour)},
+				{ID: "return", At: now.Add(2 * time.Hour)},
+			}); err != nil {
+				t.Fatalf("Replace: %v", err)
+			}
+
+			r := gin.New()
+			r.DELETE("/api/page-transitions/:id", h.CancelPageTransition)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/page-transitions/"+tt.id, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if pending := h.scheduler.Pending(); len(pending) != tt.wantPending {
+				t.Errorf("%d transitions pending, want %d", len(pending), tt.wantPending)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin Account Management (owner only)
+
+// GetUsers returns all admin accounts without their credentials
+func (a *AuthHandler) GetUsers(c *gin.Context) {
+	users, err := a.storage.ListAdminUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get users: " + err.Error()})
+		return
+	}
+
+	infos := make([]models.AdminUserInfo, 0, len(users))
+	for _, user := range users {
+		infos = append(infos, user.Info())
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"users": infos})
+}
+
+// CreateUser adds an admin account with a role
+func (a *AuthHandler) CreateUser(c *gin.Context) {
+	var request models.CreateUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user: " + err.Error()})
+		return
+	}
+
+	user := models.AdminUser{
+		Username: request.Username,
+		Role:     request.Role,
+		Created:  time.Now(),
+	}
+	if err := user.SetPassword(request.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password: " + err.Error()})
+		return
+	}
+
+	if err := a.storage.AddAdminUser(user); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to create user: " + err.Error()})
+		return
+	}
+	a.audit(auditActor(c), models.AuditUserCreate, user.Username, nil, user.Info())
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, user.Info())
+}
+
+// UpdateUser changes an account's role or resets its password
+func (a *AuthHandler) UpdateUser(c *gin.Context) {
+	username := c.Param("username")
+
+	var request models.UpdateUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user update: " + err.Error()})
+		return
+	}
+
+	user, err := a.storage.GetAdminUser(username)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found: " + err.Error()})
+		return
+	}
+
+	before := user.Info()
+	if request.Role != nil {
+		user.Role = *request.Role
+	}
+	if request.Password != nil {
+		if err := user.SetPassword(*request.Password); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password: " + err.Error()})
+			return
+		}
+	}
+
+	if err := a.storage.UpdateAdminUser(username, *user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update user: " + err.Error()})
+		return
+	}
+
+	// A reset password logs the user out everywhere, except the owner's own session
+	if request.Password != nil {
+		if err := a.storage.DeleteUserSessions(username, c.GetString(contextTokenHash)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end sessions: " + err.Error()})
+			return
+		}
+	}
+
+	after := user.Info()
+	a.audit(auditActor(c), models.AuditUserUpdate, username, before, gin.H{
+		"user":           after,
+		"password_reset": request.Password != nil,
+	})
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, after)
+}
+
+// DeleteUser removes an account and ends its sessions
+func (a *AuthHandler) DeleteUser(c *gin.Context) {
+	username := c.Param("username")
+
+	if username == c.GetString(contextUsername) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete your own account"})
+		return
+	}
+
+	user, err := a.storage.GetAdminUser(username)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found: " + err.Error()})
+		return
+	}
+
+	if err := a.storage.DeleteAdminUser(username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to delete user: " + err.Error()})
+		return
+	}
+	a.audit(auditActor(c), models.AuditUserDelete, username, user.Info(), nil)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"spinner-wheel/models"
+	"spinner-wheel/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Prize Vouchers
+//
+// Every winning spin issues a voucher with a short random code and a QR payload.
+// The winner shows it at the counter, where staff redeem it once before it expires.
+// Codes are only returned to whoever spun and to staff; displays and the public
+// history never see them.
+
+// GetVouchers returns vouchers, newest first, optionally filtered by ?status= and ?player_id=
+func (h *APIHandler) GetVouchers(c *gin.Context) {
+	book, err := h.storage.GetVoucherBook()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get vouchers: " + err.Error()})
+		return
+	}
+
+	status := c.Query("status")
+	playerID := c.Query("player_id")
+	now := time.Now()
+	vouchers := make([]models.Voucher, 0)
+	for i := len(book.Vouchers) - 1; i >= 0; i-- {
+		voucher := book.Vouchers[i]
+		voucher.Expired = voucher.IsExpired(now)
+		if status != "" && voucher.Status != status {
+			continue
+		}
+		if playerID != "" && voucher.PlayerID != playerID {
+			continue
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, gin.H{"vouchers": vouchers})
+}
+
+// GetVoucher looks up a voucher by its code or QR payload, so staff can check it before handing out the prize
+func (h *APIHandler) GetVoucher(c *gin.Context) {
+	voucher, err := h.storage.GetVoucher(models.NormalizeVoucherCode(c.Param("code")))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, voucherLookupError(err))
+		return
+	}
+	voucher.Expired = voucher.IsExpired(time.Now())
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, voucher)
+}
+
+// RedeemVoucher marks a valid voucher as used. Unknown codes are 404, vouchers already
+// redeemed or voided are 409 and expired ones are 410.
+func (h *APIHandler) RedeemVoucher(c *gin.Context) {
+	var request models.VoucherCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	actor := auditActor(c)
+	now := time.Now()
+	before, voucher, err := h.storage.UpdateVoucher(models.NormalizeVoucherCode(request.Code), func(voucher *models.Voucher) error {
+		if err := checkVoucherUsable(voucher, now); err != nil {
+			return err
+		}
+		voucher.Status = models.VoucherStatusRedeemed
+		voucher.Redeemed = &now
+		voucher.RedeemedBy = &actor
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, voucherLookupError(err))
+		return
+	}
+	h.audit(actor, models.AuditVoucherRedeem, voucher.Code, before, voucher)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, voucher)
+}
+
+// VoidVoucher cancels a voucher that has not been redeemed, e.g. one issued by mistake
+func (h *APIHandler) VoidVoucher(c *gin.Context) {
+	var request models.VoucherVoidRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	reason := strings.TrimSpace(request.Reason)
+	if len([]rune(reason)) > models.MaxVoidReasonLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason is too long"})
+		return
+	}
+
+	actor := auditActor(c)
+	now := time.Now()
+	before, voucher, err := h.storage.UpdateVoucher(models.NormalizeVoucherCode(c.Param("code")), func(voucher *models.Voucher) error {
+		if voucher.Status != models.VoucherStatusIssued {
+			return voucherStatusError(voucher)
+		}
+		voucher.Status = models.VoucherStatusVoid
+		voucher.Voided = &now
+		voucher.VoidedBy = &actor
+		voucher.VoidReason = reason
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, voucherLookupError(err))
+		return
+	}
+	h.audit(actor, models.AuditVoucherVoid, voucher.Code, before, voucher)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, voucher)
+}
+
+// GetOutstandingVouchers reports prizes won but not yet collected, per prize
+func (h *APIHandler) GetOutstandingVouchers(c *gin.Context) {
+	book, err := h.storage.GetVoucherBook()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get vouchers: " + err.Error()})
+		return
+	}
+
+	now := time.Now()
+	report := models.OutstandingVoucherReport{
+		ByPrize:  make([]models.PrizeCount, 0),
+		Vouchers: make([]models.Voucher, 0),
+	}
+	counts := make(map[string]int)
+	for _, voucher := range book.Vouchers {
+		if voucher.Status != models.VoucherStatusIssued {
+			continue
+		}
+		if voucher.IsExpired(now) {
+			report.Expired++
+			continue
+		}
+		report.Outstanding++
+		if counts[voucher.Prize] == 0 {
+			report.ByPrize = append(report.ByPrize, models.PrizeCount{Prize: voucher.Prize})
+		}
+		counts[voucher.Prize]++
+		report.Vouchers = append(report.Vouchers, voucher)
+	}
+	for i := range report.ByPrize {
+		report.ByPrize[i].Count = counts[report.ByPrize[i].Prize]
+	}
+	sort.SliceStable(report.ByPrize, func(i, j int) bool {
+		return report.ByPrize[i].Count > report.ByPrize[j].Count
+	})
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, report)
+}
+
+// GetVoucherSettings returns how long new vouchers stay valid
+func (h *APIHandler) GetVoucherSettings(c *gin.Context) {
+	book, err := h.storage.GetVoucherBook()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get voucher settings: " + err.Error()})
+		return
+	}
+
+	c.Header("ETag", entityTag(book.Settings))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, book.Settings)
+}
+
+// UpdateVoucherSettings replaces the voucher settings if they still match the If-Match ETag.
+// Vouchers already issued keep their expiry.
+func (h *APIHandler) UpdateVoucherSettings(c *gin.Context) {
+	var settings models.VoucherSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	before, err := h.storage.UpdateVoucherSettings(settings, func(current models.VoucherSettings) error {
+		return checkIfMatch(ifMatch, current)
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	h.audit(auditActor(c), models.AuditVoucherSettings, "", before, settings)
+
+	c.Header("ETag", entityTag(settings))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, settings)
+}
+
+// issueVoucher creates the voucher for a winning spin result, valid for the configured number of days
+func (h *APIHandler) issueVoucher(result models.SpinResult) (*models.Voucher, error) {
+	book, err := h.storage.GetVoucherBook()
+	if err != nil {
+		return nil, err
+	}
+	code, err := models.NewVoucherCode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Voucher{
+		Code:      code,
+		QRPayload: models.VoucherQRPayload(code),
+		Prize:     result.Prize,
+		Mode:      result.Mode,
+		PlayerID:  result.PlayerID,
+		Table:     result.Table,
+		Status:    models.VoucherStatusIssued,
+		Issued:    result.Timestamp,
+		Expires:   result.Timestamp.AddDate(0, 0, book.Settings.ValidityDays),
+	}, nil
+}
+
+// checkVoucherUsable refuses vouchers that were already used, voided or have expired
+func checkVoucherUsable(voucher *models.Voucher, now time.Time) error {
+	if voucher.Status != models.VoucherStatusIssued {
+		return voucherStatusError(voucher)
+	}
+	if voucher.IsExpired(now) {
+		return &apiError{
+			Status:  http.StatusGone,
+			Message: "Voucher has expired",
+			Details: gin.H{"expires": voucher.Expires},
+		}
+	}
+	return nil
+}
+
+// voucherStatusError explains why a redeemed or voided voucher can't be used, and when that happened
+func voucherStatusError(voucher *models.Voucher) *apiError {
+	if voucher.Status == models.VoucherStatusRedeemed {
+		return &apiError{
+			Status:  http.StatusConflict,
+			Message: "Voucher was already redeemed",
+			Details: gin.H{"redeemed": voucher.Redeemed, "redeemed_by": voucher.RedeemedBy},
+		}
+	}
+	return &apiError{
+		Status:  http.StatusConflict,
+		Message: "Voucher was voided",
+		Details: gin.H{"voided": voucher.Voided, "void_reason": voucher.VoidReason},
+	}
+}
+
+// voucherLookupError turns an unknown code into a 404
+func voucherLookupError(err error) error {
+	if errors.Is(err, storage.ErrVoucherNotFound) {
+		return newAPIError(http.StatusNotFound, "Voucher not found")
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// winVoucher makes a spin that always wins and returns the voucher it issued
+func winVoucher(t *testing.T, h *APIHandler) *models.Voucher {
+	t.Helper()
+	if _, _, err := h.state.Update(func(tx *gameTx) error {
+		tx.Config.Mode = 2
+		tx.Config.Mode2WinRate = 100
+		tx.Config.RemainingSpins = 1
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	result, _, err := h.performSpin(models.AuditActor{Username: "admin"}, "")
+	if err != nil {
+		t.Fatalf("performSpin: %v", err)
+	}
+	if result.VoucherCode == "" {
+		t.Fatal("winning spin issued no voucher")
+	}
+	voucher, err := h.storage.GetVoucher(result.VoucherCode)
+	if err != nil {
+		t.Fatalf("GetVoucher: %v", err)
+	}
+	return voucher
+}
+
+func TestWinningSpinIssuesVoucher(t *testing.T) {
+	h := newTestAPIHandler(t)
+	voucher := winVoucher(t, h)
+
+	book, err := h.storage.GetVoucherBook()
+	if err != nil {
+		t.Fatalf("GetVoucherBook: %v", err)
+	}
+	if voucher.Status != models.VoucherStatusIssued || voucher.QRPayload != models.VoucherQRPayload(voucher.Code) {
+		t.Errorf("voucher = %+v, want issued with its QR payload", voucher)
+	}
+	if want := voucher.Issued.AddDate(0, 0, book.Settings.ValidityDays); !voucher.Expires.Equal(want) {
+		t.Errorf("expires %v, want %v", voucher.Expires, want)
+	}
+	if history, err := h.storage.GetHistory(); err != nil || len(history.Results) != 1 || history.Results[0].VoucherCode != voucher.Code {
+		t.Errorf("history does not record voucher %s: %+v, %v", voucher.Code, history, err)
+	}
+}
+
+func TestRedeemVoucher(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       func(voucher *models.Voucher) string
+		setup      func(voucher *models.Voucher) // Changes the voucher before it is redeemed
+		wantStatus int
+	}{
+		{
+			name:       "issued voucher",
+			code:       func(voucher *models.Voucher) string { return voucher.Code },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "typed without the dash",
+			code: func(voucher *models.Voucher) string {
+				return strings.ToLower(strings.ReplaceAll(voucher.Code, "-", ""))
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "scanned QR payload",
+			code:       func(voucher *models.Voucher) string { return voucher.QRPayload },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "already redeemed",
+			code: func(voucher *models.Voucher) string { return voucher.Code },
+			setup: func(voucher *models.Voucher) {
+				now := time.Now()
+				voucher.Status = models.VoucherStatusRedeemed
+				voucher.Redeemed = &now
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "voided",
+			code: func(voucher *models.Voucher) string { return voucher.Code },
+			setup: func(voucher *models.Voucher) {
+				now := time.Now()
+				voucher.Status = models.VoucherStatusVoid
+				voucher.Voided = &now
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "expired",
+			code:       func(voucher *models.Voucher) string { return voucher.Code },
+			setup:      func(voucher *models.Voucher) { voucher.Expires = time.Now().Add(-time.Minute) },
+			wantStatus: http.StatusGone,
+		},
+		{
+			name:       "unknown code",
+			code:       func(voucher *models.Voucher) string { return "ZZZZ-ZZZZ" },
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAPIHandler(t)
+			voucher := winVoucher(t, h)
+			if tt.setup != nil {
+				if _, _, err := h.storage.UpdateVoucher(voucher.Code, func(v *models.Voucher) error { tt.setup(v); return nil }); err != nil {
+					t.Fatalf("UpdateVoucher: %v", err)
+				}
+			}
+			before, err := h.storage.GetVoucher(voucher.Code)
+			if err != nil {
+				t.Fatalf("GetVoucher: %v", err)
+			}
+
+			r := gin.New()
+			r.POST("/api/vouchers/redeem", func(c *gin.Context) { c.Set(contextUsername, "staff1") }, h.RedeemVoucher)
+			body, _ := json.Marshal(models.VoucherCodeRequest{Code: tt.code(voucher)})
+			req := httptest.NewRequest(http.MethodPost, "/api/vouchers/redeem", strings.NewReader(string(body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			after, err := h.storage.GetVoucher(voucher.Code)
+			if err != nil {
+				t.Fatalf("GetVoucher: %v", err)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if after.Status != before.Status {
+					t.Errorf("refused redemption changed status %s -> %s", before.Status, after.Status)
+				}
+				return
+			}
+			if after.Status != models.VoucherStatusRedeemed || after.Redeemed == nil || after.RedeemedBy == nil || after.RedeemedBy.Username != "staff1" {
+				t.Errorf("voucher = %+v, want redeemed by staff1", after)
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"spinner-wheel/models"
 
@@ -12,26 +15,130 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// wsClient is a single WebSocket connection and the device it belongs to
+type wsClient struct {
+	conn     *websocket.Conn
+	deviceID string        // Empty for anonymous clients (admin pages, browsers)
+	protocol int           // Negotiated protocol version; guarded by writeMu once the client is registered
+	request  *http.Request // Upgrade request; its credentials are checked again for every command
+	writeMu  sync.Mutex    // gorilla/websocket allows only one concurrent writer
+
+	topics   map[string]bool // Subscribed event topics
+	topicsMu sync.RWMutex
+}
+
+// isSubscribed reports whether the client receives events of a topic
+func (c *wsClient) isSubscribed(topic string) bool {
+	if topic == "" {
+		return true
+	}
+	c.topicsMu.RLock()
+	defer c.topicsMu.RUnlock()
+	return c.topics[topic]
+}
+
+// setTopics subscribes or unsubscribes topics and returns the resulting subscription list
+func (c *wsClient) setTopics(topics []string, subscribed bool) []string {
+	c.topicsMu.Lock()
+	defer c.topicsMu.Unlock()
+
+	for _, topic := range topics {
+		if subscribed {
+			c.topics[topic] = true
+		} else {
+			delete(c.topics, topic)
+		}
+	}
+
+	current := make([]string, 0, len(c.topics))
+	for _, topic := range models.AllTopics {
+		if c.topics[topic] {
+			current = append(current, topic)
+		}
+	}
+	return current
+}
+
+// send writes a message to the client, stamping the protocol version for version 2+ clients
+func (c *wsClient) send(message models.WebSocketMessage) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	message.Version = 0
+	if c.protocol >= 2 {
+		message.Version = c.protocol
+	}
+	return c.conn.WriteJSON(message)
+}
+
+// protocolVersion returns the negotiated protocol version
+func (c *wsClient) protocolVersion() int {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.protocol
+}
+
+// sendError reports a rejected client message; version 1 clients never expected replies
+func (c *wsClient) sendError(code string, message string) error {
+	if c.protocolVersion() < 2 {
+		return nil
+	}
+	return c.send(models.WebSocketMessage{
+		Type: models.EventError,
+		Data: models.ErrorEvent{Code: code, Message: message},
+	})
+}
+
 // WebSocketHandler manages WebSocket connections
 type WebSocketHandler struct {
-	clients   map[*websocket.Conn]bool
-	clientsMu sync.RWMutex
-	upgrader  websocket.Upgrader
+	clients      map[*websocket.Conn]*wsClient
+	clientsMu    sync.RWMutex
+	upgrader     websocket.Upgrader
+	stream       *eventStream // Shared with the SSE endpoint
+	onDeviceSeen func(deviceID string, presence string)
+	onCommand    func(actor models.AuditActor, cmd models.CommandMessage) (interface{}, string, error)
+	authorize    func(r *http.Request) (models.AuditActor, bool)
 }
 
+// Device presence states reported to the device seen callback
+const (
+	PresenceConnected    = "connected"
+	PresenceHeartbeat    = "heartbeat"
+	PresenceDisconnected = "disconnected"
+)
+
 // NewWebSocketHandler creates a new WebSocket handler
 func NewWebSocketHandler() *WebSocketHandler {
 	return &WebSocketHandler{
-		clients: make(map[*websocket.Conn]bool),
-		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				// Allow all origins for development
-				return true
-			},
-		},
+		clients: make(map[*websocket.Conn]*wsClient),
+		stream:  newEventStream(),
+		// Without an origin policy the upgrader only accepts same-origin connections
+		upgrader: websocket.Upgrader{},
 	}
 }
 
+// SetOriginPolicy sets which browser origins may open WebSocket connections
+func (h *WebSocketHandler) SetOriginPolicy(policy *OriginPolicy) {
+	h.upgrader.CheckOrigin = policy.CheckOrigin
+}
+
+// SetDeviceSeenHandler sets the callback invoked when a device connects, sends a heartbeat or disconnects
+func (h *WebSocketHandler) SetDeviceSeenHandler(fn func(deviceID string, presence string)) {
+	h.onDeviceSeen = fn
+}
+
+// SetCommandHandler sets the function that executes commands received over the socket
+func (h *WebSocketHandler) SetCommandHandler(fn func(actor models.AuditActor, cmd models.CommandMessage) (interface{}, string, error)) {
+	h.onCommand = fn
+}
+
+// SetCommandAuthorizer sets the check deciding whether a client may send commands and who its
+// commands are attributed to. It runs for every command, so a logout, password change, role
+// change or revoked key takes effect on sockets that are already open.
+func (h *WebSocketHandler) SetCommandAuthorizer(fn func(r *http.Request) (models.AuditActor, bool)) {
+	h.authorize = fn
+}
+
 // HandleWebSocket handles WebSocket connection requests
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
@@ -40,36 +147,82 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
+	// Displays identify themselves with /ws?device=<id>; clients may request a protocol with ?protocol=<n>
+	// and otherwise speak version 1 until they send "hello"
+	client := &wsClient{
+		conn:     conn,
+		deviceID: c.Query("device"),
+		protocol: 1,
+		request:  c.Request,
+		topics:   make(map[string]bool),
+	}
+
+	if requested := c.Query("protocol"); requested != "" {
+		version, _ := strconv.Atoi(requested)
+		client.protocol = models.NegotiateProtocolVersion([]int{version})
+		if client.protocol == 0 {
+			// A client asking for a version understands versioned errors
+			client.protocol = models.ProtocolVersion
+			client.sendError("unsupported_protocol", "Unsupported protocol version: "+requested)
+			conn.Close()
+			return
+		}
+	}
+
+	// Subscribe to every topic unless the client narrows it with ?topics=spin,game
+	initialTopics := models.AllTopics
+	if requested := c.Query("topics"); requested != "" {
+		initialTopics = strings.Split(requested, ",")
+		for _, topic := range initialTopics {
+			if !models.IsValidTopic(topic) {
+				client.sendError("invalid_topic", "Unknown topic: "+topic)
+				conn.Close()
+				return
+			}
+		}
+	}
+	client.setTopics(initialTopics, true)
+
 	// Add client to connections
 	h.clientsMu.Lock()
-	h.clients[conn] = true
+	h.clients[conn] = client
+	total := len(h.clients)
 	h.clientsMu.Unlock()
 
-	log.Printf("WebSocket client connected. Total clients: %d", len(h.clients))
+	log.Printf("WebSocket client connected. Total clients: %d", total)
 
 	// Handle client disconnection
 	defer func() {
 		h.clientsMu.Lock()
 		delete(h.clients, conn)
+		total := len(h.clients)
 		h.clientsMu.Unlock()
 		conn.Close()
-		log.Printf("WebSocket client disconnected. Total clients: %d", len(h.clients))
+		log.Printf("WebSocket client disconnected. Total clients: %d", total)
+		h.deviceSeen(client, PresenceDisconnected)
 	}()
 
 	// Send welcome message
 	welcomeMsg := models.WebSocketMessage{
-		Type: "connected",
-		Data: gin.H{"message": "Connected to spinner wheel server"},
+		Type: models.EventConnected,
+		Data: models.ConnectedEvent{
+			Message:           "Connected to spinner wheel server",
+			DeviceID:          client.deviceID,
+			ProtocolVersion:   client.protocolVersion(),
+			SupportedVersions: models.SupportedProtocolVersions,
+			ServerTime:        time.Now(),
+		},
 	}
-	
-	if err := conn.WriteJSON(welcomeMsg); err != nil {
+
+	if err := client.send(welcomeMsg); err != nil {
 		log.Printf("Error sending welcome message: %v", err)
 		return
 	}
+	h.deviceSeen(client, PresenceConnected)
 
-	// Listen for messages (mainly for ping/pong to keep connection alive)
+	// Listen for client messages
 	for {
-		var msg models.WebSocketMessage
+		var msg models.ClientMessage
 		err := conn.ReadJSON(&msg)
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
@@ -78,43 +231,201 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 			break
 		}
 
-		// Handle ping/pong
-		if msg.Type == "ping" {
-			pongMsg := models.WebSocketMessage{
-				Type: "pong",
-				Data: gin.H{"timestamp": msg.Data},
-			}
-			if err := conn.WriteJSON(pongMsg); err != nil {
-				log.Printf("Error sending pong: %v", err)
+		payload, err := msg.Decode()
+		if err != nil {
+			if err := client.sendError("invalid_message", err.Error()); err != nil {
 				break
 			}
+			continue
+		}
+
+		if err := h.handleClientMessage(client, msg.Type, payload); err != nil {
+			log.Printf("Error replying to %s: %v", msg.Type, err)
+			break
 		}
 	}
 }
 
-// Broadcast sends a message to all connected clients
+// handleClientMessage dispatches a validated client message
+func (h *WebSocketHandler) handleClientMessage(client *wsClient, messageType string, payload interface{}) error {
+	switch messageType {
+	case models.MessagePing:
+		h.deviceSeen(client, PresenceHeartbeat)
+		return client.send(models.WebSocketMessage{
+			Type: models.EventPong,
+			Data: models.PongEvent{Timestamp: payload.(int64)},
+		})
+
+	case models.MessageHello:
+		hello := payload.(models.HelloMessage)
+		version := models.NegotiateProtocolVersion(hello.Versions)
+		if version == 0 {
+			return client.sendError("unsupported_protocol", "No common protocol version")
+		}
+		client.writeMu.Lock()
+		client.protocol = version
+		client.writeMu.Unlock()
+		return client.send(models.WebSocketMessage{
+			Type: models.EventHelloAck,
+			Data: models.HelloAckEvent{ProtocolVersion: version},
+		})
+
+	case models.MessageSubscribe, models.MessageUnsubscribe:
+		subscription := payload.(models.SubscriptionMessage)
+		topics := client.setTopics(subscription.Topics, messageType == models.MessageSubscribe)
+		return client.send(models.WebSocketMessage{
+			Type: models.EventSubscribed,
+			Data: models.SubscribedEvent{Topics: topics},
+		})
+
+	case models.MessageCommand:
+		return h.runCommand(client, payload.(models.CommandMessage))
+	}
+	return nil
+}
+
+// runCommand authorizes and executes a command, replying with a correlated ack or error
+func (h *WebSocketHandler) runCommand(client *wsClient, cmd models.CommandMessage) error {
+	fail := func(status int, message string) error {
+		return client.send(models.WebSocketMessage{
+			Type: models.EventCommandError,
+			Data: models.CommandErrorEvent{
+				ID:      cmd.ID,
+				Command: cmd.Command,
+				Status:  status,
+				Message: message,
+			},
+		})
+	}
+
+	if h.authorize == nil || h.onCommand == nil {
+		return fail(http.StatusUnauthorized, "Client is not authorized to send commands")
+	}
+
+	// The session may have ended or the account changed since the socket was opened
+	actor, ok := h.authorize(client.request)
+	if !ok {
+		return fail(http.StatusUnauthorized, "Client is not authorized to send commands")
+	}
+
+	if err := cmd.Validate(); err != nil {
+		return fail(http.StatusBadRequest, "Invalid command: "+err.Error())
+	}
+
+	result, etag, err := h.onCommand(actor, cmd)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(*apiError); ok {
+			status = apiErr.Status
+		}
+		return fail(status, err.Error())
+	}
+
+	return client.send(models.WebSocketMessage{
+		Type: models.EventCommandAck,
+		Data: models.CommandAckEvent{
+			ID:      cmd.ID,
+			Command: cmd.Command,
+			Result:  result,
+			ETag:    etag,
+		},
+	})
+}
+
+// GetSchema returns the machine-readable JSON Schema of the WebSocket protocol
+func (h *WebSocketHandler) GetSchema(c *gin.Context) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, models.GenerateEventSchema())
+}
+
+// deviceSeen reports device activity to the registered callback
+func (h *WebSocketHandler) deviceSeen(client *wsClient, presence string) {
+	if client.deviceID == "" || h.onDeviceSeen == nil {
+		return
+	}
+	h.onDeviceSeen(client.deviceID, presence)
+}
+
+// Broadcast sends a message to all clients subscribed to its topic
 func (h *WebSocketHandler) Broadcast(message models.WebSocketMessage) {
+	h.BroadcastExcludingDevices(message, nil)
+}
+
+// BroadcastExcludingDevices sends a message to all subscribed clients except the given devices
+func (h *WebSocketHandler) BroadcastExcludingDevices(message models.WebSocketMessage, excluded map[string]bool) {
+	h.publish(streamEvent{
+		Message:  message,
+		Topic:    models.TopicForEvent(message.Type),
+		Excluded: excluded,
+	})
+}
+
+// SendToDevice sends a message to every connection of a single device and returns how many received it.
+// Targeted messages bypass topic subscriptions.
+func (h *WebSocketHandler) SendToDevice(deviceID string, message models.WebSocketMessage) int {
+	return h.publish(streamEvent{
+		Message:  message,
+		DeviceID: deviceID,
+	})
+}
+
+// IsDeviceOnline reports whether a device currently has an open WebSocket or SSE stream
+func (h *WebSocketHandler) IsDeviceOnline(deviceID string) bool {
 	h.clientsMu.RLock()
 	defer h.clientsMu.RUnlock()
 
-	if len(h.clients) == 0 {
-		return
+	for _, client := range h.clients {
+		if client.deviceID == deviceID {
+			return true
+		}
+	}
+	return h.stream.hasDevice(deviceID)
+}
+
+// publish validates an event, records it in the shared stream for SSE clients and
+// delivers it to matching WebSocket clients, returning the number of recipients
+func (h *WebSocketHandler) publish(event streamEvent) int {
+	message := event.Message
+
+	// Refuse to send anything that doesn't match the published schema
+	if err := message.Validate(); err != nil {
+		log.Printf("Dropping invalid WebSocket message: %v", err)
+		return 0
+	}
+
+	event, delivered := h.stream.publish(event)
+
+	h.clientsMu.RLock()
+	recipients := make([]*wsClient, 0, len(h.clients))
+	for _, client := range h.clients {
+		if event.deliverableTo(client.deviceID, client.isSubscribed) {
+			recipients = append(recipients, client)
+		}
+	}
+	h.clientsMu.RUnlock()
+
+	if len(recipients) == 0 {
+		return delivered
 	}
 
 	// Convert message to JSON for logging
 	msgJSON, _ := json.Marshal(message)
-	log.Printf("Broadcasting to %d clients: %s", len(h.clients), string(msgJSON))
+	log.Printf("Broadcasting to %d clients: %s", len(recipients), string(msgJSON))
 
-	// Send to all connected clients
-	for conn := range h.clients {
-		err := conn.WriteJSON(message)
-		if err != nil {
+	// Send to all matching clients
+	for _, client := range recipients {
+		if err := client.send(message); err != nil {
 			log.Printf("Error broadcasting to client: %v", err)
 			// Remove failed connection
-			delete(h.clients, conn)
-			conn.Close()
+			h.clientsMu.Lock()
+			delete(h.clients, client.conn)
+			h.clientsMu.Unlock()
+			client.conn.Close()
+			continue
 		}
+		delivered++
 	}
+	return delivered
 }
 
 // GetClientCount returns the number of connected clients
@@ -136,4 +447,4 @@ func (h *WebSocketHandler) BroadcastToUsers(message models.WebSocketMessage) {
 	// For now, just broadcast to all clients
 	// In the future, we could track which clients are admin vs user
 	h.Broadcast(message)
-}
\ No newline at end of file
+}
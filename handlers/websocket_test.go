@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// dialTestWebSocket opens a socket to h with the given query string and returns the
+// connection and its welcome message
+func dialTestWebSocket(t *testing.T, h *WebSocketHandler, query string) (*websocket.Conn, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/ws", h.HandleWebSocket)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws"+query, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, readTestMessage(t, conn)
+}
+
+// readTestMessage reads the next message from a test socket
+func readTestMessage(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var message map[string]interface{}
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	return message
+}
+
+// sendTestMessage writes a client message to a test socket
+func sendTestMessage(t *testing.T, conn *websocket.Conn, message string) {
+	t.Helper()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+}
+
+func TestWebSocketProtocolNegotiation(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		hello       string  // Sent after the welcome, "" for none
+		wantType    string  // Reply to hello, or the welcome message without one
+		wantVersion float64 // "v" on the reply, 0 when omitted
+		wantThen    float64 // "v" on a ping's pong afterwards
+	}{
+		{name: "legacy client", wantType: "connected", wantVersion: 0, wantThen: 0},
+		{name: "version requested in the URL", query: "?protocol=2", wantType: "connected", wantVersion: 2, wantThen: 2},
+		{name: "hello upgrades the connection", hello: `{"type": "hello", "data": {"versions": [1, 2]}}`, wantType: "hello_ack", wantVersion: 2, wantThen: 2},
+		{name: "hello with an older version", query: "?protocol=2", hello: `{"type": "hello", "data": {"versions": [1]}}`, wantType: "hello_ack", wantVersion: 0, wantThen: 0},
+		{name: "hello without a common version", query: "?protocol=2", hello: `{"type": "hello", "data": {"versions": [7]}}`, wantType: "error", wantVersion: 2, wantThen: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, reply := dialTestWebSocket(t, NewWebSocketHandler(), tt.query)
+			if tt.hello != "" {
+				sendTestMessage(t, conn, tt.hello)
+				reply = readTestMessage(t, conn)
+			}
+			if reply["type"] != tt.wantType {
+				t.Fatalf("reply = %v, want %s", reply, tt.wantType)
+			}
+			if version, _ := reply["v"].(float64); version != tt.wantVersion {
+				t.Errorf("reply v = %v, want %v", version, tt.wantVersion)
+			}
+
+			sendTestMessage(t, conn, `{"type": "ping", "data": 5}`)
+			pong := readTestMessage(t, conn)
+			if version, _ := pong["v"].(float64); pong["type"] != "pong" || version != tt.wantThen {
+				t.Errorf("pong = %v, want v %v", pong, tt.wantThen)
+			}
+		})
+	}
+}
+
+func TestWebSocketUnsupportedProtocol(t *testing.T) {
+	conn, reply := dialTestWebSocket(t, NewWebSocketHandler(), "?protocol=9")
+	data, _ := reply["data"].(map[string]interface{})
+	if reply["type"] != "error" || data["code"] != "unsupported_protocol" {
+		t.Fatalf("reply = %v, want an unsupported_protocol error", reply)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("connection stayed open")
+	}
+}
+
+func TestWebSocketInvalidMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		message   string
+		wantError bool // Whether the client is told, rather than the message being dropped
+	}{
+		{name: "unknown type for a v2 client", query: "?protocol=2", message: `{"type": "shout"}`, wantError: true},
+		{name: "bad payload for a v2 client", query: "?protocol=2", message: `{"type": "ping", "data": "now"}`, wantError: true},
+		{name: "unknown type for a v1 client", message: `{"type": "shout"}`},
+		{name: "bad payload for a v1 client", message: `{"type": "ping", "data": "now"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _ := dialTestWebSocket(t, NewWebSocketHandler(), tt.query)
+			sendTestMessage(t, conn, tt.message)
+			// A ping afterwards shows the connection survived, and whether an error came first
+			sendTestMessage(t, conn, `{"type": "ping", "data": 5}`)
+
+			reply := readTestMessage(t, conn)
+			if tt.wantError {
+				data, _ := reply["data"].(map[string]interface{})
+				if reply["type"] != "error" || data["code"] != "invalid_message" {
+					t.Fatalf("reply = %v, want an invalid_message error", reply)
+				}
+				reply = readTestMessage(t, conn)
+			}
+			if reply["type"] != "pong" {
+				t.Errorf("reply = %v, want pong", reply)
+			}
+		})
+	}
+}
+
+func TestWebSocketTopics(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		subscription  string   // Sent after the welcome, "" for none
+		wantTopics    []string // Topics acknowledged for the subscription
+		wantDelivered []string // Events received out of page_switched (game) and spin_lock_cleared (spin)
+	}{
+		{name: "every topic by default", wantDelivered: []string{"page_switched", "spin_lock_cleared"}},
+		{name: "topics chosen in the URL", query: "?topics=spin", wantDelivered: []string{"spin_lock_cleared"}},
+		{
+			name:          "subscribe adds a topic",
+			query:         "?topics=spin",
+			subscription:  `{"type": "subscribe", "data": {"topics": ["game"]}}`,
+			wantTopics:    []string{"game", "spin"},
+			wantDelivered: []string{"page_switched", "spin_lock_cleared"},
+		},
+		{
+			name:          "unsubscribe drops a topic",
+			subscription:  `{"type": "unsubscribe", "data": {"topics": ["spin", "ads"]}}`,
+			wantTopics:    []string{"game", "restaurant", "presence", "players"},
+			wantDelivered: []string{"page_switched"},
+		},
+		{
+			name:          "unsubscribe from everything",
+			query:         "?topics=game,spin",
+			subscription:  `{"type": "unsubscribe", "data": {"topics": ["game", "spin"]}}`,
+			wantTopics:    []string{},
+			wantDelivered: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewWebSocketHandler()
+			conn, _ := dialTestWebSocket(t, h, tt.query)
+			if tt.subscription != "" {
+				sendTestMessage(t, conn, tt.subscription)
+				reply := readTestMessage(t, conn)
+				data, _ := reply["data"].(map[string]interface{})
+				topics := []string{}
+				for _, topic := range data["topics"].([]interface{}) {
+					topics = append(topics, topic.(string))
+				}
+				if reply["type"] != "subscribed" || !sameIDs(topics, tt.wantTopics) {
+					t.Fatalf("reply = %v, want subscribed to %v", reply, tt.wantTopics)
+				}
+			}
+
+			h.Broadcast(models.WebSocketMessage{Type: models.EventPageSwitched, Data: models.PageSwitchedEvent{Page: "advertisement"}})
+			h.Broadcast(models.WebSocketMessage{Type: models.EventSpinLockCleared, Data: models.SpinLockEvent{}})
+			// The pong marks the end of whatever was delivered
+			sendTestMessage(t, conn, `{"type": "ping", "data": 5}`)
+
+			delivered := []string{}
+			for {
+				message := readTestMessage(t, conn)
+				if message["type"] == "pong" {
+					break
+				}
+				delivered = append(delivered, message["type"].(string))
+			}
+			if !sameIDs(delivered, tt.wantDelivered) {
+				t.Errorf("delivered %v, want %v", delivered, tt.wantDelivered)
+			}
+		})
+	}
+}
+
+func TestWebSocketUnknownTopics(t *testing.T) {
+	t.Run("in the URL", func(t *testing.T) {
+		conn, reply := dialTestWebSocket(t, NewWebSocketHandler(), "?protocol=2&topics=spin,gossip")
+		data, _ := reply["data"].(map[string]interface{})
+		if reply["type"] != "error" || data["code"] != "invalid_topic" {
+			t.Fatalf("reply = %v, want an invalid_topic error", reply)
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, _, err := conn.ReadMessage(); err == nil {
+			t.Error("connection stayed open")
+		}
+	})
+
+	t.Run("in a subscription", func(t *testing.T) {
+		conn, _ := dialTestWebSocket(t, NewWebSocketHandler(), "?protocol=2")
+		sendTestMessage(t, conn, `{"type": "subscribe", "data": {"topics": ["gossip"]}}`)
+		reply := readTestMessage(t, conn)
+		data, _ := reply["data"].(map[string]interface{})
+		if reply["type"] != "error" || data["code"] != "invalid_message" {
+			t.Fatalf("reply = %v, want an invalid_message error", reply)
+		}
+	})
+}
+
+func TestWebSocketCommands(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		command    string
+		wantType   string  // command_ack or command_error
+		wantStatus float64 // Status of a command_error
+	}{
+		{name: "spin", query: "?key=secret", command: `{"id": "c1", "command": "spin"}`, wantType: "command_ack"},
+		{name: "switch page", query: "?key=secret", command: `{"id": "c1", "command": "switch_page", "page": "advertisement"}`, wantType: "command_ack"},
+		{name: "select a player", query: "?key=secret", command: `{"id": "c1", "command": "set_player", "player": 3}`, wantType: "command_ack"},
+		{name: "no key", command: `{"id": "c1", "command": "spin"}`, wantType: "command_error", wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", query: "?key=guess", command: `{"id": "c1", "command": "spin"}`, wantType: "command_error", wantStatus: http.StatusUnauthorized},
+		{name: "missing id", query: "?key=secret", command: `{"id": "", "command": "spin"}`, wantType: "command_error", wantStatus: http.StatusBadRequest},
+		{name: "unknown command", query: "?key=secret", command: `{"id": "c1", "command": "reset"}`, wantType: "command_error", wantStatus: http.StatusBadRequest},
+		{name: "switch page without a page", query: "?key=secret", command: `{"id": "c1", "command": "switch_page"}`, wantType: "command_error", wantStatus: http.StatusBadRequest},
+		{name: "stale if_match", query: "?key=secret", command: `{"id": "c1", "command": "switch_page", "page": "advertisement", "if_match": "\"0-1\""}`, wantType: "command_error", wantStatus: http.StatusPreconditionFailed},
+		{name: "staff can't set venue spins", query: "?key=secret", command: `{"id": "c1", "command": "set_player", "player": 3, "remaining_spins": 99}`, wantType: "command_error", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPIHandler(t)
+			if _, _, err := api.state.Update(func(tx *gameTx) error { tx.Config.RemainingSpins = 1; return nil }); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			h := NewWebSocketHandler()
+			h.SetCommandHandler(api.ExecuteCommand)
+			h.SetCommandAuthorizer(CommandKeyAuthorizer("secret"))
+
+			conn, _ := dialTestWebSocket(t, h, tt.query)
+			sendTestMessage(t, conn, `{"type": "command", "data": `+tt.command+`}`)
+			reply := readTestMessage(t, conn)
+
+			// Replies carry the command's ID so clients can match them up
+			var sent models.CommandMessage
+			if err := json.Unmarshal([]byte(tt.command), &sent); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			data, _ := reply["data"].(map[string]interface{})
+			if reply["type"] != tt.wantType || data["id"] != sent.ID {
+				t.Fatalf("reply = %v, want %s for %q", reply, tt.wantType, sent.ID)
+			}
+			if status, _ := data["status"].(float64); status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestWebSocketCommandsRecheckAuthorization(t *testing.T) {
+	api := newTestAPIHandler(t)
+	authorized := true
+	var mutex sync.Mutex
+	h := NewWebSocketHandler()
+	h.SetCommandHandler(api.ExecuteCommand)
+	h.SetCommandAuthorizer(func(r *http.Request) (models.AuditActor, bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return models.AuditActor{Username: "staff1", Role: models.RoleStaff}, authorized
+	})
+	conn, _ := dialTestWebSocket(t, h, "")
+
+	command := `{"type": "command", "data": {"id": "c1", "command": "switch_page", "page": "advertisement"}}`
+	sendTestMessage(t, conn, command)
+	if reply := readTestMessage(t, conn); reply["type"] != "command_ack" {
+		t.Fatalf("reply = %v, want command_ack", reply)
+	}
+
+	// The session ends while the socket stays open
+	mutex.Lock()
+	authorized = false
+	mutex.Unlock()
+	sendTestMessage(t, conn, command)
+	if reply := readTestMessage(t, conn); reply["type"] != "command_error" {
+		t.Fatalf("reply = %v, want command_error", reply)
+	}
+}
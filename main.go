@@ -1,14 +1,19 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"spinner-wheel/handlers"
+	"spinner-wheel/models"
 	"spinner-wheel/storage"
 
 	"github.com/gin-contrib/cors"
@@ -118,8 +123,32 @@ func createBasicTemplates() {
 func main() {
 	// Parse command line flags
 	port := flag.String("port", "8080", "Port to run the server on")
+	printSchema := flag.Bool("ws-schema", false, "Print the WebSocket message JSON Schema and exit")
+	sessionTTL := flag.Duration("session-ttl", 12*time.Hour, "How long an admin login stays valid")
+	commandKey := flag.String("command-key", os.Getenv("SPINNER_COMMAND_KEY"), "Shared key allowing WebSocket clients (e.g. the counter keypad) to send commands")
+	allowedOrigins := flag.String("allowed-origins", os.Getenv("SPINNER_ALLOWED_ORIGINS"), "Comma-separated browser origins allowed for CORS and WebSocket, e.g. http://192.168.1.20:3000")
+	devMode := flag.Bool("dev", false, "Development mode: allow every origin for CORS and WebSocket")
+	enableTLS := flag.Bool("tls", false, "Serve HTTPS; uses a self-signed certificate in data/tls unless -tls-cert and -tls-key are given")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file (PEM); implies -tls")
+	tlsKey := flag.String("tls-key", "", "TLS private key file (PEM); implies -tls")
+	redirectPort := flag.String("redirect-port", "", "With TLS, also listen for plain HTTP on this port and redirect to HTTPS")
+	spinRate := flag.String("spin-rate-limit", "20/1m", "Spin requests allowed per client (IP or API token), e.g. 20/1m; off to disable")
+	uploadRate := flag.String("upload-rate-limit", "30/1h", "Advertisement uploads allowed per client, e.g. 30/1h; off to disable")
+	loginRate := flag.String("login-rate-limit", "10/15m", "Failed admin logins allowed per client IP and per username, e.g. 10/15m; off to disable")
+	rateAllowlist := flag.String("rate-limit-allowlist", os.Getenv("SPINNER_RATE_LIMIT_ALLOWLIST"), "Comma-separated IPs or CIDRs exempt from rate limits (e.g. the counter keypad)")
+	publicURL := flag.String("public-url", os.Getenv("SPINNER_PUBLIC_URL"), "Address customers' phones reach the server at, used in spin link QR codes, e.g. http://192.168.1.20:8080")
 	flag.Parse()
 
+	if *printSchema {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(models.GenerateEventSchema()); err != nil {
+			log.Fatal("Failed to encode schema:", err)
+		}
+		return
+	}
+
 	// Ensure data directory exists
 	if err := os.MkdirAll("data", 0755); err != nil {
 		log.Fatal("Failed to create data directory:", err)
@@ -137,6 +166,12 @@ func main() {
 	// Initialize Gin router
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
+
+	// Served directly on the LAN: use the connection address, not spoofable X-Forwarded-For headers,
+	// for rate limits and the audit log
+	if err := r.SetTrustedProxies(nil); err != nil {
+		log.Fatal("Failed to configure trusted proxies:", err)
+	}
 	
 	// Load HTML templates early for development mode
 	if _, err := os.Stat("templates"); err == nil {
@@ -144,49 +179,179 @@ func main() {
 		fmt.Println("开发模式: 加载HTML模板")
 	}
 
+	// Origins allowed to call the API and open WebSockets from a browser
+	originPolicy, err := handlers.NewOriginPolicy(strings.Split(*allowedOrigins, ","), *devMode)
+	if err != nil {
+		log.Fatal("Invalid -allowed-origins:", err)
+	}
+
 	// Configure CORS
 	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
+	config.AllowOriginWithContextFunc = func(c *gin.Context, origin string) bool {
+		return originPolicy.Allow(origin, c.Request)
+	}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match", handlers.IdempotencyKeyHeader}
+	config.ExposeHeaders = []string{"ETag", "Idempotent-Replayed", "Retry-After"}
 	r.Use(cors.New(config))
 
+	// Per-client request budgets for endpoints that are easy to abuse
+	spinBudget, err := handlers.ParseRateBudget(*spinRate)
+	if err != nil {
+		log.Fatal("Invalid -spin-rate-limit:", err)
+	}
+	uploadBudget, err := handlers.ParseRateBudget(*uploadRate)
+	if err != nil {
+		log.Fatal("Invalid -upload-rate-limit:", err)
+	}
+	loginBudget, err := handlers.ParseRateBudget(*loginRate)
+	if err != nil {
+		log.Fatal("Invalid -login-rate-limit:", err)
+	}
+	rateLimits, err := handlers.NewRateLimits(strings.Split(*rateAllowlist, ","))
+	if err != nil {
+		log.Fatal("Invalid -rate-limit-allowlist:", err)
+	}
+
 	// Initialize handlers
-	apiHandler := handlers.NewAPIHandler(store)
+	apiHandler, err := handlers.NewAPIHandler(store)
+	if err != nil {
+		log.Fatal("Failed to load game state:", err)
+	}
+	apiHandler.SetPublicURL(*publicURL)
 	wsHandler := handlers.NewWebSocketHandler()
+	authHandler := handlers.NewAuthHandler(store, *sessionTTL)
+	authHandler.SetLoginLimit(rateLimits.Failures("login", loginBudget))
 
 	// API routes
-	api := r.Group("/api")
+	api := r.Group("/api", authHandler.AuthenticateAPIToken())
 	{
-		// Game configuration
+		// Read-only endpoints used by the displays
 		api.GET("/config", apiHandler.GetConfig)
-		api.POST("/config", apiHandler.UpdateConfig)
-		api.POST("/spin", apiHandler.Spin)
 		api.GET("/history", apiHandler.GetHistory)
-		api.POST("/reset", apiHandler.Reset)
-		
+		api.GET("/restaurant", apiHandler.GetRestaurantData)
+		api.GET("/devices", apiHandler.GetDevices)
+		api.GET("/queue", apiHandler.GetQueue)
+		api.GET("/jackpot", apiHandler.GetJackpot)
+
+		// Self-service spins from a customer's phone, authorized by the signed link
+		api.GET("/play/:token", apiHandler.GetPlayLink)
+		api.POST("/play/:token/spin", rateLimits.Limit("spin", spinBudget), apiHandler.PlaySpinLink)
+
+		// Admin login and first-run setup
+		api.GET("/auth/status", authHandler.GetStatus)
+		api.POST("/auth/setup", authHandler.Setup)
+		api.POST("/auth/login", authHandler.Login)
+	}
+
+	// Admin API routes (require a logged-in admin whose role grants the route's permission)
+	admin := api.Group("", authHandler.RequireAdmin(), authHandler.RequireRoutePermissions())
+	{
+		admin.POST("/auth/logout", authHandler.Logout)
+		admin.POST("/auth/password", authHandler.ChangePassword)
+
+		// Game configuration
+		admin.POST("/config", apiHandler.UpdateConfig)
+		admin.POST("/spin", rateLimits.Limit("spin", spinBudget), apiHandler.Spin)
+		admin.POST("/reset", apiHandler.Reset)
+		admin.GET("/spin/lock", apiHandler.GetSpinLock)
+		admin.DELETE("/spin/lock", apiHandler.ForceUnlockSpin)
+		admin.PUT("/jackpot", apiHandler.UpdateJackpot)
+
 		// Page management
-		api.POST("/switch-page", apiHandler.SwitchPage)
-		
+		admin.POST("/switch-page", apiHandler.SwitchPage)
+		admin.GET("/page-transitions", apiHandler.GetPageTransitions)
+		admin.DELETE("/page-transitions", apiHandler.CancelPageTransitions)
+		admin.DELETE("/page-transitions/:id", apiHandler.CancelPageTransition)
+		admin.GET("/display-schedule", apiHandler.GetDisplaySchedule)
+		admin.PUT("/display-schedule", apiHandler.UpdateDisplaySchedule)
+		admin.GET("/display-schedule/status", apiHandler.GetDisplayScheduleStatus)
+		admin.DELETE("/display-schedule/override", apiHandler.ResumeDisplaySchedule)
+
 		// Restaurant data management
-		api.GET("/restaurant", apiHandler.GetRestaurantData)
-		api.POST("/restaurant/config", apiHandler.UpdateRestaurantConfig)
-		
+		admin.POST("/restaurant/config", apiHandler.UpdateRestaurantConfig)
+
 		// Advertisement management
-		api.POST("/advertisements", apiHandler.UploadAdvertisement)
-		api.DELETE("/advertisements/:id", apiHandler.DeleteAdvertisement)
-		
+		admin.POST("/advertisements", rateLimits.Limit("upload", uploadBudget), apiHandler.UploadAdvertisement)
+		admin.DELETE("/advertisements/:id", apiHandler.DeleteAdvertisement)
+
 		// Menu management
-		api.PUT("/menu/:id", apiHandler.UpdateMenuItem)
-		
+		admin.PUT("/menu/:id", apiHandler.UpdateMenuItem)
+
 		// Recommendations management
-		api.POST("/recommendations", apiHandler.AddRecommendation)
-		api.PUT("/recommendations/:id", apiHandler.UpdateRecommendation)
-		api.DELETE("/recommendations/:id", apiHandler.DeleteRecommendation)
+		admin.POST("/recommendations", apiHandler.AddRecommendation)
+		admin.PUT("/recommendations/:id", apiHandler.UpdateRecommendation)
+		admin.DELETE("/recommendations/:id", apiHandler.DeleteRecommendation)
+
+		// Player registry and spin allowances
+		admin.GET("/players", apiHandler.GetPlayers)
+		admin.POST("/players", apiHandler.CreatePlayer)
+		admin.GET("/players/:id", apiHandler.GetPlayer)
+		admin.PUT("/players/:id", apiHandler.UpdatePlayer)
+		admin.DELETE("/players/:id", apiHandler.DeletePlayer)
+
+		// Customer queue
+		admin.POST("/queue", apiHandler.EnqueuePlayer)
+		admin.POST("/queue/next", apiHandler.AdvanceQueue)
+		admin.PUT("/queue/order", apiHandler.ReorderQueue)
+		admin.DELETE("/queue/:player_id", apiHandler.RemoveFromQueue)
+		admin.POST("/queue/:player_id/skip", apiHandler.SkipQueuedPlayer)
+
+		// POS bills granting spins
+		admin.POST("/pos/bills", apiHandler.PostBill)
+		admin.GET("/pos/bills", apiHandler.GetBills)
+		admin.GET("/pos/rules", apiHandler.GetBillRules)
+		admin.PUT("/pos/rules", apiHandler.UpdateBillRules)
+
+		// Self-service spin links
+		admin.POST("/spin-links", apiHandler.CreateSpinLink)
+		admin.GET("/spin-links", apiHandler.GetSpinLinks)
+		admin.DELETE("/spin-links/:id", apiHandler.RevokeSpinLink)
+		admin.GET("/spin-links/:id/qr.png", apiHandler.GetSpinLinkQR)
+
+		// Prize vouchers
+		admin.GET("/vouchers", apiHandler.GetVouchers)
+		admin.GET("/vouchers/outstanding", apiHandler.GetOutstandingVouchers)
+		admin.GET("/vouchers/settings", apiHandler.GetVoucherSettings)
+		admin.PUT("/vouchers/settings", apiHandler.UpdateVoucherSettings)
+		admin.POST("/vouchers/redeem", apiHandler.RedeemVoucher)
+		admin.GET("/vouchers/:code", apiHandler.GetVoucher)
+		admin.POST("/vouchers/:code/void", apiHandler.VoidVoucher)
+
+		// Display device management
+		admin.POST("/devices", apiHandler.RegisterDevice)
+		admin.PUT("/devices/:id", apiHandler.UpdateDevice)
+		admin.DELETE("/devices/:id", apiHandler.DeleteDevice)
+		admin.POST("/devices/:id/command", apiHandler.SendDeviceCommand)
+
+		// API tokens for external integrations
+		admin.GET("/tokens", authHandler.GetTokens)
+		admin.POST("/tokens", authHandler.CreateToken)
+		admin.DELETE("/tokens/:id", authHandler.RevokeToken)
+
+		// Tamper-evident audit log
+		admin.GET("/audit", apiHandler.GetAuditLog)
+		admin.GET("/audit/verify", apiHandler.VerifyAuditLog)
+
+		// Admin account management (owner only)
+		admin.GET("/users", authHandler.GetUsers)
+		admin.POST("/users", authHandler.CreateUser)
+		admin.PUT("/users/:username", authHandler.UpdateUser)
+		admin.DELETE("/users/:username", authHandler.DeleteUser)
 	}
 
-	// WebSocket endpoint
+	// Admin login page
+	r.GET("/login", authHandler.LoginPage)
+
+	// Customer phone page for spin links
+	r.GET("/play/:token", apiHandler.PlayPage)
+
+	// WebSocket endpoint and its published message schema
 	r.GET("/ws", wsHandler.HandleWebSocket)
+	r.GET("/api/ws/schema", wsHandler.GetSchema)
+
+	// Server-Sent Events fallback for displays without reliable WebSocket support
+	r.GET("/events", wsHandler.HandleEvents)
 
 	// Serve uploaded advertisement images
 	r.Static("/uploads", "data/uploads")
@@ -208,7 +373,9 @@ func main() {
 		// Serve index.html for all routes
 		r.StaticFile("/", filepath.Join(staticPath, "index.html"))
 		r.StaticFile("/user", filepath.Join(staticPath, "index.html"))
-		r.StaticFile("/admin", filepath.Join(staticPath, "index.html"))
+		r.GET("/admin", authHandler.RequireAdminPage(), func(c *gin.Context) {
+			c.File(filepath.Join(staticPath, "index.html"))
+		})
 		r.StaticFile("/restaurant", filepath.Join(staticPath, "index.html"))
 	} else {
 		fmt.Println("开发模式: 使用HTML模板")
@@ -222,7 +389,7 @@ func main() {
 			r.GET("/user", func(c *gin.Context) {
 				c.HTML(http.StatusOK, "user.html", nil)
 			})
-			r.GET("/admin", func(c *gin.Context) {
+			r.GET("/admin", authHandler.RequireAdminPage(), func(c *gin.Context) {
 				c.HTML(http.StatusOK, "admin.html", nil)
 			})
 		} else {
@@ -235,7 +402,7 @@ func main() {
 					"admin": "/admin",
 				})
 			})
-			r.GET("/admin", func(c *gin.Context) {
+			r.GET("/admin", authHandler.RequireAdminPage(), func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{
 					"message": "开发模式 - 管理界面", 
 					"note": "请运行 build.bat 构建完整版本",
@@ -247,10 +414,113 @@ func main() {
 
 	// Connect WebSocket to API handlers for broadcasting
 	apiHandler.SetWebSocketHandler(wsHandler)
+	wsHandler.SetOriginPolicy(originPolicy)
+	wsHandler.SetDeviceSeenHandler(apiHandler.MarkDeviceSeen)
+	wsHandler.SetCommandHandler(apiHandler.ExecuteCommand)
+	keyAuthorized := handlers.CommandKeyAuthorizer(*commandKey)
+	wsHandler.SetCommandAuthorizer(func(req *http.Request) (models.AuditActor, bool) {
+		if actor, ok := authHandler.CommandActor(req); ok {
+			return actor, true
+		}
+		return keyAuthorized(req)
+	})
+
+	// Recover spin locks whose unlock never happened, and resume one held before a restart
+	apiHandler.StartSpinLockWatchdog(time.Second)
+
+	// Fire scheduled page switches, including any that were pending before a restart
+	apiHandler.StartPageScheduler()
+
+	// Follow the display schedule, checking every few seconds
+	apiHandler.StartDisplaySchedule(5 * time.Second)
+
+	// Save device last-seen times, which heartbeats only update in memory
+	apiHandler.StartDevicePresenceFlush(time.Minute)
+
+	// Resolve TLS certificate before announcing URLs
+	useTLS := *enableTLS || *tlsCert != "" || *tlsKey != ""
+	scheme := "http"
+	certPath, keyPath := *tlsCert, *tlsKey
+	if useTLS {
+		scheme = "https"
+		if (certPath == "") != (keyPath == "") {
+			log.Fatal("Both -tls-cert and -tls-key are required when using your own certificate")
+		}
+		if certPath == "" {
+			certPath, keyPath, err = store.EnsureSelfSignedCertificate(localHostnames())
+			if err != nil {
+				log.Fatal("Failed to prepare self-signed certificate:", err)
+			}
+			fmt.Printf("使用自签名证书: %s (浏览器首次访问需手动信任)\n", certPath)
+		}
+	}
 
 	fmt.Printf("服务器启动在端口 %s\n", *port)
-	fmt.Printf("用户界面: http://localhost:%s/user\n", *port)
-	fmt.Printf("管理界面: http://localhost:%s/admin\n", *port)
+	fmt.Printf("用户界面: %s://localhost:%s/user\n", scheme, *port)
+	fmt.Printf("管理界面: %s://localhost:%s/admin\n", scheme, *port)
+	if originPolicy.Dev() {
+		fmt.Println("警告: 开发模式已允许所有来源访问API和WebSocket，请勿在生产环境使用 -dev")
+	}
+	if hasUsers, err := store.HasAdminUsers(); err == nil && !hasUsers {
+		fmt.Printf("首次运行: 请访问 %s://localhost:%s/login 设置管理员密码\n", scheme, *port)
+	}
+
+	if !useTLS {
+		log.Fatal(r.Run(":" + *port))
+	}
+
+	if *redirectPort != "" {
+		fmt.Printf("HTTP重定向: 端口 %s → HTTPS端口 %s\n", *redirectPort, *port)
+		go func() {
+			log.Fatal(http.ListenAndServe(":"+*redirectPort, redirectToHTTPS(*port)))
+		}()
+	}
 
-	log.Fatal(r.Run(":" + *port))
+	server := &http.Server{
+		Addr:    ":" + *port,
+		Handler: r,
+	}
+	log.Fatal(server.ListenAndServeTLS(certPath, keyPath))
+}
+
+// redirectToHTTPS sends plain HTTP requests to the same host and path on the HTTPS port
+func redirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		target := "https://" + net.JoinHostPort(host, httpsPort) + req.URL.RequestURI()
+		if httpsPort == "443" {
+			target = "https://" + host + req.URL.RequestURI()
+		}
+
+		// 308 keeps the method and body of API calls; browsers treat 301 as the usual page redirect
+		status := http.StatusMovedPermanently
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			status = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, req, target, status)
+	})
+}
+
+// localHostnames returns the names and addresses a LAN client may use to reach this machine
+func localHostnames() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		hosts = append(hosts, hostname)
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return hosts
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		hosts = append(hosts, ipNet.IP.String())
+	}
+	return hosts
 }
\ No newline at end of file
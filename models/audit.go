@@ -0,0 +1,149 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Audit Log Models
+
+// Audited administrative actions
+const (
+	AuditConfigUpdate         = "config.update"
+	AuditGameReset            = "game.reset"
+	AuditPageSwitch           = "page.switch"
+	AuditPageTransitionCancel = "page.transition_cancel"
+	AuditScheduleUpdate       = "schedule.update"
+	AuditScheduleResume       = "schedule.resume"
+	AuditRestaurantUpdate     = "restaurant.update"
+	AuditAdUpload             = "advertisement.upload"
+	AuditAdDelete             = "advertisement.delete"
+	AuditMenuUpdate           = "menu.update"
+	AuditRecommendationAdd    = "recommendation.add"
+	AuditRecommendationUpdate = "recommendation.update"
+	AuditRecommendationDelete = "recommendation.delete"
+	AuditDeviceRegister       = "device.register"
+	AuditDeviceUpdate         = "device.update"
+	AuditDeviceDelete         = "device.delete"
+	AuditDeviceCommand        = "device.command"
+	AuditAuthSetup            = "auth.setup"
+	AuditAuthLogin            = "auth.login"
+	AuditAuthLoginFailed      = "auth.login_failed"
+	AuditAuthLogout           = "auth.logout"
+	AuditPasswordChange       = "auth.password_change"
+	AuditUserCreate           = "user.create"
+	AuditUserUpdate           = "user.update"
+	AuditUserDelete           = "user.delete"
+	AuditTokenCreate          = "token.create"
+	AuditTokenRevoke          = "token.revoke"
+	AuditSpinForceUnlock      = "spin.force_unlock"
+	AuditPlayerCreate         = "player.create"
+	AuditPlayerUpdate         = "player.update"
+	AuditPlayerDelete         = "player.delete"
+	AuditBillPost             = "pos.bill"
+	AuditBillRulesUpdate      = "pos.rules_update"
+	AuditQueueEnqueue         = "queue.enqueue"
+	AuditQueueAdvance         = "queue.advance"
+	AuditQueueRemove          = "queue.remove"
+	AuditQueueSkip            = "queue.skip"
+	AuditQueueReorder         = "queue.reorder"
+	AuditVoucherRedeem        = "voucher.redeem"
+	AuditVoucherVoid          = "voucher.void"
+	AuditVoucherSettings      = "voucher.settings_update"
+	AuditSpinLinkCreate       = "spin_link.create"
+	AuditSpinLinkRevoke       = "spin_link.revoke"
+	AuditJackpotUpdate        = "jackpot.update"
+)
+
+// AuditGenesisHash is the previous-hash of the first entry in the chain
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditActor identifies who performed an action
+type AuditActor struct {
+	Username string `json:"username"`           // Admin account, or a label such as "command-key"
+	Role     string `json:"role,omitempty"`     // Role at the time of the action
+	TokenID  string `json:"token_id,omitempty"` // Set when an API token was used
+	IP       string `json:"ip"`
+}
+
+// AuditEntry is one record in the hash-chained audit log
+type AuditEntry struct {
+	Seq      int64           `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Actor    AuditActor      `json:"actor"`
+	Action   string          `json:"action"`
+	Target   string          `json:"target,omitempty"` // ID of the changed item, if any
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"` // SHA-256 over the entry with an empty hash
+}
+
+// ComputeHash returns the chain hash of the entry; Hash itself is not covered
+func (e AuditEntry) ComputeHash() (string, error) {
+	e.Hash = ""
+	// json.Marshal compacts raw values, so re-indented files hash identically
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// NewAuditValue encodes a before/after value; nil values (including nil pointers) stay empty
+func NewAuditValue(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// AuditQuery filters and pages the audit log, newest entries first
+type AuditQuery struct {
+	Action string `form:"action"` // Exact action, or a prefix ending in "." (e.g. "auth.")
+	Actor  string `form:"actor"`  // Username
+	Before int64  `form:"before"` // Only entries with a smaller seq (paging)
+	Limit  int    `form:"limit"`  // Defaults to 50, at most 500
+}
+
+// Matches reports whether an entry passes the filters
+func (q *AuditQuery) Matches(entry AuditEntry) bool {
+	if q.Before > 0 && entry.Seq >= q.Before {
+		return false
+	}
+	if q.Actor != "" && entry.Actor.Username != q.Actor {
+		return false
+	}
+	if q.Action != "" {
+		if strings.HasSuffix(q.Action, ".") {
+			return strings.HasPrefix(entry.Action, q.Action)
+		}
+		return entry.Action == q.Action
+	}
+	return true
+}
+
+// AuditPage is one page of audit entries
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int64        `json:"total"`          // Entries in the whole log
+	Next    int64        `json:"next,omitempty"` // Pass as ?before= for the next page
+}
+
+// AuditVerification is the result of checking the hash chain
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`
+	HeadHash string `json:"head_hash"`           // Hash of the last entry; note it down to detect a rewritten log
+	BrokenAt int64  `json:"broken_at,omitempty"` // Seq of the first entry that fails verification
+	Error    string `json:"error,omitempty"`
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Admin Authentication Models
+
+// passwordHashIterations is the PBKDF2-SHA256 work factor for new password hashes
+const passwordHashIterations = 210000
+
+// AdminUser represents an account allowed to use the admin interface
+type AdminUser struct {
+	Username        string    `json:"username"`
+	Role            string    `json:"role"`          // owner, manager or staff
+	PasswordHash    string    `json:"password_hash"` // Hex PBKDF2-SHA256 hash
+	Salt            string    `json:"salt"`          // Hex random salt
+	Iterations      int       `json:"iterations"`    // PBKDF2 iterations used for this hash
+	Created         time.Time `json:"created"`
+	PasswordChanged time.Time `json:"password_changed"`
+}
+
+// Session represents a logged-in admin session; only the token's hash is stored
+type Session struct {
+	TokenHash string    `json:"token_hash"`
+	Username  string    `json:"username"`
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires"`
+	LastUsed  time.Time `json:"last_used"`
+	IP        string    `json:"ip"`
+}
+
+// AuthData contains all admin accounts and sessions
+type AuthData struct {
+	Users    []AdminUser `json:"users"`
+	Sessions []Session   `json:"sessions"`
+}
+
+// LoginRequest represents a login or first-run setup request
+type LoginRequest struct {
+	Username string `json:"username"` // Defaults to "admin"
+	Password string `json:"password"` // Password or numeric PIN
+}
+
+// ChangePasswordRequest represents a password change request
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// DefaultAdminUsername is used when a login or setup request omits the username
+const DefaultAdminUsername = "admin"
+
+// ValidatePassword checks the minimum password/PIN policy
+func ValidatePassword(password string) error {
+	if len(password) < 4 {
+		return fmt.Errorf("password must be at least 4 characters")
+	}
+	if len(password) > 128 {
+		return fmt.Errorf("password must be at most 128 characters")
+	}
+	return nil
+}
+
+// SetPassword replaces the user's password hash with a freshly salted one
+func (u *AdminUser) SetPassword(password string) error {
+	if err := ValidatePassword(password); err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, password, salt, passwordHashIterations, 32)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	u.Salt = hex.EncodeToString(salt)
+	u.PasswordHash = hex.EncodeToString(hash)
+	u.Iterations = passwordHashIterations
+	u.PasswordChanged = time.Now()
+	return nil
+}
+
+// CheckPassword reports whether password matches the stored hash
+func (u *AdminUser) CheckPassword(password string) bool {
+	salt, err := hex.DecodeString(u.Salt)
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(u.PasswordHash)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, password, salt, u.Iterations, len(expected))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hash, expected) == 1
+}
+
+// NewSessionToken returns a random bearer token and the hash to store for it
+func NewSessionToken() (token string, tokenHash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token = hex.EncodeToString(raw)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the stored form of a bearer token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// POS Bill Models
+
+// Bill limits
+const (
+	MaxBillIDLength = 64
+	MaxBillAmount   = 1000000
+	MaxBillRecords  = 10000 // Oldest bills beyond this keep only their ID, so they still can't be posted again
+)
+
+// Default bill rule: one spin per ¥100 spent
+const DefaultAmountPerSpin = 100
+
+// BillRules decide how many spins a bill earns
+type BillRules struct {
+	Enabled         bool    `json:"enabled"`            // Whether posted bills grant spins at all
+	AmountPerSpin   float64 `json:"amount_per_spin"`    // Spend needed for each spin
+	MinAmount       float64 `json:"min_amount"`         // Bills below this earn nothing
+	MaxSpinsPerBill int     `json:"max_spins_per_bill"` // Cap per bill; 0 means no cap
+}
+
+// Bill is a POS bill posted for a spin grant
+type Bill struct {
+	ID           string      `json:"id"`                  // The POS bill number
+	Table        string      `json:"table"`               // Table the bill was for
+	Amount       float64     `json:"amount"`              // Bill total
+	PlayerID     string      `json:"player_id,omitempty"` // Player the spins went to
+	SpinsGranted int         `json:"spins_granted"`
+	Received     time.Time   `json:"received"`
+	Actor        *AuditActor `json:"actor,omitempty"` // Token or admin that posted it
+}
+
+// BillLedger is the persisted bill rules and the bills already processed
+type BillLedger struct {
+	Rules        BillRules `json:"rules"`
+	Bills        []Bill    `json:"bills"`
+	ForgottenIDs []string  `json:"forgotten_ids,omitempty"` // IDs of bills trimmed from Bills, oldest first
+}
+
+// Seen reports whether a bill ID was processed, including bills trimmed from the ledger
+func (l *BillLedger) Seen(id string) (Bill, bool) {
+	for _, existing := range l.Bills {
+		if existing.ID == id {
+			return existing, true
+		}
+	}
+	for _, forgotten := range l.ForgottenIDs {
+		if forgotten == id {
+			return Bill{ID: id}, true
+		}
+	}
+	return Bill{}, false
+}
+
+// Add appends a bill, keeping only the ID of bills beyond MaxBillRecords
+func (l *BillLedger) Add(bill Bill) {
+	l.Bills = append(l.Bills, bill)
+	if excess := len(l.Bills) - MaxBillRecords; excess > 0 {
+		for _, trimmed := range l.Bills[:excess] {
+			l.ForgottenIDs = append(l.ForgottenIDs, trimmed.ID)
+		}
+		l.Bills = append([]Bill(nil), l.Bills[excess:]...)
+	}
+}
+
+// BillRequest is a bill posted by the POS
+type BillRequest struct {
+	BillID   string  `json:"bill_id" binding:"required"`
+	Table    string  `json:"table"`
+	Amount   float64 `json:"amount"`
+	PlayerID string  `json:"player_id,omitempty"` // Credit this player instead of the table's latest one
+}
+
+// SpinsGrantedEvent announces spins earned from a bill, for counter displays
+type SpinsGrantedEvent struct {
+	BillID         string `json:"bill_id"`
+	Table          string `json:"table"`
+	Player         Player `json:"player"` // Phone masked
+	SpinsGranted   int    `json:"spins_granted"`
+	RemainingSpins int    `json:"remaining_spins"`
+}
+
+// GetDefaultBillLedger returns an empty ledger with the one-spin-per-¥100 rule
+func GetDefaultBillLedger() *BillLedger {
+	return &BillLedger{
+		Rules: BillRules{
+			Enabled:       true,
+			AmountPerSpin: DefaultAmountPerSpin,
+		},
+		Bills: make([]Bill, 0),
+	}
+}
+
+// Validate checks the rules are usable
+func (r *BillRules) Validate() error {
+	if r.AmountPerSpin <= 0 || r.AmountPerSpin > MaxBillAmount {
+		return fmt.Errorf("amount per spin must be greater than 0 and at most %d", MaxBillAmount)
+	}
+	if r.MinAmount < 0 {
+		return fmt.Errorf("minimum amount cannot be negative")
+	}
+	if r.MaxSpinsPerBill < 0 || r.MaxSpinsPerBill > MaxPlayerSpins {
+		return fmt.Errorf("max spins per bill must be between 0 and %d", MaxPlayerSpins)
+	}
+	return nil
+}
+
+// SpinsFor returns the spins a bill of amount earns under these rules
+func (r *BillRules) SpinsFor(amount float64) int {
+	if !r.Enabled || r.AmountPerSpin <= 0 || amount < r.MinAmount {
+		return 0
+	}
+	// Round to cents first so ¥299.99999 from float arithmetic doesn't lose a spin at ¥300
+	spins := int(math.Floor(math.Round(amount*100) / math.Round(r.AmountPerSpin*100)))
+	if r.MaxSpinsPerBill > 0 && spins > r.MaxSpinsPerBill {
+		spins = r.MaxSpinsPerBill
+	}
+	return spins
+}
+
+// Validate checks the bill request
+func (r *BillRequest) Validate() error {
+	r.BillID = strings.TrimSpace(r.BillID)
+	r.Table = strings.TrimSpace(r.Table)
+
+	if r.BillID == "" {
+		return fmt.Errorf("bill ID cannot be empty")
+	}
+	if len(r.BillID) > MaxBillIDLength {
+		return fmt.Errorf("bill ID cannot be longer than %d characters", MaxBillIDLength)
+	}
+	if r.Table == "" && r.PlayerID == "" {
+		return fmt.Errorf("table or player ID is required")
+	}
+	if utf8.RuneCountInString(r.Table) > MaxPlayerNameLength {
+		return fmt.Errorf("table cannot be longer than %d characters", MaxPlayerNameLength)
+	}
+	if r.Amount <= 0 || r.Amount > MaxBillAmount {
+		return fmt.Errorf("amount must be greater than 0 and at most %d", MaxBillAmount)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBillRulesSpinsFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  BillRules
+		amount float64
+		want   int
+	}{
+		{name: "below one spin", rules: BillRules{Enabled: true, AmountPerSpin: 100}, amount: 99.99, want: 0},
+		{name: "exactly one spin", rules: BillRules{Enabled: true, AmountPerSpin: 100}, amount: 100, want: 1},
+		{name: "rounds down", rules: BillRules{Enabled: true, AmountPerSpin: 100}, amount: 299.5, want: 2},
+		{name: "float noise at a boundary", rules: BillRules{Enabled: true, AmountPerSpin: 0.1}, amount: 0.1 + 0.2, want: 3},
+		{name: "fractional amount per spin", rules: BillRules{Enabled: true, AmountPerSpin: 33.33}, amount: 99.99, want: 3},
+		{name: "disabled", rules: BillRules{AmountPerSpin: 100}, amount: 500, want: 0},
+		{name: "under the minimum", rules: BillRules{Enabled: true, AmountPerSpin: 100, MinAmount: 200}, amount: 199, want: 0},
+		{name: "at the minimum", rules: BillRules{Enabled: true, AmountPerSpin: 100, MinAmount: 200}, amount: 200, want: 2},
+		{name: "capped", rules: BillRules{Enabled: true, AmountPerSpin: 100, MaxSpinsPerBill: 3}, amount: 1000, want: 3},
+		{name: "under the cap", rules: BillRules{Enabled: true, AmountPerSpin: 100, MaxSpinsPerBill: 3}, amount: 200, want: 2},
+		{name: "unusable rules", rules: BillRules{Enabled: true}, amount: 1000, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.SpinsFor(tt.amount); got != tt.want {
+				t.Errorf("SpinsFor(%v) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBillRulesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   BillRules
+		wantErr bool
+	}{
+		{name: "default", rules: GetDefaultBillLedger().Rules},
+		{name: "zero amount per spin", rules: BillRules{AmountPerSpin: 0}, wantErr: true},
+		{name: "amount per spin too large", rules: BillRules{AmountPerSpin: MaxBillAmount + 1}, wantErr: true},
+		{name: "negative minimum", rules: BillRules{AmountPerSpin: 100, MinAmount: -1}, wantErr: true},
+		{name: "negative cap", rules: BillRules{AmountPerSpin: 100, MaxSpinsPerBill: -1}, wantErr: true},
+		{name: "cap above the player limit", rules: BillRules{AmountPerSpin: 100, MaxSpinsPerBill: MaxPlayerSpins + 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBillRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request BillRequest
+		wantErr bool
+	}{
+		{name: "table bill", request: BillRequest{BillID: "B-1", Table: "A3", Amount: 120}},
+		{name: "player bill", request: BillRequest{BillID: "B-1", PlayerID: "p1", Amount: 120}},
+		{name: "blank bill ID", request: BillRequest{BillID: "  ", Table: "A3", Amount: 120}, wantErr: true},
+		{name: "bill ID too long", request: BillRequest{BillID: strings.Repeat("x", MaxBillIDLength+1), Table: "A3", Amount: 120}, wantErr: true},
+		{name: "no table or player", request: BillRequest{BillID: "B-1", Table: " ", Amount: 120}, wantErr: true},
+		{name: "zero amount", request: BillRequest{BillID: "B-1", Table: "A3"}, wantErr: true},
+		{name: "amount too large", request: BillRequest{BillID: "B-1", Table: "A3", Amount: MaxBillAmount + 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
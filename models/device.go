@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Display Device Registry Models
+
+// Device represents a named display screen connected to the server
+type Device struct {
+	ID             string    `json:"id"`               // Unique identifier (also used in /ws?device=<id>)
+	Name           string    `json:"name"`             // Display name, e.g. "大电视"
+	Kind           string    `json:"kind"`             // Device kind: "tv", "tablet", "kiosk" or "other"
+	AssignedPage   string    `json:"assigned_page"`    // Pinned page; empty follows the global CurrentPage
+	AdRotationTime int       `json:"ad_rotation_time"` // Seconds between ad changes; 0 uses the restaurant default
+	LastSeen       time.Time `json:"last_seen"`        // Last connect or heartbeat
+	Created        time.Time `json:"created"`          // Registration time
+	Online         bool      `json:"online"`           // Whether a WebSocket is currently open (filled in on read)
+}
+
+// DeviceRegistry contains all registered display devices
+type DeviceRegistry struct {
+	Devices []Device `json:"devices"`
+}
+
+// DeviceUpdateRequest represents a device registration or update request
+type DeviceUpdateRequest struct {
+	Name           *string `json:"name,omitempty"`
+	Kind           *string `json:"kind,omitempty"`
+	AssignedPage   *string `json:"assigned_page,omitempty"`
+	AdRotationTime *int    `json:"ad_rotation_time,omitempty"`
+}
+
+// DeviceCommandRequest represents a command targeted at a single device
+type DeviceCommandRequest struct {
+	Command string `json:"command"`        // "switch_page", "release_page", "reload" or "identify"
+	Page    string `json:"page,omitempty"` // Target page for "switch_page"
+}
+
+// Device command names
+const (
+	DeviceCommandSwitchPage  = "switch_page"
+	DeviceCommandReleasePage = "release_page"
+	DeviceCommandReload      = "reload"
+	DeviceCommandIdentify    = "identify"
+)
+
+// EffectivePage returns the page this device should show given the global page
+func (d *Device) EffectivePage(globalPage string) string {
+	if d.AssignedPage != "" {
+		return d.AssignedPage
+	}
+	return globalPage
+}
+
+// Validate validates the device settings
+func (d *Device) Validate() error {
+	if d.ID == "" {
+		return fmt.Errorf("device ID cannot be empty")
+	}
+
+	if d.Name == "" {
+		return fmt.Errorf("device name cannot be empty")
+	}
+
+	validKinds := map[string]bool{
+		"tv":     true,
+		"tablet": true,
+		"kiosk":  true,
+		"other":  true,
+	}
+	if !validKinds[d.Kind] {
+		return fmt.Errorf("invalid device kind: must be 'tv', 'tablet', 'kiosk', or 'other'")
+	}
+
+	if d.AssignedPage != "" && !IsValidPage(d.AssignedPage) {
+		return fmt.Errorf("invalid assigned page: must be 'lottery1', 'lottery2', 'lottery3', or 'advertisement'")
+	}
+
+	if d.AdRotationTime < 0 {
+		return fmt.Errorf("ad rotation time cannot be negative")
+	}
+
+	return nil
+}
+
+// Apply copies the provided fields of an update request onto the device
+func (r *DeviceUpdateRequest) Apply(d *Device) {
+	if r.Name != nil {
+		d.Name = *r.Name
+	}
+	if r.Kind != nil {
+		d.Kind = *r.Kind
+	}
+	if r.AssignedPage != nil {
+		d.AssignedPage = *r.AssignedPage
+	}
+	if r.AdRotationTime != nil {
+		d.AdRotationTime = *r.AdRotationTime
+	}
+}
+
+// Validate validates a device command request
+func (r *DeviceCommandRequest) Validate() error {
+	switch r.Command {
+	case DeviceCommandSwitchPage:
+		if !IsValidPage(r.Page) {
+			return fmt.Errorf("invalid page: must be 'lottery1', 'lottery2', 'lottery3', or 'advertisement'")
+		}
+	case DeviceCommandReleasePage, DeviceCommandReload, DeviceCommandIdentify:
+	default:
+		return fmt.Errorf("unknown command: %s", r.Command)
+	}
+	return nil
+}
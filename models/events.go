@@ -0,0 +1,386 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WebSocket Protocol Models
+
+// ProtocolVersion is the current WebSocket protocol version.
+// Version 1 is the legacy protocol: bare {type, data} envelopes and silently
+// ignored client mistakes. Version 2 stamps every envelope with "v", accepts a
+// "hello" negotiation message and answers invalid client messages with "error".
+const ProtocolVersion = 2
+
+// SupportedProtocolVersions lists every protocol version the server can speak
+var SupportedProtocolVersions = []int{1, 2}
+
+// Server → client event types
+const (
+	EventConnected               = "connected"
+	EventHelloAck                = "hello_ack"
+	EventPong                    = "pong"
+	EventError                   = "error"
+	EventConfigUpdated           = "config_updated"
+	EventStateUpdated            = "state_updated"
+	EventSpinStarted             = "spin_started"
+	EventSpinCompleted           = "spin_completed"
+	EventSpinLockCleared         = "spin_lock_cleared"
+	EventSpinLockRecovered       = "spin_lock_recovered"
+	EventPageSwitched            = "page_switched"
+	EventPageTransitionsUpdated  = "page_transitions_updated"
+	EventDisplayScheduleUpdated  = "display_schedule_updated"
+	EventRestaurantConfigUpdated = "restaurant_config_updated"
+	EventAdvertisementAdded      = "advertisement_added"
+	EventAdvertisementDeleted    = "advertisement_deleted"
+	EventMenuItemUpdated         = "menu_item_updated"
+	EventRecommendationAdded     = "recommendation_added"
+	EventRecommendationUpdated   = "recommendation_updated"
+	EventRecommendationDeleted   = "recommendation_deleted"
+	EventDeviceUpdated           = "device_updated"
+	EventDeviceDeleted           = "device_deleted"
+	EventDevicePresence          = "device_presence"
+	EventPlayerUpdated           = "player_updated"
+	EventPlayerDeleted           = "player_deleted"
+	EventSpinsGranted            = "spins_granted"
+	EventQueueUpdated            = "queue_updated"
+	EventJackpotUpdated          = "jackpot_updated"
+	EventDeviceCommand           = "device_command"
+	EventSubscribed              = "subscribed"
+	EventCommandAck              = "command_ack"
+	EventCommandError            = "command_error"
+)
+
+// Client → server message types
+const (
+	MessagePing        = "ping"
+	MessageHello       = "hello"
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessageCommand     = "command"
+)
+
+// Commands accepted in "command" messages
+const (
+	CommandSpin       = "spin"
+	CommandSwitchPage = "switch_page"
+	CommandSetPlayer  = "set_player"
+)
+
+// CommandPermissions lists the permission each command needs, the same as its REST endpoint
+var CommandPermissions = map[string]string{
+	CommandSpin:       PermissionSpin,
+	CommandSwitchPage: PermissionSwitchPage,
+	CommandSetPlayer:  PermissionSetPlayer,
+}
+
+// Event topics clients can subscribe to
+const (
+	TopicGame       = "game"       // Configuration, resets and page switches
+	TopicSpin       = "spin"       // Spin lifecycle and lock state
+	TopicRestaurant = "restaurant" // Restaurant settings, menu and recommendations
+	TopicAds        = "ads"        // Advertisement uploads and deletions
+	TopicPresence   = "presence"   // Display device registry and connectivity
+	TopicPlayers    = "players"    // Player registry and spin allowances
+)
+
+// AllTopics lists every topic; new connections are subscribed to all of them
+var AllTopics = []string{TopicGame, TopicSpin, TopicRestaurant, TopicAds, TopicPresence, TopicPlayers}
+
+// IsValidTopic reports whether topic is a known event topic
+func IsValidTopic(topic string) bool {
+	for _, known := range AllTopics {
+		if topic == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event directions
+const (
+	DirectionServer = "server" // Sent by the server to clients
+	DirectionClient = "client" // Sent by clients to the server
+)
+
+// ConnectedEvent is sent once right after the WebSocket is opened
+type ConnectedEvent struct {
+	Message           string    `json:"message"`
+	DeviceID          string    `json:"device_id,omitempty"`
+	ProtocolVersion   int       `json:"protocol_version"`   // Version in use for this connection
+	SupportedVersions []int     `json:"supported_versions"` // Versions a client may request with "hello"
+	ServerTime        time.Time `json:"server_time"`
+}
+
+// HelloMessage lets a client negotiate the protocol version after connecting
+type HelloMessage struct {
+	Versions []int `json:"versions"` // Versions the client understands
+}
+
+// HelloAckEvent confirms the negotiated protocol version
+type HelloAckEvent struct {
+	ProtocolVersion int `json:"protocol_version"`
+}
+
+// PongEvent answers a ping, echoing the client's timestamp
+type PongEvent struct {
+	Timestamp int64 `json:"timestamp"`
+}
+
+// SubscriptionMessage adds or removes topics for the sending connection
+type SubscriptionMessage struct {
+	Topics []string `json:"topics"`
+}
+
+// SubscribedEvent lists the topics a connection now receives
+type SubscribedEvent struct {
+	Topics []string `json:"topics"`
+}
+
+// CommandMessage asks the server to perform an admin action over the socket.
+// The ID is echoed in the command_ack or command_error reply.
+type CommandMessage struct {
+	ID             string  `json:"id"`
+	Command        string  `json:"command"`                   // "spin", "switch_page" or "set_player"
+	Page           string  `json:"page,omitempty"`            // Target page for switch_page
+	Player         *int    `json:"player,omitempty"`          // New current player for set_player
+	PlayerID       *string `json:"player_id,omitempty"`       // Registered player to select for set_player; empty clears it
+	RemainingSpins *int    `json:"remaining_spins,omitempty"` // Optional new venue-wide spin count for set_player; needs edit_config
+	IfMatch        string  `json:"if_match,omitempty"`        // Config ETag; switch_page and set_player are refused if the game settings changed since
+}
+
+// CommandAckEvent reports a successfully executed command
+type CommandAckEvent struct {
+	ID      string      `json:"id"`
+	Command string      `json:"command"`
+	Result  interface{} `json:"result"`         // Same body the equivalent REST endpoint returns
+	ETag    string      `json:"etag,omitempty"` // Config ETag after switch_page or set_player
+}
+
+// CommandErrorEvent reports a failed or rejected command
+type CommandErrorEvent struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+	Status  int    `json:"status"` // HTTP status the equivalent REST endpoint would return
+	Message string `json:"message"`
+}
+
+// ErrorEvent reports a rejected client message (protocol version 2 and later)
+type ErrorEvent struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// SpinStartedEvent announces that a spin has begun and the wheel is locked
+type SpinStartedEvent struct {
+	Player     int          `json:"player"`
+	IsSpinning bool         `json:"is_spinning"`
+	Timeline   SpinTimeline `json:"timeline"`
+	ServerTime time.Time    `json:"server_time"` // Lets screens correct for clock skew
+}
+
+// SpinCompletedEvent carries the result of a spin while the animation plays
+type SpinCompletedEvent struct {
+	Result     SpinResult   `json:"result"`
+	Config     *GameConfig  `json:"config"`
+	IsSpinning bool         `json:"is_spinning"`
+	Timeline   SpinTimeline `json:"timeline"`
+	ServerTime time.Time    `json:"server_time"`
+}
+
+// SpinLockEvent announces that the spin lock was released
+type SpinLockEvent struct {
+	IsSpinning bool `json:"is_spinning"`
+	Recovered  bool `json:"recovered,omitempty"` // True when a stale lock was force-cleared
+	Forced     bool `json:"forced,omitempty"`    // True when an admin force-unlocked the wheel
+}
+
+// PageSwitchedEvent announces a display page change
+type PageSwitchedEvent struct {
+	Page     string      `json:"page"`
+	Config   *GameConfig `json:"config"`
+	Auto     bool        `json:"auto,omitempty"`      // True for switches made by the server rather than an admin
+	DeviceID string      `json:"device_id,omitempty"` // Set when only one device is targeted
+	Reason   string      `json:"reason,omitempty"`    // Why the switch happened
+
+	AdRotationTime int `json:"ad_rotation_time,omitempty"` // The targeted device's seconds between ads; 0 uses the restaurant default
+}
+
+// DeletedEvent identifies a removed record
+type DeletedEvent struct {
+	ID string `json:"id"`
+}
+
+// DevicePresenceEvent announces a display device connecting or disconnecting
+type DevicePresenceEvent struct {
+	DeviceID string    `json:"device_id"`
+	Online   bool      `json:"online"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// DeviceCommandEvent asks a single display device to perform an action
+type DeviceCommandEvent struct {
+	DeviceID string `json:"device_id"`
+	Command  string `json:"command"`
+}
+
+// EventSpec describes one message type of the WebSocket protocol
+type EventSpec struct {
+	Type        string
+	Direction   string
+	Topic       string      // Empty for replies and targeted messages, which are always delivered
+	Payload     interface{} // Zero value of the payload type
+	Description string
+}
+
+// eventSpecs is the authoritative list of WebSocket message types
+var eventSpecs = []EventSpec{
+	{EventConnected, DirectionServer, "", ConnectedEvent{}, "Sent once after the connection is opened"},
+	{EventHelloAck, DirectionServer, "", HelloAckEvent{}, "Confirms the protocol version negotiated by hello"},
+	{EventPong, DirectionServer, "", PongEvent{}, "Reply to ping"},
+	{EventError, DirectionServer, "", ErrorEvent{}, "A client message was rejected"},
+	{EventSubscribed, DirectionServer, "", SubscribedEvent{}, "Reply to subscribe/unsubscribe with the current topics"},
+	{EventCommandAck, DirectionServer, "", CommandAckEvent{}, "A command succeeded"},
+	{EventCommandError, DirectionServer, "", CommandErrorEvent{}, "A command failed or was not authorized"},
+	{EventConfigUpdated, DirectionServer, TopicGame, GameConfig{}, "Game configuration was changed by an admin"},
+	{EventStateUpdated, DirectionServer, TopicGame, GameConfig{}, "Game state was reset"},
+	{EventPageSwitched, DirectionServer, TopicGame, PageSwitchedEvent{}, "The display page changed"},
+	{EventPageTransitionsUpdated, DirectionServer, TopicGame, PageTransitionsEvent{}, "Scheduled page switches were added, fired or cancelled"},
+	{EventDisplayScheduleUpdated, DirectionServer, TopicGame, DisplayScheduleEvent{}, "The display schedule or its manual override changed"},
+	{EventJackpotUpdated, DirectionServer, TopicGame, JackpotUpdatedEvent{}, "The progressive jackpot grew, paid out or was set by an admin"},
+	{EventSpinStarted, DirectionServer, TopicSpin, SpinStartedEvent{}, "A spin has started"},
+	{EventSpinCompleted, DirectionServer, TopicSpin, SpinCompletedEvent{}, "The spin result is known; animation is running"},
+	{EventSpinLockCleared, DirectionServer, TopicSpin, SpinLockEvent{}, "The spin animation finished and the wheel is unlocked"},
+	{EventSpinLockRecovered, DirectionServer, TopicSpin, SpinLockEvent{}, "A stale spin lock was cleared or an admin force-unlocked the wheel"},
+	{EventRestaurantConfigUpdated, DirectionServer, TopicRestaurant, RestaurantConfig{}, "Restaurant settings changed"},
+	{EventMenuItemUpdated, DirectionServer, TopicRestaurant, MenuItem{}, "A menu item changed"},
+	{EventRecommendationAdded, DirectionServer, TopicRestaurant, Recommendation{}, "A recommendation was added"},
+	{EventRecommendationUpdated, DirectionServer, TopicRestaurant, Recommendation{}, "A recommendation changed"},
+	{EventRecommendationDeleted, DirectionServer, TopicRestaurant, DeletedEvent{}, "A recommendation was deleted"},
+	{EventAdvertisementAdded, DirectionServer, TopicAds, Advertisement{}, "An advertisement was uploaded"},
+	{EventAdvertisementDeleted, DirectionServer, TopicAds, DeletedEvent{}, "An advertisement was deleted"},
+	{EventDeviceUpdated, DirectionServer, TopicPresence, Device{}, "A display device was registered or changed"},
+	{EventDeviceDeleted, DirectionServer, TopicPresence, DeletedEvent{}, "A display device was removed"},
+	{EventDevicePresence, DirectionServer, TopicPresence, DevicePresenceEvent{}, "A display device connected or disconnected"},
+	{EventPlayerUpdated, DirectionServer, TopicPlayers, Player{}, "A player was registered, changed or used a spin; the phone is masked"},
+	{EventPlayerDeleted, DirectionServer, TopicPlayers, DeletedEvent{}, "A player was removed"},
+	{EventSpinsGranted, DirectionServer, TopicPlayers, SpinsGrantedEvent{}, "A POS bill granted spins to a table"},
+	{EventQueueUpdated, DirectionServer, TopicPlayers, QueueUpdatedEvent{}, "The queue of players waiting to spin changed"},
+	{EventDeviceCommand, DirectionServer, "", DeviceCommandEvent{}, "Remote-control command for one device"},
+	{MessagePing, DirectionClient, "", int64(0), "Keep-alive; data is the client timestamp in milliseconds"},
+	{MessageHello, DirectionClient, "", HelloMessage{}, "Negotiates the protocol version"},
+	{MessageSubscribe, DirectionClient, "", SubscriptionMessage{}, "Starts receiving the listed topics"},
+	{MessageUnsubscribe, DirectionClient, "", SubscriptionMessage{}, "Stops receiving the listed topics"},
+	{MessageCommand, DirectionClient, "", CommandMessage{}, "Runs spin, switch_page or set_player (authorized clients only)"},
+}
+
+// EventSpecs returns the description of every WebSocket message type
+func EventSpecs() []EventSpec {
+	return eventSpecs
+}
+
+// LookupEventSpec returns the spec for a message type and direction
+func LookupEventSpec(messageType string, direction string) (EventSpec, bool) {
+	for _, spec := range eventSpecs {
+		if spec.Type == messageType && spec.Direction == direction {
+			return spec, true
+		}
+	}
+	return EventSpec{}, false
+}
+
+// Validate validates a command message
+func (m *CommandMessage) Validate() error {
+	if m.ID == "" {
+		return fmt.Errorf("command id cannot be empty")
+	}
+
+	switch m.Command {
+	case CommandSpin:
+	case CommandSwitchPage:
+		if m.Page == "" {
+			return fmt.Errorf("switch_page requires a page")
+		}
+	case CommandSetPlayer:
+		if m.Player == nil && m.PlayerID == nil {
+			return fmt.Errorf("set_player requires a player or player_id")
+		}
+	default:
+		return fmt.Errorf("unknown command: %s", m.Command)
+	}
+
+	return nil
+}
+
+// TopicForEvent returns the topic an outgoing event is published on
+func TopicForEvent(eventType string) string {
+	spec, _ := LookupEventSpec(eventType, DirectionServer)
+	return spec.Topic
+}
+
+// Validate checks that an outgoing message has a known type and the payload struct registered for it
+func (m *WebSocketMessage) Validate() error {
+	spec, ok := LookupEventSpec(m.Type, DirectionServer)
+	if !ok {
+		return fmt.Errorf("unknown event type: %s", m.Type)
+	}
+
+	payloadType := reflect.TypeOf(m.Data)
+	if payloadType != nil && payloadType.Kind() == reflect.Ptr {
+		payloadType = payloadType.Elem()
+	}
+	if payloadType != reflect.TypeOf(spec.Payload) {
+		return fmt.Errorf("event %s expects %T payload, got %T", m.Type, spec.Payload, m.Data)
+	}
+
+	return nil
+}
+
+// ClientMessage is a message received from a WebSocket client
+type ClientMessage struct {
+	Type    string          `json:"type"`
+	Version int             `json:"v,omitempty"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Decode validates the message type and decodes its payload into the registered struct
+func (m *ClientMessage) Decode() (interface{}, error) {
+	spec, ok := LookupEventSpec(m.Type, DirectionClient)
+	if !ok {
+		return nil, fmt.Errorf("unknown message type: %s", m.Type)
+	}
+
+	payload := reflect.New(reflect.TypeOf(spec.Payload))
+	if len(m.Data) > 0 && string(m.Data) != "null" {
+		if err := json.Unmarshal(m.Data, payload.Interface()); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", m.Type, err)
+		}
+	}
+
+	decoded := payload.Elem().Interface()
+	if subscription, ok := decoded.(SubscriptionMessage); ok {
+		for _, topic := range subscription.Topics {
+			if !IsValidTopic(topic) {
+				return nil, fmt.Errorf("unknown topic: %s", topic)
+			}
+		}
+	}
+
+	return decoded, nil
+}
+
+// NegotiateProtocolVersion returns the highest version both sides support, or 0 if none
+func NegotiateProtocolVersion(clientVersions []int) int {
+	best := 0
+	for _, clientVersion := range clientVersions {
+		for _, serverVersion := range SupportedProtocolVersions {
+			if clientVersion == serverVersion && clientVersion > best {
+				best = clientVersion
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []int
+		want     int
+	}{
+		{name: "both versions", versions: []int{1, 2}, want: 2},
+		{name: "order doesn't matter", versions: []int{2, 1}, want: 2},
+		{name: "legacy client", versions: []int{1}, want: 1},
+		{name: "newer client falls back", versions: []int{2, 3}, want: 2},
+		{name: "no common version", versions: []int{0, 3}, want: 0},
+		{name: "no versions", versions: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NegotiateProtocolVersion(tt.versions); got != tt.want {
+				t.Errorf("NegotiateProtocolVersion(%v) = %d, want %d", tt.versions, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketMessageValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		message WebSocketMessage
+		wantErr bool
+	}{
+		{name: "registered payload", message: WebSocketMessage{Type: EventPong, Data: PongEvent{Timestamp: 1}}},
+		{name: "pointer to the registered payload", message: WebSocketMessage{Type: EventPong, Data: &PongEvent{Timestamp: 1}}},
+		{name: "unknown type", message: WebSocketMessage{Type: "pong_v2", Data: PongEvent{}}, wantErr: true},
+		{name: "client message type", message: WebSocketMessage{Type: MessagePing, Data: int64(1)}, wantErr: true},
+		{name: "wrong payload", message: WebSocketMessage{Type: EventPong, Data: HelloAckEvent{}}, wantErr: true},
+		{name: "untyped payload", message: WebSocketMessage{Type: EventPong, Data: map[string]interface{}{"timestamp": 1}}, wantErr: true},
+		{name: "no payload", message: WebSocketMessage{Type: EventPong}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.message.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientMessageDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "ping", message: `{"type": "ping", "data": 1700000000000}`, want: int64(1700000000000)},
+		{name: "hello", message: `{"type": "hello", "data": {"versions": [1, 2]}}`, want: HelloMessage{Versions: []int{1, 2}}},
+		{name: "missing payload", message: `{"type": "hello"}`, want: HelloMessage{}},
+		{name: "null payload", message: `{"type": "ping", "data": null}`, want: int64(0)},
+		{name: "unknown type", message: `{"type": "shout", "data": {}}`, wantErr: true},
+		{name: "server event sent by a client", message: `{"type": "pong", "data": {"timestamp": 1}}`, wantErr: true},
+		{name: "payload of the wrong shape", message: `{"type": "hello", "data": {"versions": "2"}}`, wantErr: true},
+		{name: "ping with text", message: `{"type": "ping", "data": "now"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var message ClientMessage
+			if err := json.Unmarshal([]byte(tt.message), &message); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+
+			got, err := message.Decode()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("Decode() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
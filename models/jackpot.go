@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Progressive Jackpot Models
+//
+// Mode 3 is a progressive jackpot: every spin adds to a pool, and the rare
+// jackpot segment pays out the whole pool, which then starts again from the seed.
+
+// Mode3JackpotIndex is the segment the frontend draws as the jackpot
+const Mode3JackpotIndex = 11
+
+// Kinds of spin result, in SpinResult.Type
+const (
+	ResultTypePrize   = "prize"   // Any mode 1 prize, or the mode 2 win
+	ResultTypeLose    = "lose"    // A losing segment in mode 2 or 3
+	ResultTypeJackpot = "jackpot" // The mode 3 jackpot; JackpotAmount is what it paid
+)
+
+// How the jackpot changed, sent with jackpot_updated
+const (
+	JackpotChangeGrew     = "grew"     // A mode 3 spin added to the pool
+	JackpotChangeWon      = "won"      // The pool was paid out and reset to the seed
+	JackpotChangeAdjusted = "adjusted" // An admin set the pool
+)
+
+// JackpotWin records a jackpot payout
+type JackpotWin struct {
+	Amount    float64   `json:"amount"`
+	Player    int       `json:"player"`
+	PlayerID  string    `json:"player_id,omitempty"`
+	Table     string    `json:"table,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Jackpot is the persisted progressive jackpot pool
+type Jackpot struct {
+	Pool    float64     `json:"pool"`
+	Updated time.Time   `json:"updated"`
+	LastWin *JackpotWin `json:"last_win,omitempty"`
+}
+
+// JackpotUpdateRequest sets the pool, e.g. to carry an amount over or correct a mistake
+type JackpotUpdateRequest struct {
+	Pool *float64 `json:"pool" binding:"required"`
+}
+
+// JackpotUpdatedEvent announces a new pool value so displays can tick the counter
+type JackpotUpdatedEvent struct {
+	Change    string  `json:"change"`
+	Increment float64 `json:"increment"` // What each spin adds, for animating the counter
+	Jackpot
+}
+
+// SpinResultType returns the kind of result a segment gives in a mode
+func SpinResultType(mode, index int) string {
+	switch {
+	case mode == 2 && index != Mode2WinIndex:
+		return ResultTypeLose
+	case mode == 3 && index == Mode3JackpotIndex:
+		return ResultTypeJackpot
+	case mode == 3:
+		return ResultTypeLose
+	}
+	return ResultTypePrize
+}
+
+// GetDefaultJackpot returns a pool holding the default seed
+func GetDefaultJackpot() *Jackpot {
+	return &Jackpot{Pool: GetDefaultConfig().Mode3JackpotSeed}
+}
+
+// validateJackpot checks the mode 3 settings
+func (c *GameConfig) validateJackpot() error {
+	if c.Mode3JackpotSeed < 0 {
+		return fmt.Errorf("jackpot seed cannot be negative")
+	}
+	if c.Mode3JackpotIncrement < 0 {
+		return fmt.Errorf("jackpot increment cannot be negative")
+	}
+	if c.Mode3JackpotRate < 0 || c.Mode3JackpotRate > 100 {
+		return fmt.Errorf("jackpot rate must be between 0 and 100")
+	}
+	return nil
+}
+
+// ApplyJackpotDefaults fills in the mode 3 settings of configs saved before mode 3 existed
+func (c *GameConfig) ApplyJackpotDefaults() {
+	if c.Mode3JackpotText == "" && c.Mode3LoseText == "" && c.Mode3JackpotRate == 0 {
+		defaults := GetDefaultConfig()
+		c.Mode3JackpotText = defaults.Mode3JackpotText
+		c.Mode3LoseText = defaults.Mode3LoseText
+		c.Mode3JackpotRate = defaults.Mode3JackpotRate
+		c.Mode3JackpotSeed = defaults.Mode3JackpotSeed
+		c.Mode3JackpotIncrement = defaults.Mode3JackpotIncrement
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import "testing"
+
+func TestSpinResultType(t *testing.T) {
+	tests := []struct {
+		mode  int
+		index int
+		want  string
+	}{
+		{mode: 1, index: 0, want: ResultTypePrize},
+		{mode: 1, index: Mode3JackpotIndex, want: ResultTypePrize},
+		{mode: 2, index: Mode2WinIndex, want: ResultTypePrize},
+		{mode: 2, index: Mode2WinIndex + 1, want: ResultTypeLose},
+		{mode: 3, index: Mode3JackpotIndex, want: ResultTypeJackpot},
+		{mode: 3, index: 0, want: ResultTypeLose},
+	}
+
+	for _, tt := range tests {
+		if got := SpinResultType(tt.mode, tt.index); got != tt.want {
+			t.Errorf("SpinResultType(%d, %d) = %q, want %q", tt.mode, tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestValidateConfigJackpot(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *GameConfig)
+		wantErr bool
+	}{
+		{name: "defaults", change: func(c *GameConfig) {}},
+		{name: "no seed or increment", change: func(c *GameConfig) { c.Mode3JackpotSeed, c.Mode3JackpotIncrement = 0, 0 }},
+		{name: "jackpot every spin", change: func(c *GameConfig) { c.Mode3JackpotRate = 100 }},
+		{name: "negative seed", change: func(c *GameConfig) { c.Mode3JackpotSeed = -1 }, wantErr: true},
+		{name: "negative increment", change: func(c *GameConfig) { c.Mode3JackpotIncrement = -1 }, wantErr: true},
+		{name: "negative rate", change: func(c *GameConfig) { c.Mode3JackpotRate = -0.1 }, wantErr: true},
+		{name: "rate over 100", change: func(c *GameConfig) { c.Mode3JackpotRate = 100.1 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GetDefaultConfig()
+			config.Mode = 3
+			tt.change(config)
+			if err := config.ValidateConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyJackpotDefaults(t *testing.T) {
+	defaults := GetDefaultConfig()
+
+	// A config saved before mode 3 existed gets the defaults
+	old := GameConfig{Mode: 1}
+	old.ApplyJackpotDefaults()
+	if old.Mode3JackpotText != defaults.Mode3JackpotText || old.Mode3JackpotRate != defaults.Mode3JackpotRate ||
+		old.Mode3JackpotSeed != defaults.Mode3JackpotSeed || old.Mode3JackpotIncrement != defaults.Mode3JackpotIncrement {
+		t.Errorf("ApplyJackpotDefaults() = %+v, want the default mode 3 settings", old)
+	}
+
+	// A saved mode 3 setup is kept, even with no seed or increment
+	saved := GameConfig{Mode: 3, Mode3JackpotText: "Big win", Mode3JackpotRate: 2}
+	saved.ApplyJackpotDefaults()
+	if saved.Mode3JackpotText != "Big win" || saved.Mode3JackpotRate != 2 || saved.Mode3JackpotSeed != 0 || saved.Mode3JackpotIncrement != 0 {
+		t.Errorf("ApplyJackpotDefaults() changed saved settings: %+v", saved)
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Player Registry Models
+
+// Player limits
+const (
+	MaxPlayerNameLength = 32
+	MaxPlayerPhone      = 20
+	MaxPlayerSpins      = 1000
+)
+
+// Player is a registered customer with their own spin allowance
+type Player struct {
+	ID           string    `json:"id"`                 // Unique identifier
+	Table        string    `json:"table"`              // Table number or label, e.g. "A3"
+	Nickname     string    `json:"nickname,omitempty"` // Optional display name
+	Phone        string    `json:"phone,omitempty"`    // Optional contact number
+	SpinsGranted int       `json:"spins_granted"`      // Spins the player is entitled to
+	SpinsUsed    int       `json:"spins_used"`         // Spins already taken
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+}
+
+// PlayerRegistry contains all registered players
+type PlayerRegistry struct {
+	Players []Player `json:"players"`
+}
+
+// PlayerUpdateRequest represents a player registration or update request
+type PlayerUpdateRequest struct {
+	Table        *string `json:"table,omitempty"`
+	Nickname     *string `json:"nickname,omitempty"`
+	Phone        *string `json:"phone,omitempty"`
+	SpinsGranted *int    `json:"spins_granted,omitempty"` // Replaces the allowance
+	AddSpins     *int    `json:"add_spins,omitempty"`     // Adds to (or, if negative, takes from) the allowance
+}
+
+// PlayerDetail is a player together with their spin history
+type PlayerDetail struct {
+	Player  Player       `json:"player"`
+	History []SpinResult `json:"history"`
+}
+
+// RemainingSpins returns how many spins the player has left
+func (p *Player) RemainingSpins() int {
+	if remaining := p.SpinsGranted - p.SpinsUsed; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Label returns the name shown on screens: the nickname, or the table
+func (p *Player) Label() string {
+	if p.Nickname != "" {
+		return p.Nickname
+	}
+	return p.Table
+}
+
+// Public returns a copy safe to broadcast to unauthenticated screens, with the phone masked
+func (p Player) Public() Player {
+	if n := len(p.Phone); n > 4 {
+		p.Phone = strings.Repeat("*", n-4) + p.Phone[n-4:]
+	} else if n > 0 {
+		p.Phone = strings.Repeat("*", n)
+	}
+	return p
+}
+
+// Validate validates the player's details and allowance
+func (p *Player) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("player ID cannot be empty")
+	}
+
+	if strings.TrimSpace(p.Table) == "" {
+		return fmt.Errorf("table cannot be empty")
+	}
+	if utf8.RuneCountInString(p.Table) > MaxPlayerNameLength {
+		return fmt.Errorf("table cannot be longer than %d characters", MaxPlayerNameLength)
+	}
+
+	if utf8.RuneCountInString(p.Nickname) > MaxPlayerNameLength {
+		return fmt.Errorf("nickname cannot be longer than %d characters", MaxPlayerNameLength)
+	}
+
+	if len(p.Phone) > MaxPlayerPhone {
+		return fmt.Errorf("phone cannot be longer than %d characters", MaxPlayerPhone)
+	}
+	for _, r := range p.Phone {
+		if (r < '0' || r > '9') && r != '+' && r != '-' && r != ' ' {
+			return fmt.Errorf("phone may only contain digits, spaces, '+' and '-'")
+		}
+	}
+
+	if p.SpinsGranted < 0 || p.SpinsGranted > MaxPlayerSpins {
+		return fmt.Errorf("spins granted must be between 0 and %d", MaxPlayerSpins)
+	}
+	if p.SpinsUsed < 0 {
+		return fmt.Errorf("spins used cannot be negative")
+	}
+	if p.SpinsGranted < p.SpinsUsed {
+		return fmt.Errorf("spins granted cannot be less than the %d spins already used", p.SpinsUsed)
+	}
+
+	return nil
+}
+
+// Apply copies the provided fields of an update request onto the player
+func (r *PlayerUpdateRequest) Apply(p *Player) {
+	if r.Table != nil {
+		p.Table = strings.TrimSpace(*r.Table)
+	}
+	if r.Nickname != nil {
+		p.Nickname = strings.TrimSpace(*r.Nickname)
+	}
+	if r.Phone != nil {
+		p.Phone = strings.TrimSpace(*r.Phone)
+	}
+	if r.SpinsGranted != nil {
+		p.SpinsGranted = *r.SpinsGranted
+	}
+	if r.AddSpins != nil {
+		p.SpinsGranted += *r.AddSpins
+	}
+}
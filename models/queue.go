@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Customer Queue Models
+
+// How the queue changed, sent with queue_updated
+const (
+	QueueChangeEnqueued  = "enqueued"
+	QueueChangeAdvanced  = "advanced" // The next player was selected to spin
+	QueueChangeRemoved   = "removed"
+	QueueChangeSkipped   = "skipped" // A player was moved to the back
+	QueueChangeReordered = "reordered"
+)
+
+// QueueEntry is a registered player waiting for their turn at the wheel
+type QueueEntry struct {
+	PlayerID string    `json:"player_id"`
+	Table    string    `json:"table"`
+	Nickname string    `json:"nickname,omitempty"`
+	Enqueued time.Time `json:"enqueued"`
+}
+
+// PlayerQueue is the persisted FIFO queue of players waiting to spin
+type PlayerQueue struct {
+	Entries []QueueEntry `json:"entries"` // First entry is next up
+}
+
+// QueueRequest adds a player to the back of the queue
+type QueueRequest struct {
+	PlayerID string `json:"player_id" binding:"required"`
+}
+
+// QueueOrderRequest reorders the queue; it must list exactly the queued players
+type QueueOrderRequest struct {
+	PlayerIDs []string `json:"player_ids" binding:"required"`
+}
+
+// QueueStatus is what the big screen shows: who is spinning now and who is waiting
+type QueueStatus struct {
+	NowSpinning *Player      `json:"now_spinning,omitempty"` // The selected player, if any, with the phone masked
+	Entries     []QueueEntry `json:"entries"`
+}
+
+// QueueUpdatedEvent announces a change to the queue
+type QueueUpdatedEvent struct {
+	Change string `json:"change"`
+	QueueStatus
+}
+
+// NewQueueEntry returns the queue entry for a player
+func NewQueueEntry(player *Player, at time.Time) QueueEntry {
+	return QueueEntry{
+		PlayerID: player.ID,
+		Table:    player.Table,
+		Nickname: player.Nickname,
+		Enqueued: at,
+	}
+}
+
+// Index returns the position of a player in the queue, or -1
+func (q *PlayerQueue) Index(playerID string) int {
+	for i, entry := range q.Entries {
+		if entry.PlayerID == playerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Remove takes a player out of the queue, reporting whether they were in it
+func (q *PlayerQueue) Remove(playerID string) bool {
+	i := q.Index(playerID)
+	if i < 0 {
+		return false
+	}
+	q.Entries = append(q.Entries[:i], q.Entries[i+1:]...)
+	return true
+}
+
+// Reorder puts the queue in the order of playerIDs, which must list every queued player once
+func (q *PlayerQueue) Reorder(playerIDs []string) error {
+	if len(playerIDs) != len(q.Entries) {
+		return fmt.Errorf("order lists %d players but %d are queued", len(playerIDs), len(q.Entries))
+	}
+
+	reordered := make([]QueueEntry, 0, len(playerIDs))
+	seen := make(map[string]bool)
+	for _, playerID := range playerIDs {
+		i := q.Index(playerID)
+		if i < 0 {
+			return fmt.Errorf("player %s is not queued", playerID)
+		}
+		if seen[playerID] {
+			return fmt.Errorf("player %s is listed twice", playerID)
+		}
+		seen[playerID] = true
+		reordered = append(reordered, q.Entries[i])
+	}
+	q.Entries = reordered
+	return nil
+}
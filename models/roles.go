@@ -0,0 +1,154 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Role-Based Access Control Models
+
+// Roles an admin account can have
+const (
+	RoleOwner   = "owner"   // Everything, including managing other accounts
+	RoleManager = "manager" // Game settings, history, restaurant content and devices
+	RoleStaff   = "staff"   // Day-to-day operation: spins, player changes, page switches
+)
+
+// Permissions checked by the API
+const (
+	PermissionSpin          = "spin"
+	PermissionSetPlayer     = "set_player"
+	PermissionSwitchPage    = "switch_page"
+	PermissionEditConfig    = "edit_config"
+	PermissionResetHistory  = "reset_history"
+	PermissionManageAds     = "manage_ads"
+	PermissionManageMenu    = "manage_menu"
+	PermissionManageDevices = "manage_devices"
+	PermissionManageUsers   = "manage_users"
+	PermissionViewAudit     = "view_audit"
+	PermissionManageTokens  = "manage_tokens"
+	PermissionReadHistory   = "read_history"
+	PermissionForceUnlock   = "force_unlock"
+	PermissionManagePlayers = "manage_players"
+	PermissionPostBills     = "post_bills"
+	PermissionRedeemVoucher = "redeem_vouchers"
+	PermissionVoidVoucher   = "void_vouchers"
+)
+
+// staffPermissions are granted to every role
+var staffPermissions = []string{
+	PermissionReadHistory,
+	PermissionSpin,
+	PermissionSetPlayer,
+	PermissionSwitchPage,
+	PermissionManagePlayers,
+	PermissionPostBills,
+	PermissionRedeemVoucher,
+}
+
+// managerPermissions are granted to managers and owners
+var managerPermissions = append(append([]string{}, staffPermissions...),
+	PermissionEditConfig,
+	PermissionResetHistory,
+	PermissionManageAds,
+	PermissionManageMenu,
+	PermissionManageDevices,
+	PermissionForceUnlock,
+	PermissionVoidVoucher,
+)
+
+// rolePermissions lists the permissions of each role
+var rolePermissions = map[string][]string{
+	RoleStaff:   staffPermissions,
+	RoleManager: managerPermissions,
+	RoleOwner:   append(append([]string{}, managerPermissions...), PermissionManageUsers, PermissionViewAudit, PermissionManageTokens),
+}
+
+// IsValidRole checks if a role name is known
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// PermissionsForRole returns the permissions granted to a role
+func PermissionsForRole(role string) []string {
+	return append([]string{}, rolePermissions[role]...)
+}
+
+// RoleHasPermission reports whether a role grants a permission
+func RoleHasPermission(role string, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminUserInfo is an admin account as returned by the API, without credentials
+type AdminUserInfo struct {
+	Username        string    `json:"username"`
+	Role            string    `json:"role"`
+	Permissions     []string  `json:"permissions"`
+	Created         time.Time `json:"created"`
+	PasswordChanged time.Time `json:"password_changed"`
+}
+
+// Info returns the account without its credentials
+func (u *AdminUser) Info() AdminUserInfo {
+	return AdminUserInfo{
+		Username:        u.Username,
+		Role:            u.Role,
+		Permissions:     PermissionsForRole(u.Role),
+		Created:         u.Created,
+		PasswordChanged: u.PasswordChanged,
+	}
+}
+
+// CreateUserRequest represents a request to add an admin account
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// Validate checks the account request
+func (r *CreateUserRequest) Validate() error {
+	if err := ValidateUsername(r.Username); err != nil {
+		return err
+	}
+	if !IsValidRole(r.Role) {
+		return fmt.Errorf("invalid role: %s", r.Role)
+	}
+	return ValidatePassword(r.Password)
+}
+
+// UpdateUserRequest represents a change to another account's role or password
+type UpdateUserRequest struct {
+	Role     *string `json:"role,omitempty"`
+	Password *string `json:"password,omitempty"` // Resets the password and ends the user's sessions
+}
+
+// Validate checks the update request
+func (r *UpdateUserRequest) Validate() error {
+	if r.Role != nil && !IsValidRole(*r.Role) {
+		return fmt.Errorf("invalid role: %s", *r.Role)
+	}
+	if r.Password != nil {
+		return ValidatePassword(*r.Password)
+	}
+	return nil
+}
+
+// ValidateUsername checks that a username is usable in URLs and logs
+func ValidateUsername(username string) error {
+	if username == "" || len(username) > 32 {
+		return fmt.Errorf("username must be 1-32 characters")
+	}
+	for _, r := range username {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.') {
+			return fmt.Errorf("username may only contain letters, digits, '.', '_' and '-'")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultOverrideMinutes is how long a manual page switch pauses the display schedule when none is configured
+const DefaultOverrideMinutes = 30
+
+// Display schedule limits
+const (
+	MinRotateSeconds   = 10
+	MaxOverrideMinutes = 24 * 60
+)
+
+// How the display schedule changed, sent with display_schedule_updated
+const (
+	ScheduleChangeUpdated         = "updated"          // An admin edited the schedule
+	ScheduleChangeOverrideSet     = "override_set"     // A manual page switch paused the schedule
+	ScheduleChangeOverrideCleared = "override_cleared" // An admin resumed the schedule early
+	ScheduleChangeOverrideExpired = "override_expired" // The manual override ran out
+)
+
+// TransitionReasonSchedule is the page_switched reason for switches made by the display schedule
+const TransitionReasonSchedule = "schedule"
+
+// DisplaySchedule is the weekly playlist that drives the global display page.
+// The first slot covering the current time wins; outside every slot the page is left alone.
+type DisplaySchedule struct {
+	Enabled         bool           `json:"enabled"`
+	OverrideMinutes int            `json:"override_minutes"` // How long a manual page switch pauses the schedule
+	Slots           []ScheduleSlot `json:"slots"`
+}
+
+// ScheduleSlot shows one page, or rotates through several, during a daily time window
+type ScheduleSlot struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	Days          []int    `json:"days,omitempty"`           // Weekdays the slot starts on, 0 = Sunday; empty means every day
+	Start         string   `json:"start"`                    // "HH:MM" local time
+	End           string   `json:"end"`                      // "HH:MM"; at or before Start the slot runs past midnight
+	Pages         []string `json:"pages"`                    // Shown in turn
+	RotateSeconds int      `json:"rotate_seconds,omitempty"` // Time on each page when there are several
+}
+
+// ScheduleOverride pauses the display schedule after a manual page switch
+type ScheduleOverride struct {
+	Page  string      `json:"page"`
+	Until time.Time   `json:"until"`
+	Actor *AuditActor `json:"actor,omitempty"`
+}
+
+// DisplayScheduleData is the persisted schedule and the override in force, if any
+type DisplayScheduleData struct {
+	Schedule DisplaySchedule   `json:"schedule"`
+	Override *ScheduleOverride `json:"override,omitempty"`
+}
+
+// DisplayScheduleStatus describes what the schedule wants to show right now
+type DisplayScheduleStatus struct {
+	Enabled    bool              `json:"enabled"`
+	Slot       *ScheduleSlot     `json:"slot,omitempty"`        // The slot covering the current time
+	Page       string            `json:"page,omitempty"`        // The page that slot wants now
+	NextChange *time.Time        `json:"next_change,omitempty"` // When the slot rotates or ends
+	Override   *ScheduleOverride `json:"override,omitempty"`    // Set while a manual switch pauses the schedule
+}
+
+// DisplayScheduleEvent announces a change to the display schedule or its override
+type DisplayScheduleEvent struct {
+	Change string                `json:"change"`
+	Status DisplayScheduleStatus `json:"status"`
+}
+
+// GetDefaultDisplaySchedule returns an empty, disabled display schedule
+func GetDefaultDisplaySchedule() *DisplayScheduleData {
+	return &DisplayScheduleData{
+		Schedule: DisplaySchedule{
+			OverrideMinutes: DefaultOverrideMinutes,
+			Slots:           make([]ScheduleSlot, 0),
+		},
+	}
+}
+
+// Validate checks the schedule's slots and override time
+func (s *DisplaySchedule) Validate() error {
+	if s.OverrideMinutes < 0 || s.OverrideMinutes > MaxOverrideMinutes {
+		return fmt.Errorf("override minutes must be between 0 and %d", MaxOverrideMinutes)
+	}
+
+	ids := make(map[string]bool)
+	for i, slot := range s.Slots {
+		if err := slot.validate(); err != nil {
+			return fmt.Errorf("slot %d: %w", i+1, err)
+		}
+		if slot.ID != "" {
+			if ids[slot.ID] {
+				return fmt.Errorf("slot %d: duplicate ID %s", i+1, slot.ID)
+			}
+			ids[slot.ID] = true
+		}
+	}
+	return nil
+}
+
+// validate checks one slot's days, window and pages
+func (s *ScheduleSlot) validate() error {
+	for _, day := range s.Days {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("day must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if _, err := parseClock(s.Start); err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	if _, err := parseClock(s.End); err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+	if len(s.Pages) == 0 {
+		return fmt.Errorf("at least one page is required")
+	}
+	for _, page := range s.Pages {
+		if !IsValidPage(page) {
+			return fmt.Errorf("unknown page: %s", page)
+		}
+	}
+	if len(s.Pages) > 1 && s.RotateSeconds < MinRotateSeconds {
+		return fmt.Errorf("rotate seconds must be at least %d when rotating pages", MinRotateSeconds)
+	}
+	return nil
+}
+
+// Status returns the slot covering t and the page it wants, or an empty status outside every slot
+func (s *DisplaySchedule) Status(t time.Time) DisplayScheduleStatus {
+	status := DisplayScheduleStatus{Enabled: s.Enabled}
+	for i := range s.Slots {
+		slot := s.Slots[i]
+		start, end, ok := slot.windowAt(t)
+		if !ok {
+			continue
+		}
+
+		status.Slot = &slot
+		status.Page = slot.Pages[0]
+		next := end
+		if len(slot.Pages) > 1 {
+			rotation := time.Duration(slot.RotateSeconds) * time.Second
+			turn := int(t.Sub(start) / rotation)
+			status.Page = slot.Pages[turn%len(slot.Pages)]
+			if rotated := start.Add(time.Duration(turn+1) * rotation); rotated.Before(end) {
+				next = rotated
+			}
+		}
+		status.NextChange = &next
+		return status
+	}
+	return status
+}
+
+// windowAt returns the occurrence of the slot's window containing t, if any.
+// A window that runs past midnight may have started the day before. Times are
+// wall-clock times in t's location, so a slot keeps its hours across DST changes.
+func (s *ScheduleSlot) windowAt(t time.Time) (time.Time, time.Time, bool) {
+	startClock, err := parseClock(s.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endClock, err := parseClock(s.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	for _, dayOffset := range []int{0, -1} {
+		day := time.Date(t.Year(), t.Month(), t.Day()+dayOffset, 12, 0, 0, 0, t.Location())
+		if !s.runsOn(day.Weekday()) {
+			continue
+		}
+		start := clockOn(day, startClock, 0)
+		end := clockOn(day, endClock, 0)
+		if endClock <= startClock {
+			end = clockOn(day, endClock, 1)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// runsOn reports whether the slot starts on a weekday
+func (s *ScheduleSlot) runsOn(weekday time.Weekday) bool {
+	if len(s.Days) == 0 {
+		return true
+	}
+	for _, day := range s.Days {
+		if time.Weekday(day) == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// clockOn returns the wall-clock time of day clock on day, moved by days
+func clockOn(day time.Time, clock time.Duration, days int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+days,
+		int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+// parseClock parses an "HH:MM" time of day into the offset from midnight
+func parseClock(clock string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", clock)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // The DST cases need zone data on every machine
+)
+
+// at returns a time in January 2026; the 2nd is a Friday and the 3rd a Saturday
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 1, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestDisplayScheduleStatus(t *testing.T) {
+	lateNight := ScheduleSlot{ID: "late", Start: "22:00", End: "02:00", Pages: []string{"advertisement"}}
+	fridayNight := ScheduleSlot{ID: "friday", Days: []int{5}, Start: "22:00", End: "02:00", Pages: []string{"lottery3"}}
+	allDay := ScheduleSlot{ID: "all-day", Start: "08:00", End: "08:00", Pages: []string{"lottery1"}}
+	rotating := ScheduleSlot{ID: "rotating", Start: "22:00", End: "02:00", Pages: []string{"lottery1", "advertisement"}, RotateSeconds: 600}
+	lunch := ScheduleSlot{ID: "lunch", Start: "11:00", End: "14:00", Pages: []string{"lottery2"}}
+
+	tests := []struct {
+		name     string
+		slots    []ScheduleSlot
+		now      time.Time
+		wantSlot string // "" when no slot covers now
+		wantPage string
+		wantNext time.Time
+	}{
+		{name: "before a window", slots: []ScheduleSlot{lunch}, now: at(5, 10, 59)},
+		{name: "at the start", slots: []ScheduleSlot{lunch}, now: at(5, 11, 0), wantSlot: "lunch", wantPage: "lottery2", wantNext: at(5, 14, 0)},
+		{name: "at the end", slots: []ScheduleSlot{lunch}, now: at(5, 14, 0)},
+
+		{name: "overnight before midnight", slots: []ScheduleSlot{lateNight}, now: at(5, 23, 0), wantSlot: "late", wantPage: "advertisement", wantNext: at(6, 2, 0)},
+		{name: "overnight after midnight", slots: []ScheduleSlot{lateNight}, now: at(6, 1, 0), wantSlot: "late", wantPage: "advertisement", wantNext: at(6, 2, 0)},
+		{name: "overnight at midnight", slots: []ScheduleSlot{lateNight}, now: at(6, 0, 0), wantSlot: "late", wantPage: "advertisement", wantNext: at(6, 2, 0)},
+		{name: "overnight at the end", slots: []ScheduleSlot{lateNight}, now: at(6, 2, 0)},
+		{name: "overnight gap", slots: []ScheduleSlot{lateNight}, now: at(6, 12, 0)},
+
+		{name: "weekday window on its day", slots: []ScheduleSlot{fridayNight}, now: at(2, 23, 30), wantSlot: "friday", wantPage: "lottery3", wantNext: at(3, 2, 0)},
+		{name: "weekday window after midnight", slots: []ScheduleSlot{fridayNight}, now: at(3, 1, 30), wantSlot: "friday", wantPage: "lottery3", wantNext: at(3, 2, 0)},
+		{name: "weekday window the morning of its day", slots: []ScheduleSlot{fridayNight}, now: at(2, 1, 30)},
+		{name: "weekday window the next evening", slots: []ScheduleSlot{fridayNight}, now: at(3, 23, 30)},
+
+		{name: "equal start and end runs all day", slots: []ScheduleSlot{allDay}, now: at(5, 7, 59), wantSlot: "all-day", wantPage: "lottery1", wantNext: at(5, 8, 0)},
+		{name: "equal start and end restarts", slots: []ScheduleSlot{allDay}, now: at(5, 8, 0), wantSlot: "all-day", wantPage: "lottery1", wantNext: at(6, 8, 0)},
+
+		{name: "rotation first page", slots: []ScheduleSlot{rotating}, now: at(5, 22, 5), wantSlot: "rotating", wantPage: "lottery1", wantNext: at(5, 22, 10)},
+		{name: "rotation across midnight", slots: []ScheduleSlot{rotating}, now: at(5, 23, 55), wantSlot: "rotating", wantPage: "advertisement", wantNext: at(6, 0, 0)},
+		{name: "rotation continues after midnight", slots: []ScheduleSlot{rotating}, now: at(6, 0, 5), wantSlot: "rotating", wantPage: "lottery1", wantNext: at(6, 0, 10)},
+		{name: "last rotation ends with the window", slots: []ScheduleSlot{rotating}, now: at(6, 1, 55), wantSlot: "rotating", wantPage: "advertisement", wantNext: at(6, 2, 0)},
+
+		{name: "first matching slot wins", slots: []ScheduleSlot{fridayNight, lateNight}, now: at(3, 1, 0), wantSlot: "friday", wantPage: "lottery3", wantNext: at(3, 2, 0)},
+		{name: "later slot covers the other nights", slots: []ScheduleSlot{fridayNight, lateNight}, now: at(4, 1, 0), wantSlot: "late", wantPage: "advertisement", wantNext: at(4, 2, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := DisplaySchedule{Enabled: true, Slots: tt.slots}
+			status := schedule.Status(tt.now)
+
+			if tt.wantSlot == "" {
+				if status.Slot != nil {
+					t.Fatalf("slot = %s, want none", status.Slot.ID)
+				}
+				return
+			}
+			if status.Slot == nil || status.Slot.ID != tt.wantSlot {
+				t.Fatalf("slot = %+v, want %s", status.Slot, tt.wantSlot)
+			}
+			if status.Page != tt.wantPage {
+				t.Errorf("page = %q, want %q", status.Page, tt.wantPage)
+			}
+			if status.NextChange == nil || !status.NextChange.Equal(tt.wantNext) {
+				t.Errorf("next change = %v, want %v", status.NextChange, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestDisplayScheduleStatusAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	// Clocks go forward on 29 March 2026 and back on 25 October 2026
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, berlin)
+	}
+	lunch := ScheduleSlot{ID: "lunch", Start: "11:00", End: "14:00", Pages: []string{"lottery2"}}
+	lateNight := ScheduleSlot{ID: "late", Start: "22:00", End: "04:00", Pages: []string{"advertisement"}}
+
+	tests := []struct {
+		name     string
+		slot     ScheduleSlot
+		now      time.Time
+		wantSlot bool
+		wantNext time.Time
+	}{
+		{name: "spring day before the window", slot: lunch, now: local(time.March, 29, 10, 59)},
+		{name: "spring day at the start", slot: lunch, now: local(time.March, 29, 11, 0), wantSlot: true, wantNext: local(time.March, 29, 14, 0)},
+		{name: "spring day at the end", slot: lunch, now: local(time.March, 29, 14, 0)},
+		{name: "autumn day before the window", slot: lunch, now: local(time.October, 25, 10, 30)},
+		{name: "autumn day at the start", slot: lunch, now: local(time.October, 25, 11, 0), wantSlot: true, wantNext: local(time.October, 25, 14, 0)},
+		{name: "overnight into the spring change", slot: lateNight, now: local(time.March, 29, 3, 30), wantSlot: true, wantNext: local(time.March, 29, 4, 0)},
+		{name: "overnight into the autumn change", slot: lateNight, now: local(time.October, 25, 3, 30), wantSlot: true, wantNext: local(time.October, 25, 4, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := DisplaySchedule{Enabled: true, Slots: []ScheduleSlot{tt.slot}}
+			status := schedule.Status(tt.now)
+
+			if (status.Slot != nil) != tt.wantSlot {
+				t.Fatalf("slot = %+v, want covered %v", status.Slot, tt.wantSlot)
+			}
+			if tt.wantSlot && (status.NextChange == nil || !status.NextChange.Equal(tt.wantNext)) {
+				t.Errorf("next change = %v, want %v", status.NextChange, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestDisplayScheduleValidate(t *testing.T) {
+	valid := ScheduleSlot{ID: "a", Start: "22:00", End: "02:00", Pages: []string{"advertisement"}}
+
+	tests := []struct {
+		name     string
+		schedule DisplaySchedule
+		wantErr  bool
+	}{
+		{name: "overnight slot", schedule: DisplaySchedule{OverrideMinutes: 30, Slots: []ScheduleSlot{valid}}},
+		{name: "no slots", schedule: DisplaySchedule{}},
+		{name: "override too long", schedule: DisplaySchedule{OverrideMinutes: MaxOverrideMinutes + 1}, wantErr: true},
+		{name: "bad day", schedule: DisplaySchedule{Slots: []ScheduleSlot{{Days: []int{7}, Start: "10:00", End: "11:00", Pages: []string{"lottery1"}}}}, wantErr: true},
+		{name: "bad start", schedule: DisplaySchedule{Slots: []ScheduleSlot{{Start: "24:00", End: "11:00", Pages: []string{"lottery1"}}}}, wantErr: true},
+		{name: "bad end", schedule: DisplaySchedule{Slots: []ScheduleSlot{{Start: "10:00", End: "11", Pages: []string{"lottery1"}}}}, wantErr: true},
+		{name: "no pages", schedule: DisplaySchedule{Slots: []ScheduleSlot{{Start: "10:00", End: "11:00"}}}, wantErr: true},
+		{name: "unknown page", schedule: DisplaySchedule{Slots: []ScheduleSlot{{Start: "10:00", End: "11:00", Pages: []string{"menu"}}}}, wantErr: true},
+		{name: "rotation too fast", schedule: DisplaySchedule{Slots: []ScheduleSlot{{Start: "10:00", End: "11:00", Pages: []string{"lottery1", "lottery2"}, RotateSeconds: MinRotateSeconds - 1}}}, wantErr: true},
+		{name: "duplicate slot IDs", schedule: DisplaySchedule{Slots: []ScheduleSlot{valid, valid}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.schedule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// WebSocket Schema Generation
+
+// GenerateEventSchema builds a JSON Schema document describing every WebSocket
+// message, derived from the payload structs registered in eventSpecs
+func GenerateEventSchema() map[string]interface{} {
+	gen := &schemaGenerator{defs: make(map[string]interface{})}
+
+	serverMessages := make([]interface{}, 0)
+	clientMessages := make([]interface{}, 0)
+	for _, spec := range eventSpecs {
+		envelope := map[string]interface{}{
+			"type":        "object",
+			"description": spec.Description,
+			"properties": map[string]interface{}{
+				"type": map[string]interface{}{"const": spec.Type},
+				"v":    map[string]interface{}{"type": "integer", "enum": SupportedProtocolVersions},
+				"data": gen.schemaFor(reflect.TypeOf(spec.Payload)),
+			},
+			"required": []string{"type", "data"},
+		}
+		if spec.Topic != "" {
+			envelope["x-topic"] = spec.Topic
+		}
+		if spec.Direction == DirectionClient {
+			clientMessages = append(clientMessages, envelope)
+		} else {
+			serverMessages = append(serverMessages, envelope)
+		}
+	}
+
+	return map[string]interface{}{
+		"$schema":            "https://json-schema.org/draft/2020-12/schema",
+		"title":              "SpinnerWheel WebSocket protocol",
+		"protocol_version":   ProtocolVersion,
+		"supported_versions": SupportedProtocolVersions,
+		"topics":             AllTopics,
+		"$defs":              gen.defs,
+		"properties": map[string]interface{}{
+			"server_messages": map[string]interface{}{"oneOf": serverMessages},
+			"client_messages": map[string]interface{}{"oneOf": clientMessages},
+		},
+	}
+}
+
+// schemaGenerator converts Go types to JSON Schema, collecting named structs in $defs
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema for a Go type
+func (g *schemaGenerator) schemaFor(t reflect.Type) interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		// interface{} and anything else accepts any JSON value
+		return map[string]interface{}{}
+	}
+}
+
+// structRef registers a struct in $defs and returns a reference to it
+func (g *schemaGenerator) structRef(t reflect.Type) interface{} {
+	name := t.Name()
+	ref := map[string]interface{}{"$ref": "#/$defs/" + name}
+	if _, exists := g.defs[name]; exists {
+		return ref
+	}
+
+	// Reserve the name first so recursive types terminate
+	g.defs[name] = map[string]interface{}{}
+
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		jsonName := parts[0]
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		omitempty := false
+		for _, option := range parts[1:] {
+			if option == "omitempty" {
+				omitempty = true
+			}
+		}
+
+		properties[jsonName] = g.schemaFor(field.Type)
+		if !omitempty {
+			required = append(required, jsonName)
+		}
+	}
+
+	g.defs[name] = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	return ref
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Self-Service Spin Link Models
+
+// Spin link states
+const (
+	SpinLinkStatusActive  = "active"
+	SpinLinkStatusUsed    = "used"
+	SpinLinkStatusExpired = "expired"
+	SpinLinkStatusRevoked = "revoked"
+)
+
+// Spin link limits
+const (
+	DefaultSpinLinkMinutes = 30
+	MaxSpinLinkMinutes     = 24 * 60
+	MaxSpinLinkRecords     = 1000 // Oldest links are forgotten beyond this
+)
+
+// spinLinkSignatureBytes is how much of the HMAC goes into a link; 128 bits can't be guessed
+const spinLinkSignatureBytes = 16
+
+// SpinLink lets a customer take one spin from a player's allowance on their own phone
+type SpinLink struct {
+	ID        string      `json:"id"`
+	PlayerID  string      `json:"player_id"`
+	Table     string      `json:"table,omitempty"`
+	Status    string      `json:"status,omitempty"` // Filled in on read
+	Created   time.Time   `json:"created"`
+	CreatedBy AuditActor  `json:"created_by"`
+	Expires   time.Time   `json:"expires"`
+	Claimed   *time.Time  `json:"claimed,omitempty"` // When the phone took the spin
+	Result    *SpinResult `json:"result,omitempty"`
+	Revoked   *time.Time  `json:"revoked,omitempty"`
+}
+
+// SpinLinkBook is the persisted signing secret and every spin link issued
+type SpinLinkBook struct {
+	Secret string     `json:"secret"` // Hex HMAC key; never leaves the server
+	Links  []SpinLink `json:"links"`
+}
+
+// SpinLinkRequest creates a spin link for a player, or for the player most recently registered at a table
+type SpinLinkRequest struct {
+	PlayerID       string `json:"player_id"`
+	Table          string `json:"table"`
+	ExpiresMinutes int    `json:"expires_minutes"` // Default 30
+}
+
+// SpinLinkView is a spin link with the URL the customer opens and its QR image
+type SpinLinkView struct {
+	SpinLink
+	URL   string `json:"url"`
+	QRURL string `json:"qr_url"`
+}
+
+// SpinLinkStatus is what the customer's phone sees; it never includes the phone number
+type SpinLinkStatus struct {
+	Status   string      `json:"status"`
+	Table    string      `json:"table,omitempty"`
+	Nickname string      `json:"nickname,omitempty"`
+	Expires  time.Time   `json:"expires"`
+	Result   *SpinResult `json:"result,omitempty"`
+	Voucher  *Voucher    `json:"voucher,omitempty"`
+}
+
+// SpinLinkSpinResponse is returned to the phone that took the spin, with the timeline
+// so it can reveal the prize when the big screen does
+type SpinLinkSpinResponse struct {
+	SpinLinkStatus
+	Timeline   SpinTimeline `json:"timeline"`
+	ServerTime time.Time    `json:"server_time"`
+}
+
+// NewSpinLinkSecret returns a random signing key
+func NewSpinLinkSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// Validate checks the request
+func (r *SpinLinkRequest) Validate() error {
+	if strings.TrimSpace(r.PlayerID) == "" && strings.TrimSpace(r.Table) == "" {
+		return fmt.Errorf("player_id or table is required")
+	}
+	if r.ExpiresMinutes < 0 || r.ExpiresMinutes > MaxSpinLinkMinutes {
+		return fmt.Errorf("expires_minutes must be between 1 and %d, or 0 for the default", MaxSpinLinkMinutes)
+	}
+	return nil
+}
+
+// StatusAt returns whether the link can still be used at now, and if not why
+func (l *SpinLink) StatusAt(now time.Time) string {
+	switch {
+	case l.Revoked != nil:
+		return SpinLinkStatusRevoked
+	case l.Claimed != nil:
+		return SpinLinkStatusUsed
+	case !now.Before(l.Expires):
+		return SpinLinkStatusExpired
+	}
+	return SpinLinkStatusActive
+}
+
+// Token returns the signed token in the link's URL: the ID and an HMAC over
+// the ID, player and expiry, so a link can't be forged or moved to another player
+func (l *SpinLink) Token(secret string) string {
+	return l.ID + "." + l.signature(secret)
+}
+
+// VerifyToken reports whether a token was signed for this link
+func (l *SpinLink) VerifyToken(secret, token string) bool {
+	_, signature, ok := strings.Cut(token, ".")
+	return ok && hmac.Equal([]byte(signature), []byte(l.signature(secret)))
+}
+
+// signature signs the link's ID, player and expiry
+func (l *SpinLink) signature(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(l.ID + "|" + l.PlayerID + "|" + strconv.FormatInt(l.Expires.Unix(), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:spinLinkSignatureBytes])
+}
+
+// SpinLinkID returns the link ID part of a token
+func SpinLinkID(token string) string {
+	id, _, _ := strings.Cut(token, ".")
+	return id
+}
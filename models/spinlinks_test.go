@@ -0,0 +1,114 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpinLinkVerifyToken(t *testing.T) {
+	const secret = "0123456789abcdef"
+	expires := time.Date(2026, 1, 1, 21, 0, 0, 0, time.UTC)
+	link := SpinLink{ID: "l1", PlayerID: "p1", Expires: expires}
+	token := link.Token(secret)
+
+	tests := []struct {
+		name  string
+		link  SpinLink
+		token string
+		want  bool
+	}{
+		{name: "issued token", link: link, token: token, want: true},
+		{name: "other secret", link: link, token: link.Token("fedcba9876543210"), want: false},
+		{name: "tampered signature", link: link, token: token[:len(token)-1] + flipChar(token[len(token)-1]), want: false},
+		{name: "truncated signature", link: link, token: token[:len(token)-4], want: false},
+		{name: "moved to another player", link: SpinLink{ID: "l1", PlayerID: "p2", Expires: expires}, token: token, want: false},
+		{name: "expiry extended", link: SpinLink{ID: "l1", PlayerID: "p1", Expires: expires.Add(time.Hour)}, token: token, want: false},
+		{name: "no signature", link: link, token: "l1", want: false},
+		{name: "empty signature", link: link, token: "l1.", want: false},
+		{name: "empty token", link: link, token: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.VerifyToken(secret, tt.token); got != tt.want {
+				t.Errorf("VerifyToken(%q) = %v, want %v", tt.token, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpinLinkID(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{token: "l1.c2lnbmF0dXJl", want: "l1"},
+		{token: "l1", want: "l1"},
+		{token: ".c2lnbmF0dXJl", want: ""},
+		{token: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := SpinLinkID(tt.token); got != tt.want {
+			t.Errorf("SpinLinkID(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestSpinLinkStatusAt(t *testing.T) {
+	now := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Minute)
+
+	tests := []struct {
+		name string
+		link SpinLink
+		want string
+	}{
+		{name: "active", link: SpinLink{Expires: now.Add(time.Minute)}, want: SpinLinkStatusActive},
+		{name: "expires now", link: SpinLink{Expires: now}, want: SpinLinkStatusExpired},
+		{name: "expired", link: SpinLink{Expires: earlier}, want: SpinLinkStatusExpired},
+		{name: "used", link: SpinLink{Expires: now.Add(time.Minute), Claimed: &earlier}, want: SpinLinkStatusUsed},
+		{name: "used then expired", link: SpinLink{Expires: earlier, Claimed: &earlier}, want: SpinLinkStatusUsed},
+		{name: "revoked", link: SpinLink{Expires: now.Add(time.Minute), Revoked: &earlier}, want: SpinLinkStatusRevoked},
+		{name: "revoked after use", link: SpinLink{Expires: now.Add(time.Minute), Claimed: &earlier, Revoked: &earlier}, want: SpinLinkStatusRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.StatusAt(now); got != tt.want {
+				t.Errorf("StatusAt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpinLinkRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request SpinLinkRequest
+		wantErr bool
+	}{
+		{name: "player", request: SpinLinkRequest{PlayerID: "p1"}},
+		{name: "table", request: SpinLinkRequest{Table: "A3", ExpiresMinutes: 15}},
+		{name: "longest expiry", request: SpinLinkRequest{PlayerID: "p1", ExpiresMinutes: MaxSpinLinkMinutes}},
+		{name: "no player or table", request: SpinLinkRequest{PlayerID: " ", Table: " "}, wantErr: true},
+		{name: "negative expiry", request: SpinLinkRequest{PlayerID: "p1", ExpiresMinutes: -1}, wantErr: true},
+		{name: "expiry too long", request: SpinLinkRequest{PlayerID: "p1", ExpiresMinutes: MaxSpinLinkMinutes + 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// flipChar returns a different character from the base64url alphabet
+func flipChar(c byte) string {
+	if c == 'A' {
+		return "B"
+	}
+	return "A"
+}
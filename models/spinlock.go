@@ -0,0 +1,9 @@
+package models
+
+// SpinLock is the state of the wheel's spin lock. It is persisted so a restart
+// in the middle of a spin comes back locked until the spin's timeline unlocks.
+type SpinLock struct {
+	Locked   bool          `json:"locked"`
+	Holder   *AuditActor   `json:"holder,omitempty"`   // Who started the spin holding the lock
+	Timeline *SpinTimeline `json:"timeline,omitempty"` // Start is when the lock was taken, Unlock when it is expected to end
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Spin timing defaults, matching the original fixed 8-second lock:
+// a 6-second wheel animation, an immediate reveal and a 2-second cool-down
+const (
+	DefaultSpinDurationMs = 6000
+	DefaultRevealDelayMs  = 0
+	DefaultCooldownMs     = 2000
+)
+
+// Spin timing limits
+const (
+	MinSpinDurationMs = 1000
+	MaxSpinDurationMs = 60000
+	MaxRevealDelayMs  = 30000
+	MaxCooldownMs     = 60000
+)
+
+// SpinTimeline is the server-authoritative schedule of one spin. Screens animate
+// against it and the spin lock is released at Unlock.
+type SpinTimeline struct {
+	Start  time.Time `json:"start"`  // The wheel starts turning
+	Stop   time.Time `json:"stop"`   // The wheel comes to rest on the result
+	Reveal time.Time `json:"reveal"` // The result is announced
+	Unlock time.Time `json:"unlock"` // The wheel accepts the next spin
+}
+
+// SpinTimeline returns the timeline of a spin starting at start under this config's timings
+func (c *GameConfig) SpinTimeline(start time.Time) SpinTimeline {
+	stop := start.Add(time.Duration(c.SpinDurationMs) * time.Millisecond)
+	reveal := stop.Add(time.Duration(c.RevealDelayMs) * time.Millisecond)
+	return SpinTimeline{
+		Start:  start,
+		Stop:   stop,
+		Reveal: reveal,
+		Unlock: reveal.Add(time.Duration(c.CooldownMs) * time.Millisecond),
+	}
+}
+
+// ApplyTimingDefaults fills in the spin timings of configs saved before they were configurable
+func (c *GameConfig) ApplyTimingDefaults() {
+	// A valid config always has a spin duration, so zero means the timings were never set
+	if c.SpinDurationMs == 0 {
+		c.SpinDurationMs = DefaultSpinDurationMs
+		c.RevealDelayMs = DefaultRevealDelayMs
+		c.CooldownMs = DefaultCooldownMs
+	}
+}
+
+// validateTiming checks the spin timings are within their limits
+func (c *GameConfig) validateTiming() error {
+	if c.SpinDurationMs < MinSpinDurationMs || c.SpinDurationMs > MaxSpinDurationMs {
+		return fmt.Errorf("spin duration must be between %d and %d ms", MinSpinDurationMs, MaxSpinDurationMs)
+	}
+	if c.RevealDelayMs < 0 || c.RevealDelayMs > MaxRevealDelayMs {
+		return fmt.Errorf("reveal delay must be between 0 and %d ms", MaxRevealDelayMs)
+	}
+	if c.CooldownMs < 0 || c.CooldownMs > MaxCooldownMs {
+		return fmt.Errorf("cool-down must be between 0 and %d ms", MaxCooldownMs)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGameConfigSpinTimeline(t *testing.T) {
+	start := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		spin       int // Milliseconds
+		reveal     int
+		cooldown   int
+		wantStop   time.Duration // Since start
+		wantReveal time.Duration
+		wantUnlock time.Duration
+	}{
+		{name: "defaults", spin: DefaultSpinDurationMs, reveal: DefaultRevealDelayMs, cooldown: DefaultCooldownMs, wantStop: 6 * time.Second, wantReveal: 6 * time.Second, wantUnlock: 8 * time.Second},
+		{name: "drum roll before the reveal", spin: 4000, reveal: 1500, cooldown: 500, wantStop: 4 * time.Second, wantReveal: 5500 * time.Millisecond, wantUnlock: 6 * time.Second},
+		{name: "no cool-down", spin: 1000, reveal: 0, cooldown: 0, wantStop: time.Second, wantReveal: time.Second, wantUnlock: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GameConfig{SpinDurationMs: tt.spin, RevealDelayMs: tt.reveal, CooldownMs: tt.cooldown}
+			got := config.SpinTimeline(start)
+			if !got.Start.Equal(start) || got.Stop.Sub(start) != tt.wantStop || got.Reveal.Sub(start) != tt.wantReveal || got.Unlock.Sub(start) != tt.wantUnlock {
+				t.Errorf("SpinTimeline() = stop +%v, reveal +%v, unlock +%v; want +%v, +%v, +%v",
+					got.Stop.Sub(start), got.Reveal.Sub(start), got.Unlock.Sub(start), tt.wantStop, tt.wantReveal, tt.wantUnlock)
+			}
+		})
+	}
+}
+
+func TestValidateConfigTiming(t *testing.T) {
+	tests := []struct {
+		name     string
+		spin     int
+		reveal   int
+		cooldown int
+		wantErr  bool
+	}{
+		{name: "defaults", spin: DefaultSpinDurationMs, reveal: DefaultRevealDelayMs, cooldown: DefaultCooldownMs},
+		{name: "shortest", spin: MinSpinDurationMs},
+		{name: "longest", spin: MaxSpinDurationMs, reveal: MaxRevealDelayMs, cooldown: MaxCooldownMs},
+		{name: "spin too short", spin: MinSpinDurationMs - 1, wantErr: true},
+		{name: "spin too long", spin: MaxSpinDurationMs + 1, wantErr: true},
+		{name: "negative reveal delay", spin: DefaultSpinDurationMs, reveal: -1, wantErr: true},
+		{name: "reveal delay too long", spin: DefaultSpinDurationMs, reveal: MaxRevealDelayMs + 1, wantErr: true},
+		{name: "negative cool-down", spin: DefaultSpinDurationMs, cooldown: -1, wantErr: true},
+		{name: "cool-down too long", spin: DefaultSpinDurationMs, cooldown: MaxCooldownMs + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GetDefaultConfig()
+			config.SpinDurationMs, config.RevealDelayMs, config.CooldownMs = tt.spin, tt.reveal, tt.cooldown
+			if err := config.ValidateConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyTimingDefaults(t *testing.T) {
+	// A config saved before timings existed gets the old fixed 8-second lock
+	legacy := GameConfig{}
+	legacy.ApplyTimingDefaults()
+	if legacy.SpinDurationMs != DefaultSpinDurationMs || legacy.RevealDelayMs != DefaultRevealDelayMs || legacy.CooldownMs != DefaultCooldownMs {
+		t.Errorf("legacy config timings = %d/%d/%d", legacy.SpinDurationMs, legacy.RevealDelayMs, legacy.CooldownMs)
+	}
+
+	// Configured timings, including a zero cool-down, are kept
+	configured := GameConfig{SpinDurationMs: 3000, RevealDelayMs: 500, CooldownMs: 0}
+	configured.ApplyTimingDefaults()
+	if configured.SpinDurationMs != 3000 || configured.RevealDelayMs != 500 || configured.CooldownMs != 0 {
+		t.Errorf("configured timings = %d/%d/%d, want 3000/500/0", configured.SpinDurationMs, configured.RevealDelayMs, configured.CooldownMs)
+	}
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// API Token Models
+
+// APITokenPrefix marks bearer tokens that are API tokens rather than admin sessions
+const APITokenPrefix = "swt_"
+
+// DefaultTokenRateLimit is the per-token budget in requests per minute when none is set
+const DefaultTokenRateLimit = 60
+
+// Scopes an API token can be granted
+const (
+	ScopeSpin           = "spin"
+	ScopeReadHistory    = "read-history"
+	ScopeManageMenu     = "manage-menu"
+	ScopePostBills      = "post-bills"
+	ScopeRedeemVouchers = "redeem-vouchers"
+)
+
+// scopePermissions maps each scope to the permission it grants
+var scopePermissions = map[string]string{
+	ScopeSpin:           PermissionSpin,
+	ScopeReadHistory:    PermissionReadHistory,
+	ScopeManageMenu:     PermissionManageMenu,
+	ScopePostBills:      PermissionPostBills,
+	ScopeRedeemVouchers: PermissionRedeemVoucher,
+}
+
+// APIToken is a long-lived credential for an external integration; only its hash is stored
+type APIToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`                 // e.g. "POS"
+	Prefix     string     `json:"prefix"`               // First characters of the token, to recognise it
+	TokenHash  string     `json:"token_hash,omitempty"` // Cleared before tokens are returned by the API
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit"` // Requests per minute
+	Created    time.Time  `json:"created"`
+	CreatedBy  string     `json:"created_by"`
+	LastUsed   *time.Time `json:"last_used,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	Revoked    *time.Time `json:"revoked,omitempty"`
+}
+
+// TokenRegistry contains all API tokens, including revoked ones
+type TokenRegistry struct {
+	Tokens []APIToken `json:"tokens"`
+}
+
+// Permissions returns the permissions granted by the token's scopes
+func (t *APIToken) Permissions() []string {
+	permissions := make([]string, 0, len(t.Scopes))
+	for _, scope := range t.Scopes {
+		if permission, ok := scopePermissions[scope]; ok {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
+// Active reports whether the token can still be used
+func (t *APIToken) Active() bool {
+	return t.Revoked == nil
+}
+
+// CreateTokenRequest represents a request to issue an API token
+type CreateTokenRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Scopes    []string `json:"scopes" binding:"required"`
+	RateLimit int      `json:"rate_limit"` // Requests per minute, defaults to DefaultTokenRateLimit
+}
+
+// Validate checks the token request
+func (r *CreateTokenRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.Scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range r.Scopes {
+		if _, ok := scopePermissions[scope]; !ok {
+			return fmt.Errorf("invalid scope: %s", scope)
+		}
+	}
+	if r.RateLimit < 0 || r.RateLimit > 6000 {
+		return fmt.Errorf("rate_limit must be between 0 and 6000 requests per minute")
+	}
+	return nil
+}
+
+// NewAPIToken returns a random API token and the hash to store for it
+func NewAPIToken() (token string, tokenHash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token = APITokenPrefix + hex.EncodeToString(raw)
+	return token, HashToken(token), nil
+}
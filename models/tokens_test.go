@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestCreateTokenRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request CreateTokenRequest
+		wantErr bool
+	}{
+		{name: "one scope", request: CreateTokenRequest{Name: "POS", Scopes: []string{ScopePostBills}}},
+		{name: "several scopes and a limit", request: CreateTokenRequest{Name: "Kiosk", Scopes: []string{ScopeSpin, ScopeReadHistory}, RateLimit: 6000}},
+		{name: "blank name", request: CreateTokenRequest{Name: " ", Scopes: []string{ScopeSpin}}, wantErr: true},
+		{name: "no scopes", request: CreateTokenRequest{Name: "POS"}, wantErr: true},
+		{name: "unknown scope", request: CreateTokenRequest{Name: "POS", Scopes: []string{ScopeSpin, "admin"}}, wantErr: true},
+		{name: "negative limit", request: CreateTokenRequest{Name: "POS", Scopes: []string{ScopeSpin}, RateLimit: -1}, wantErr: true},
+		{name: "limit too high", request: CreateTokenRequest{Name: "POS", Scopes: []string{ScopeSpin}, RateLimit: 6001}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPITokenPermissions(t *testing.T) {
+	token := APIToken{Scopes: []string{ScopeSpin, "retired-scope", ScopeRedeemVouchers}}
+	got := token.Permissions()
+	want := []string{PermissionSpin, PermissionRedeemVoucher}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Permissions() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Why a page transition was scheduled, sent as the reason of its page_switched event
+const (
+	TransitionReasonAfterSpin           = "after_spin"             // Show the advertisement once a spin unlocks
+	TransitionReasonAdvertisementFinish = "advertisement_finished" // Return to the lottery after the advertisement
+	TransitionReasonManual              = "manual"                 // An admin switched the page
+)
+
+// How the pending transitions changed, sent with page_transitions_updated
+const (
+	TransitionChangeScheduled  = "scheduled"
+	TransitionChangeFired      = "fired"
+	TransitionChangeCancelled  = "cancelled"
+	TransitionChangeSuperseded = "superseded" // A manual page switch replaced them
+)
+
+// PageTransition is a global page switch scheduled for later
+type PageTransition struct {
+	ID      string    `json:"id"`
+	Page    string    `json:"page,omitempty"` // Target page; empty returns to the lottery page of the current mode
+	At      time.Time `json:"at"`
+	Reason  string    `json:"reason"`
+	Created time.Time `json:"created"`
+}
+
+// PageTransitionList is the persisted set of pending page transitions
+type PageTransitionList struct {
+	Transitions []PageTransition `json:"transitions"`
+}
+
+// PageTransitionsEvent announces a change to the pending page transitions
+type PageTransitionsEvent struct {
+	Change      string           `json:"change"`
+	Transitions []PageTransition `json:"transitions"` // Everything still pending after the change
+}
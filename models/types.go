@@ -7,14 +7,23 @@ import (
 
 // GameConfig represents the main configuration for the game
 type GameConfig struct {
-	Mode           int              `json:"mode"`                     // 1 or 2
+	Mode           int              `json:"mode"`                     // 1, 2 or 3 (progressive jackpot)
 	Mode1Options   []PrizeOption    `json:"mode1_options"`           // Options for mode 1
 	Mode2WinText   string           `json:"mode2_win_text"`          // Custom winning text for mode 2
 	Mode2LoseText  string           `json:"mode2_lose_text"`         // Custom losing text for mode 2
 	Mode2WinRate   float64          `json:"mode2_win_rate"`          // Win probability for mode 2 (0-100)
+	Mode3JackpotText      string    `json:"mode3_jackpot_text"`      // Jackpot segment text for mode 3
+	Mode3LoseText         string    `json:"mode3_lose_text"`         // Losing segment text for mode 3
+	Mode3JackpotRate      float64   `json:"mode3_jackpot_rate"`      // Jackpot probability for mode 3 (0-100)
+	Mode3JackpotSeed      float64   `json:"mode3_jackpot_seed"`      // Pool value after a payout
+	Mode3JackpotIncrement float64   `json:"mode3_jackpot_increment"` // Added to the pool by every mode 3 spin
 	CurrentPlayer  int              `json:"current_player"`          // Current player number
+	CurrentPlayerID string          `json:"current_player_id,omitempty"` // Registered player whose allowance spins use; empty uses RemainingSpins
 	RemainingSpins int              `json:"remaining_spins"`         // Remaining spins
-	CurrentPage    string           `json:"current_page"`            // Current display page: "lottery1", "lottery2", "advertisement"
+	CurrentPage    string           `json:"current_page"`            // Current display page: "lottery1", "lottery2", "lottery3", "advertisement"
+	SpinDurationMs int              `json:"spin_duration_ms"`        // How long the wheel turns
+	RevealDelayMs  int              `json:"reveal_delay_ms"`         // Pause between the wheel stopping and the result announcement
+	CooldownMs     int              `json:"cooldown_ms"`             // How long the wheel stays locked after the reveal
 }
 
 // PrizeOption represents a single prize option for mode 1
@@ -26,10 +35,21 @@ type PrizeOption struct {
 // SpinResult represents the result of a single spin
 type SpinResult struct {
 	Player    int       `json:"player"`    // Player number
+	PlayerID  string    `json:"player_id,omitempty"` // Registered player, if one was selected
+	Table     string    `json:"table,omitempty"`     // That player's table
 	Prize     string    `json:"prize"`     // Prize name/text
 	Index     int       `json:"index"`     // Segment index (0-11)
 	Timestamp time.Time `json:"timestamp"` // When the spin occurred
 	Mode      int       `json:"mode"`      // Which mode was used
+	Type      string    `json:"type,omitempty"` // prize, lose or jackpot; empty for spins recorded before result types
+	JackpotAmount float64 `json:"jackpot_amount,omitempty"` // What a jackpot result paid out
+	VoucherCode string  `json:"voucher_code,omitempty"` // Prize voucher issued for a win; hidden from displays
+}
+
+// Redacted returns the result as shown to displays and the public history, without its voucher code
+func (r SpinResult) Redacted() SpinResult {
+	r.VoucherCode = ""
+	return r
 }
 
 // SpinHistory contains all spin results
@@ -39,8 +59,9 @@ type SpinHistory struct {
 
 // WebSocketMessage represents messages sent over WebSocket
 type WebSocketMessage struct {
-	Type string      `json:"type"` // Event type
-	Data interface{} `json:"data"` // Event data
+	Type    string      `json:"type"`        // Event type
+	Version int         `json:"v,omitempty"` // Protocol version (omitted for version 1 clients)
+	Data    interface{} `json:"data"`        // Event payload, one of the structs in events.go
 }
 
 // SpinRequest represents a spin request from the client
@@ -55,9 +76,38 @@ type ConfigUpdateRequest struct {
 	Mode2WinText   *string          `json:"mode2_win_text,omitempty"`
 	Mode2LoseText  *string          `json:"mode2_lose_text,omitempty"`
 	Mode2WinRate   *float64         `json:"mode2_win_rate,omitempty"`
+	Mode3JackpotText      *string   `json:"mode3_jackpot_text,omitempty"`
+	Mode3LoseText         *string   `json:"mode3_lose_text,omitempty"`
+	Mode3JackpotRate      *float64  `json:"mode3_jackpot_rate,omitempty"`
+	Mode3JackpotSeed      *float64  `json:"mode3_jackpot_seed,omitempty"`
+	Mode3JackpotIncrement *float64  `json:"mode3_jackpot_increment,omitempty"`
 	CurrentPlayer  *int             `json:"current_player,omitempty"`
+	CurrentPlayerID *string         `json:"current_player_id,omitempty"` // Empty clears the selection
 	RemainingSpins *int             `json:"remaining_spins,omitempty"`
+	ExpectedRemainingSpins *int     `json:"expected_remaining_spins,omitempty"` // Spins the client last saw; refused with 412 if play has changed them
 	CurrentPage    *string          `json:"current_page,omitempty"`
+	SpinDurationMs *int             `json:"spin_duration_ms,omitempty"`
+	RevealDelayMs  *int             `json:"reveal_delay_ms,omitempty"`
+	CooldownMs     *int             `json:"cooldown_ms,omitempty"`
+}
+
+// ChangesGameSettings reports whether the update touches more than the current player and spins
+func (r *ConfigUpdateRequest) ChangesGameSettings() bool {
+	return r.Mode != nil || r.Mode1Options != nil || r.Mode2WinText != nil ||
+		r.Mode2LoseText != nil || r.Mode2WinRate != nil ||
+		r.Mode3JackpotText != nil || r.Mode3LoseText != nil || r.Mode3JackpotRate != nil ||
+		r.Mode3JackpotSeed != nil || r.Mode3JackpotIncrement != nil ||
+		r.SpinDurationMs != nil || r.RevealDelayMs != nil || r.CooldownMs != nil
+}
+
+// GameSettings returns the config without the current player, spins and page, which change
+// through play rather than admin edits; only a change to the rest conflicts with an open edit
+func (c GameConfig) GameSettings() GameConfig {
+	c.CurrentPlayer = 0
+	c.CurrentPlayerID = ""
+	c.RemainingSpins = 0
+	c.CurrentPage = ""
+	return c
 }
 
 // Restaurant and Advertisement System Models
@@ -114,7 +164,18 @@ type RestaurantData struct {
 
 // PageSwitchRequest represents a request to switch the display page
 type PageSwitchRequest struct {
-	Page string `json:"page"` // Target page: "lottery1", "lottery2", "advertisement"
+	Page string `json:"page"` // Target page: "lottery1", "lottery2", "lottery3", "advertisement"
+}
+
+// IsValidPage reports whether page is a known display page
+func IsValidPage(page string) bool {
+	validPages := map[string]bool{
+		"lottery1":      true,
+		"lottery2":      true,
+		"lottery3":      true,
+		"advertisement": true,
+	}
+	return validPages[page]
 }
 
 // GetDefaultConfig returns the default game configuration
@@ -124,9 +185,17 @@ func GetDefaultConfig() *GameConfig {
 		Mode2WinText:   "中奖了!", // Default winning text for mode 2
 		Mode2LoseText:  "再接再厉", // Default losing text for mode 2
 		Mode2WinRate:   8.33,     // Default 8.33% win rate (1/12 chance)
+		Mode3JackpotText:      "头奖!",
+		Mode3LoseText:         "再接再厉",
+		Mode3JackpotRate:      0.5, // Rare: 1 spin in 200
+		Mode3JackpotSeed:      500,
+		Mode3JackpotIncrement: 10,
 		CurrentPlayer:  1,
 		RemainingSpins: 100,
 		CurrentPage:    "lottery1", // Default to lottery mode 1
+		SpinDurationMs: DefaultSpinDurationMs,
+		RevealDelayMs:  DefaultRevealDelayMs,
+		CooldownMs:     DefaultCooldownMs,
 		Mode1Options: []PrizeOption{
 			{"奖品1", 8.33},
 			{"奖品2", 8.33},
@@ -177,6 +246,9 @@ func generateDefaultMenuItems() []MenuItem {
 	return items
 }
 
+// Mode2WinIndex is the segment the frontend draws as the mode 2 win
+const Mode2WinIndex = 11
+
 // GetMode2Options returns the fixed options for mode 2
 func GetMode2Options() []PrizeOption {
 	options := make([]PrizeOption, 12)
@@ -189,8 +261,8 @@ func GetMode2Options() []PrizeOption {
 
 // ValidateConfig validates the game configuration
 func (c *GameConfig) ValidateConfig() error {
-	if c.Mode != 1 && c.Mode != 2 {
-		return fmt.Errorf("invalid mode: must be 1 or 2")
+	if c.Mode < 1 || c.Mode > 3 {
+		return fmt.Errorf("invalid mode: must be 1, 2 or 3")
 	}
 
 	if c.CurrentPlayer < 1 {
@@ -202,13 +274,16 @@ func (c *GameConfig) ValidateConfig() error {
 	}
 
 	// Validate current page
-	validPages := map[string]bool{
-		"lottery1":      true,
-		"lottery2":      true,
-		"advertisement": true,
+	if c.CurrentPage != "" && !IsValidPage(c.CurrentPage) {
+		return fmt.Errorf("invalid current page: must be 'lottery1', 'lottery2', 'lottery3', or 'advertisement'")
 	}
-	if c.CurrentPage != "" && !validPages[c.CurrentPage] {
-		return fmt.Errorf("invalid current page: must be 'lottery1', 'lottery2', or 'advertisement'")
+
+	if err := c.validateTiming(); err != nil {
+		return err
+	}
+
+	if err := c.validateJackpot(); err != nil {
+		return err
 	}
 
 	if c.Mode == 1 {
@@ -238,14 +313,8 @@ func (c *GameConfig) ValidateConfig() error {
 
 // ValidatePageSwitchRequest validates a page switch request
 func (p *PageSwitchRequest) Validate() error {
-	validPages := map[string]bool{
-		"lottery1":      true,
-		"lottery2":      true,
-		"advertisement": true,
-	}
-	
-	if !validPages[p.Page] {
-		return fmt.Errorf("invalid page: must be 'lottery1', 'lottery2', or 'advertisement'")
+	if !IsValidPage(p.Page) {
+		return fmt.Errorf("invalid page: must be 'lottery1', 'lottery2', 'lottery3', or 'advertisement'")
 	}
 	
 	return nil
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Prize Voucher Models
+
+// Voucher states
+const (
+	VoucherStatusIssued   = "issued"
+	VoucherStatusRedeemed = "redeemed"
+	VoucherStatusVoid     = "void"
+)
+
+// Voucher settings and limits
+const (
+	DefaultVoucherValidityDays = 30
+	MaxVoucherValidityDays     = 365
+	MaxVoucherRecords          = 10000 // Beyond this the oldest settled vouchers are forgotten; outstanding ones never are
+	MaxVoidReasonLength        = 200
+)
+
+// VoucherQRPrefix starts the QR payload of every voucher, so scanners can tell it apart
+const VoucherQRPrefix = "SWV1:"
+
+// voucherAlphabet leaves out 0/O, 1/I/L so codes can be read out and typed without mistakes
+const voucherAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// voucherCodeLength gives 31^8 ≈ 8.5×10^11 codes, far too many to guess
+const voucherCodeLength = 8
+
+// Voucher is the proof of a prize won on the wheel, redeemable once before it expires
+type Voucher struct {
+	Code       string      `json:"code"`       // Shown as XXXX-XXXX
+	QRPayload  string      `json:"qr_payload"` // What the printed QR code encodes
+	Prize      string      `json:"prize"`
+	Mode       int         `json:"mode"`
+	PlayerID   string      `json:"player_id,omitempty"`
+	Table      string      `json:"table,omitempty"`
+	Status     string      `json:"status"`
+	Expired    bool        `json:"expired"` // Still issued but past Expires (filled in on read)
+	Issued     time.Time   `json:"issued"`  // Same as the spin's timestamp
+	Expires    time.Time   `json:"expires"`
+	Redeemed   *time.Time  `json:"redeemed,omitempty"`
+	RedeemedBy *AuditActor `json:"redeemed_by,omitempty"`
+	Voided     *time.Time  `json:"voided,omitempty"`
+	VoidedBy   *AuditActor `json:"voided_by,omitempty"`
+	VoidReason string      `json:"void_reason,omitempty"`
+}
+
+// VoucherSettings control how vouchers are issued
+type VoucherSettings struct {
+	ValidityDays int `json:"validity_days"` // How long a voucher can be redeemed
+}
+
+// VoucherBook is the persisted voucher settings and every voucher issued
+type VoucherBook struct {
+	Settings VoucherSettings `json:"settings"`
+	Vouchers []Voucher       `json:"vouchers"`
+}
+
+// VoucherCodeRequest identifies a voucher by its code or scanned QR payload
+type VoucherCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// VoucherVoidRequest voids a voucher, e.g. one handed out by mistake
+type VoucherVoidRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PrizeCount is the number of vouchers outstanding for one prize
+type PrizeCount struct {
+	Prize string `json:"prize"`
+	Count int    `json:"count"`
+}
+
+// OutstandingVoucherReport lists prizes won but not yet collected
+type OutstandingVoucherReport struct {
+	Outstanding int          `json:"outstanding"` // Issued and not expired
+	Expired     int          `json:"expired"`     // Issued but expired without being redeemed
+	ByPrize     []PrizeCount `json:"by_prize"`    // Outstanding vouchers per prize, most first
+	Vouchers    []Voucher    `json:"vouchers"`    // Outstanding vouchers, oldest first
+}
+
+// GetDefaultVoucherBook returns an empty voucher book with the default validity
+func GetDefaultVoucherBook() *VoucherBook {
+	return &VoucherBook{
+		Settings: VoucherSettings{ValidityDays: DefaultVoucherValidityDays},
+		Vouchers: make([]Voucher, 0),
+	}
+}
+
+// Validate checks the voucher settings
+func (s *VoucherSettings) Validate() error {
+	if s.ValidityDays < 1 || s.ValidityDays > MaxVoucherValidityDays {
+		return fmt.Errorf("validity must be between 1 and %d days", MaxVoucherValidityDays)
+	}
+	return nil
+}
+
+// IsWinningResult reports whether a spin result earns a voucher: every mode 1
+// segment is a prize, mode 2 wins on its winning segment and mode 3 on the jackpot
+func IsWinningResult(result *SpinResult) bool {
+	return result.Type == ResultTypePrize || result.Type == ResultTypeJackpot
+}
+
+// NewVoucherCode returns a random voucher code
+func NewVoucherCode() (string, error) {
+	max := big.NewInt(int64(len(voucherAlphabet)))
+	code := make([]byte, voucherCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = voucherAlphabet[n.Int64()]
+	}
+	return FormatVoucherCode(string(code)), nil
+}
+
+// FormatVoucherCode writes a normalized code in its XXXX-XXXX display form
+func FormatVoucherCode(code string) string {
+	if len(code) != voucherCodeLength {
+		return code
+	}
+	return code[:4] + "-" + code[4:]
+}
+
+// NormalizeVoucherCode accepts a typed code in any case, with or without the dash,
+// or a scanned QR payload, and returns it in display form
+func NormalizeVoucherCode(input string) string {
+	code := strings.TrimSpace(input)
+	if len(code) >= len(VoucherQRPrefix) && strings.EqualFold(code[:len(VoucherQRPrefix)], VoucherQRPrefix) {
+		code = code[len(VoucherQRPrefix):]
+	}
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return FormatVoucherCode(code)
+}
+
+// VoucherQRPayload returns the QR payload for a voucher code
+func VoucherQRPayload(code string) string {
+	return VoucherQRPrefix + code
+}
+
+// IsExpired reports whether an issued voucher can no longer be redeemed at now
+func (v *Voucher) IsExpired(now time.Time) bool {
+	return v.Status == VoucherStatusIssued && !now.Before(v.Expires)
+}
+
+// IsSettled reports whether a voucher was redeemed, voided or has expired at now, so it can't be redeemed any more
+func (v *Voucher) IsSettled(now time.Time) bool {
+	return v.Status != VoucherStatusIssued || v.IsExpired(now)
+}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"spinner-wheel/models"
+)
+
+// auditFile is append-only: one JSON entry per line, each chained to the previous one's hash
+const auditFile = "audit.jsonl"
+
+// auditHeadFile records the last entry written. The chain can't show entries cut from its end,
+// so the log is checked against this file, and appends continue from it rather than from the log.
+const auditHeadFile = "audit-head.json"
+
+// auditHead is the last entry of the chain, kept so appends don't rescan the file
+type auditHead struct {
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// AppendAudit numbers, chains and appends an entry to the audit log
+func (s *Storage) AppendAudit(entry models.AuditEntry) (*models.AuditEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry.Seq = s.audit.Seq + 1
+	entry.PrevHash = s.audit.Hash
+	hash, err := entry.ComputeHash()
+	if err != nil {
+		return nil, err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(s.dataDir, auditFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync audit log: %w", err)
+	}
+
+	// The entry is in the log either way; a head left one behind is still consistent with it
+	s.audit = auditHead{Seq: entry.Seq, Hash: entry.Hash}
+	if err := s.writeJSONFileUnsafe(auditHeadFile, s.audit, 0600); err != nil {
+		return &entry, fmt.Errorf("failed to record audit head: %w", err)
+	}
+	return &entry, nil
+}
+
+// QueryAudit returns matching audit entries, newest first
+func (s *Storage) QueryAudit(query models.AuditQuery) (*models.AuditPage, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entries, err := s.readAuditUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	page := &models.AuditPage{
+		Entries: make([]models.AuditEntry, 0, limit),
+		Total:   int64(len(entries)),
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !query.Matches(entries[i]) {
+			continue
+		}
+		if len(page.Entries) == limit {
+			page.Next = page.Entries[limit-1].Seq
+			break
+		}
+		page.Entries = append(page.Entries, entries[i])
+	}
+
+	return page, nil
+}
+
+// VerifyAudit checks sequence numbers, hash links and entry hashes of the whole log,
+// and that it still contains the last entry written
+func (s *Storage) VerifyAudit() (*models.AuditVerification, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	file, err := os.Open(filepath.Join(s.dataDir, auditFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	result := &models.AuditVerification{Valid: true, HeadHash: models.AuditGenesisHash}
+	fail := func(seq int64, format string, args ...interface{}) {
+		result.Valid = false
+		result.BrokenAt = seq
+		result.Error = fmt.Sprintf(format, args...)
+	}
+
+	err = eachAuditLine(file, func(line []byte) error {
+		// Only the first break is reported
+		if !result.Valid {
+			return nil
+		}
+		expectedSeq := result.Entries + 1
+
+		var entry models.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			fail(expectedSeq, "entry %d is not valid JSON: %v", expectedSeq, err)
+			return nil
+		}
+		if entry.Seq != expectedSeq {
+			fail(expectedSeq, "expected entry %d, found %d (entries removed or reordered)", expectedSeq, entry.Seq)
+			return nil
+		}
+		if entry.PrevHash != result.HeadHash {
+			fail(entry.Seq, "entry %d does not link to the previous entry", entry.Seq)
+			return nil
+		}
+		hash, err := entry.ComputeHash()
+		if err != nil {
+			return err
+		}
+		if hash != entry.Hash {
+			fail(entry.Seq, "entry %d has been modified", entry.Seq)
+			return nil
+		}
+
+		if entry.Seq == s.audit.Seq && entry.Hash != s.audit.Hash {
+			fail(entry.Seq, "entry %d is not the entry %d that was written (log rewritten)", entry.Seq, entry.Seq)
+			return nil
+		}
+
+		result.Entries = entry.Seq
+		result.HeadHash = entry.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Valid && result.Entries < s.audit.Seq {
+		fail(result.Entries+1, "log ends at entry %d but %d entries were written (entries removed from the end)", result.Entries, s.audit.Seq)
+	}
+
+	return result, nil
+}
+
+// initializeAudit creates the audit log if it doesn't exist and loads the chain head.
+// The recorded head wins over a shorter log, so entries cut from the end leave a gap
+// before the next one instead of being silently continued from.
+func (s *Storage) initializeAudit() error {
+	s.audit = auditHead{Hash: models.AuditGenesisHash}
+
+	path := filepath.Join(s.dataDir, auditFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			return err
+		}
+	}
+
+	entries, err := s.readAuditUnsafe()
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		s.audit = auditHead{Seq: last.Seq, Hash: last.Hash}
+	}
+
+	var recorded auditHead
+	if err := s.readJSONUnsafe(auditHeadFile, &recorded); err != nil {
+		if _, statErr := os.Stat(filepath.Join(s.dataDir, auditHeadFile)); !os.IsNotExist(statErr) {
+			return err
+		}
+		// Logs from before the head was recorded start trusting their current end
+		return s.writeJSONFileUnsafe(auditHeadFile, s.audit, 0600)
+	}
+	if recorded.Seq > s.audit.Seq {
+		s.audit = recorded
+	}
+	return nil
+}
+
+// readAuditUnsafe reads every parseable audit entry without locking (internal use)
+func (s *Storage) readAuditUnsafe() ([]models.AuditEntry, error) {
+	file, err := os.Open(filepath.Join(s.dataDir, auditFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	entries := make([]models.AuditEntry, 0)
+	err = eachAuditLine(file, func(line []byte) error {
+		var entry models.AuditEntry
+		if err := json.Unmarshal(line, &entry); err == nil {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// eachAuditLine calls fn for every non-empty line; entries can be large (e.g. a reset's history)
+func eachAuditLine(r io.Reader, fn func(line []byte) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if fnErr := fn(line); fnErr != nil {
+				return fnErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+	}
+}
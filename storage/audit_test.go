@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+// writeAuditLog appends n entries to a fresh storage and returns it with the log lines
+func writeAuditLog(t *testing.T, n int) (*Storage, [][]byte) {
+	t.Helper()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		_, err := s.AppendAudit(models.AuditEntry{
+			Time:   time.Date(2026, 1, 1, 12, i, 0, 0, time.UTC),
+			Actor:  models.AuditActor{Username: "admin", IP: "127.0.0.1"},
+			Action: models.AuditConfigUpdate,
+			After:  models.NewAuditValue(map[string]int{"mode": i}),
+		})
+		if err != nil {
+			t.Fatalf("AppendAudit: %v", err)
+		}
+	}
+	return s, readAuditLines(t, s)
+}
+
+func readAuditLines(t *testing.T, s *Storage) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(s.dataDir, auditFile))
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	return bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+}
+
+func writeAuditLines(t *testing.T, s *Storage, lines [][]byte) {
+	t.Helper()
+	data := append(bytes.Join(lines, []byte("\n")), '\n')
+	if err := os.WriteFile(filepath.Join(s.dataDir, auditFile), data, 0600); err != nil {
+		t.Fatalf("write audit log: %v", err)
+	}
+}
+
+// editAuditLine decodes line i, applies edit and encodes it again
+func editAuditLine(t *testing.T, lines [][]byte, i int, edit func(entry *models.AuditEntry)) {
+	t.Helper()
+	var entry models.AuditEntry
+	if err := json.Unmarshal(lines[i], &entry); err != nil {
+		t.Fatalf("decode entry: %v", err)
+	}
+	edit(&entry)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("encode entry: %v", err)
+	}
+	lines[i] = line
+}
+
+func TestVerifyAudit(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(t *testing.T, lines [][]byte) [][]byte
+		valid    bool
+		entries  int64
+		brokenAt int64
+	}{
+		{
+			name:    "untouched log",
+			tamper:  func(t *testing.T, lines [][]byte) [][]byte { return lines },
+			valid:   true,
+			entries: 4,
+		},
+		{
+			name: "reformatted entries",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				var indented bytes.Buffer
+				if err := json.Indent(&indented, lines[1], "", " "); err != nil {
+					t.Fatalf("indent: %v", err)
+				}
+				lines[1] = bytes.ReplaceAll(indented.Bytes(), []byte("\n"), nil)
+				return lines
+			},
+			valid:   true,
+			entries: 4,
+		},
+		{
+			name: "modified entry",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				editAuditLine(t, lines, 2, func(entry *models.AuditEntry) { entry.Actor.Username = "someone-else" })
+				return lines
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "modified entry with recomputed hash",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				editAuditLine(t, lines, 1, func(entry *models.AuditEntry) {
+					entry.Action = models.AuditGameReset
+					entry.Hash, _ = entry.ComputeHash()
+				})
+				return lines
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "removed entry",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				return append(lines[:1], lines[2:]...)
+			},
+			brokenAt: 2,
+		},
+		{
+			name: "reordered entries",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				lines[2], lines[3] = lines[3], lines[2]
+				return lines
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "corrupt line",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				lines[0] = []byte("{not json")
+				return lines
+			},
+			brokenAt: 1,
+		},
+		{
+			name: "truncated log",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				return lines[:2]
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "emptied log",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				return nil
+			},
+			brokenAt: 1,
+		},
+		{
+			name: "tail rewritten with a valid chain",
+			tamper: func(t *testing.T, lines [][]byte) [][]byte {
+				editAuditLine(t, lines, 3, func(entry *models.AuditEntry) {
+					entry.Action = models.AuditGameReset
+					entry.Hash, _ = entry.ComputeHash()
+				})
+				return lines
+			},
+			brokenAt: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, lines := writeAuditLog(t, 4)
+			writeAuditLines(t, s, tt.tamper(t, lines))
+
+			result, err := s.VerifyAudit()
+			if err != nil {
+				t.Fatalf("VerifyAudit: %v", err)
+			}
+			if result.Valid != tt.valid {
+				t.Fatalf("Valid = %v, want %v (%s)", result.Valid, tt.valid, result.Error)
+			}
+			if tt.valid && result.Entries != tt.entries {
+				t.Errorf("Entries = %d, want %d", result.Entries, tt.entries)
+			}
+			if result.BrokenAt != tt.brokenAt {
+				t.Errorf("BrokenAt = %d, want %d", result.BrokenAt, tt.brokenAt)
+			}
+		})
+	}
+}
+
+func TestAppendAuditChainsAcrossRestarts(t *testing.T) {
+	s, _ := writeAuditLog(t, 2)
+
+	// A new instance over the same directory must continue the chain, not restart it
+	reopened, err := New(s.dataDir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	entry, err := reopened.AppendAudit(models.AuditEntry{Action: models.AuditAuthLogin})
+	if err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	if entry.Seq != 3 {
+		t.Errorf("Seq = %d, want 3", entry.Seq)
+	}
+
+	result, err := reopened.VerifyAudit()
+	if err != nil {
+		t.Fatalf("VerifyAudit: %v", err)
+	}
+	if !result.Valid || result.Entries != 3 || result.HeadHash != entry.Hash {
+		t.Errorf("VerifyAudit = %+v, want 3 valid entries ending in %s", result, entry.Hash)
+	}
+}
+
+func TestAppendAuditAfterTruncationLeavesGap(t *testing.T) {
+	s, lines := writeAuditLog(t, 4)
+	writeAuditLines(t, s, lines[:2])
+
+	// A restart must not quietly chain on from the shortened log
+	reopened, err := New(s.dataDir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	entry, err := reopened.AppendAudit(models.AuditEntry{Action: models.AuditAuthLogin})
+	if err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	if entry.Seq != 5 {
+		t.Errorf("Seq = %d, want 5", entry.Seq)
+	}
+
+	result, err := reopened.VerifyAudit()
+	if err != nil {
+		t.Fatalf("VerifyAudit: %v", err)
+	}
+	if result.Valid || result.BrokenAt != 3 {
+		t.Errorf("VerifyAudit = %+v, want broken at 3", result)
+	}
+}
+
+func TestInitializeAuditRecordsHeadOfExistingLog(t *testing.T) {
+	s, lines := writeAuditLog(t, 3)
+
+	// Logs written before the head file existed are trusted as they are
+	if err := os.Remove(filepath.Join(s.dataDir, auditHeadFile)); err != nil {
+		t.Fatalf("remove head: %v", err)
+	}
+	if _, err := New(s.dataDir); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// From then on, cutting the tail is detected
+	writeAuditLines(t, s, lines[:1])
+	reopened, err := New(s.dataDir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	result, err := reopened.VerifyAudit()
+	if err != nil {
+		t.Fatalf("VerifyAudit: %v", err)
+	}
+	if result.Valid || result.BrokenAt != 2 {
+		t.Errorf("VerifyAudit = %+v, want broken at 2", result)
+	}
+}
@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+const authFile = "auth.json"
+
+// HasAdminUsers reports whether first-run setup has been completed
+func (s *Storage) HasAdminUsers() (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return false, err
+	}
+
+	return len(data.Users) > 0, nil
+}
+
+// CreateInitialUser stores the first admin account; it fails once any account exists
+func (s *Storage) CreateInitialUser(user models.AdminUser) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return err
+	}
+
+	if len(data.Users) > 0 {
+		return fmt.Errorf("setup has already been completed")
+	}
+
+	data.Users = append(data.Users, user)
+
+	return s.saveAuthDataUnsafe(data)
+}
+
+// GetAdminUser returns an admin account by username
+func (s *Storage) GetAdminUser(username string) (*models.AdminUser, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range data.Users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user %s not found", username)
+}
+
+// UpdateAdminUser replaces an existing admin account
+func (s *Storage) UpdateAdminUser(username string, updated models.AdminUser) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return err
+	}
+
+	// Find and update user
+	found := false
+	for i, user := range data.Users {
+		if user.Username == username {
+			data.Users[i] = updated
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("user %s not found", username)
+	}
+
+	if countOwners(data.Users) == 0 {
+		return fmt.Errorf("at least one owner account is required")
+	}
+
+	return s.saveAuthDataUnsafe(data)
+}
+
+// ListAdminUsers returns all admin accounts
+func (s *Storage) ListAdminUsers() ([]models.AdminUser, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Users, nil
+}
+
+// AddAdminUser stores a new admin account
+func (s *Storage) AddAdminUser(user models.AdminUser) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range data.Users {
+		if strings.EqualFold(existing.Username, user.Username) {
+			return fmt.Errorf("user %s already exists", user.Username)
+		}
+	}
+
+	data.Users = append(data.Users, user)
+
+	return s.saveAuthDataUnsafe(data)
+}
+
+// DeleteAdminUser removes an admin account and all of its sessions
+func (s *Storage) DeleteAdminUser(username string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return err
+	}
+
+	// Find and remove user
+	found := false
+	for i, user := range data.Users {
+		if user.Username == username {
+			data.Users = append(data.Users[:i], data.Users[i+1:]...)
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("user %s not found", username)
+	}
+
+	if countOwners(data.Users) == 0 {
+		return fmt.Errorf("cannot delete the last owner account")
+	}
+
+	remaining := make([]models.Session, 0, len(data.Sessions))
+	for _, session := range data.Sessions {
+		if session.Username != username {
+			remaining = append(remaining, session)
+		}
+	}
+	data.Sessions = remaining
+
+	return s.saveAuthDataUnsafe(data)
+}
+
+// CreateSession stores a new admin session, pruning expired ones
+func (s *Storage) CreateSession(session models.Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return err
+	}
+
+	data.Sessions = append(pruneSessions(data.Sessions, time.Now()), session)
+
+	return s.saveAuthDataUnsafe(data)
+}
+
+// GetSession returns the live session for a token hash and records its use
+func (s *Storage) GetSession(tokenHash string) (*models.Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i, session := range data.Sessions {
+		if session.TokenHash != tokenHash {
+			continue
+		}
+		if !now.Before(session.Expires) {
+			return nil, fmt.Errorf("session expired")
+		}
+
+		// Only write last-used occasionally to keep request overhead low
+		if now.Sub(session.LastUsed) > time.Minute {
+			data.Sessions[i].LastUsed = now
+			if err := s.saveAuthDataUnsafe(data); err != nil {
+				return nil, err
+			}
+		}
+		return &data.Sessions[i], nil
+	}
+
+	return nil, fmt.Errorf("session not found")
+}
+
+// DeleteSession removes a session (logout)
+func (s *Storage) DeleteSession(tokenHash string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return err
+	}
+
+	for i, session := range data.Sessions {
+		if session.TokenHash == tokenHash {
+			data.Sessions = append(data.Sessions[:i], data.Sessions[i+1:]...)
+			return s.saveAuthDataUnsafe(data)
+		}
+	}
+
+	return nil
+}
+
+// DeleteUserSessions removes all sessions of a user except the given token hash
+func (s *Storage) DeleteUserSessions(username string, keepTokenHash string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getAuthDataUnsafe()
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]models.Session, 0, len(data.Sessions))
+	for _, session := range data.Sessions {
+		if session.Username != username || session.TokenHash == keepTokenHash {
+			remaining = append(remaining, session)
+		}
+	}
+	data.Sessions = remaining
+
+	return s.saveAuthDataUnsafe(data)
+}
+
+// countOwners returns how many accounts have the owner role
+func countOwners(users []models.AdminUser) int {
+	owners := 0
+	for _, user := range users {
+		if user.Role == models.RoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
+
+// pruneSessions drops expired sessions
+func pruneSessions(sessions []models.Session, now time.Time) []models.Session {
+	live := make([]models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if now.Before(session.Expires) {
+			live = append(live, session)
+		}
+	}
+	return live
+}
+
+// initializeAuth creates an empty credentials file if it doesn't exist
+func (s *Storage) initializeAuth() error {
+	return s.initializeJSONFile(authFile, &models.AuthData{
+		Users:    make([]models.AdminUser, 0),
+		Sessions: make([]models.Session, 0),
+	}, 0600)
+}
+
+// getAuthDataUnsafe reads credentials and sessions without locking (internal use)
+func (s *Storage) getAuthDataUnsafe() (*models.AuthData, error) {
+	var data models.AuthData
+	if err := s.readJSONUnsafe(authFile, &data); err != nil {
+		return nil, err
+	}
+
+	// Accounts created before roles existed belong to the owner
+	for i := range data.Users {
+		if data.Users[i].Role == "" {
+			data.Users[i].Role = models.RoleOwner
+		}
+	}
+	return &data, nil
+}
+
+// saveAuthDataUnsafe writes credentials and sessions, readable by the owner only (internal use)
+func (s *Storage) saveAuthDataUnsafe(data *models.AuthData) error {
+	return s.writeJSONFileUnsafe(authFile, data, 0600)
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"spinner-wheel/models"
+)
+
+const billsFile = "bills.json"
+
+// ErrDuplicateBill reports that a bill ID was already processed
+var ErrDuplicateBill = errors.New("bill was already processed")
+
+// GetBillLedger returns the bill rules and the processed bills, newest last
+func (s *Storage) GetBillLedger() (*models.BillLedger, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.getBillLedgerUnsafe()
+}
+
+// UpdateBillRules replaces the bill rules after check approves the current ones, returning the rules before
+func (s *Storage) UpdateBillRules(rules models.BillRules, check func(current models.BillRules) error) (models.BillRules, error) {
+	if err := rules.Validate(); err != nil {
+		return models.BillRules{}, fmt.Errorf("invalid bill rules: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ledger, err := s.getBillLedgerUnsafe()
+	if err != nil {
+		return models.BillRules{}, err
+	}
+
+	before := ledger.Rules
+	if err := check(before); err != nil {
+		return before, err
+	}
+
+	ledger.Rules = rules
+	return before, s.writeJSONUnsafe(billsFile, ledger)
+}
+
+// RecordBill records a bill and adds its spins to a player's allowance in one step.
+// The spins go to bill.PlayerID if set, otherwise to the most recently registered
+// player at the bill's table, who is registered under newPlayerID if there is none.
+// A bill ID seen before, however long ago, returns the original bill with ErrDuplicateBill.
+// If either file can't be written, both are put back and a *PartialWriteError is returned.
+func (s *Storage) RecordBill(bill models.Bill, newPlayerID string) (models.Bill, *models.Player, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ledger, err := s.getBillLedgerUnsafe()
+	if err != nil {
+		return bill, nil, err
+	}
+	if existing, seen := ledger.Seen(bill.ID); seen {
+		return existing, nil, ErrDuplicateBill
+	}
+
+	// The bill goes first: should the restore fail too, a bill recorded without its
+	// spins is better than spins granted for a bill that can be posted again
+	writes := []fileWrite{{billsFile, func() error { return s.writeJSONUnsafe(billsFile, ledger) }}}
+
+	var player *models.Player
+	if bill.SpinsGranted > 0 {
+		registry, err := s.getPlayersUnsafe()
+		if err != nil {
+			return bill, nil, err
+		}
+
+		index := -1
+		for i, candidate := range registry.Players {
+			if bill.PlayerID != "" {
+				if candidate.ID == bill.PlayerID {
+					index = i
+					break
+				}
+			} else if candidate.Table == bill.Table && (index < 0 || !candidate.Created.Before(registry.Players[index].Created)) {
+				index = i
+			}
+		}
+		if index < 0 {
+			if bill.PlayerID != "" {
+				return bill, nil, fmt.Errorf("player with ID %s not found", bill.PlayerID)
+			}
+			registry.Players = append(registry.Players, models.Player{
+				ID:      newPlayerID,
+				Table:   bill.Table,
+				Created: bill.Received,
+			})
+			index = len(registry.Players) - 1
+		}
+
+		updated := registry.Players[index]
+		updated.SpinsGranted += bill.SpinsGranted
+		updated.Updated = bill.Received
+		if err := updated.Validate(); err != nil {
+			return bill, nil, fmt.Errorf("invalid player: %w", err)
+		}
+		registry.Players[index] = updated
+		writes = append(writes, fileWrite{playersFile, func() error { return s.writeJSONUnsafe(playersFile, registry) }})
+
+		bill.PlayerID = updated.ID
+		bill.Table = updated.Table
+		player = &updated
+	}
+
+	ledger.Add(bill)
+	if err := s.writeAllUnsafe(writes); err != nil {
+		return bill, nil, err
+	}
+
+	return bill, player, nil
+}
+
+// initializeBills creates a bill ledger with the default rules if it doesn't exist
+func (s *Storage) initializeBills() error {
+	return s.initializeJSON(billsFile, models.GetDefaultBillLedger())
+}
+
+// getBillLedgerUnsafe reads the bill ledger without locking (internal use)
+func (s *Storage) getBillLedgerUnsafe() (*models.BillLedger, error) {
+	var ledger models.BillLedger
+	if err := s.readJSONUnsafe(billsFile, &ledger); err != nil {
+		return nil, err
+	}
+	if ledger.Bills == nil {
+		ledger.Bills = make([]models.Bill, 0)
+	}
+	return &ledger, nil
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+func TestRecordBill(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	start := time.Date(2026, 1, 1, 19, 0, 0, 0, time.UTC)
+
+	// Steps run in order against the same ledger
+	steps := []struct {
+		name        string
+		bill        models.Bill
+		newPlayerID string
+		wantErr     string // Expected error message, "" for success
+		wantPlayer  string // Player credited, "" if none
+		wantSpins   int    // That player's allowance afterwards
+	}{
+		{
+			name:        "first bill creates the table's player",
+			bill:        models.Bill{ID: "B-1", Table: "A3", Amount: 200, SpinsGranted: 2},
+			newPlayerID: "p1",
+			wantPlayer:  "p1",
+			wantSpins:   2,
+		},
+		{
+			name:        "second bill adds to the same player",
+			bill:        models.Bill{ID: "B-2", Table: "A3", Amount: 100, SpinsGranted: 1},
+			newPlayerID: "unused",
+			wantPlayer:  "p1",
+			wantSpins:   3,
+		},
+		{
+			name:        "repeated bill ID is refused",
+			bill:        models.Bill{ID: "B-1", Table: "A3", Amount: 200, SpinsGranted: 2},
+			newPlayerID: "unused",
+			wantErr:     ErrDuplicateBill.Error(),
+		},
+		{
+			name:        "repeated bill ID for another table is refused",
+			bill:        models.Bill{ID: "B-2", Table: "C1", Amount: 900, SpinsGranted: 9},
+			newPlayerID: "unused",
+			wantErr:     ErrDuplicateBill.Error(),
+		},
+		{
+			name:        "bill earning nothing is recorded without a player",
+			bill:        models.Bill{ID: "B-3", Table: "B7", Amount: 50},
+			newPlayerID: "unused",
+		},
+		{
+			name:        "bill earning nothing can't be replayed for spins",
+			bill:        models.Bill{ID: "B-3", Table: "B7", Amount: 500, SpinsGranted: 5},
+			newPlayerID: "unused",
+			wantErr:     ErrDuplicateBill.Error(),
+		},
+		{
+			name:        "bill for an unknown player fails",
+			bill:        models.Bill{ID: "B-4", PlayerID: "missing", Amount: 100, SpinsGranted: 1},
+			newPlayerID: "unused",
+			wantErr:     "player with ID missing not found",
+		},
+		{
+			name:        "failed bill can be posted again",
+			bill:        models.Bill{ID: "B-4", PlayerID: "p1", Amount: 100, SpinsGranted: 1},
+			newPlayerID: "unused",
+			wantPlayer:  "p1",
+			wantSpins:   4,
+		},
+	}
+
+	for i, step := range steps {
+		ok := t.Run(step.name, func(t *testing.T) {
+			step.bill.Received = start.Add(time.Duration(i) * time.Minute)
+			bill, player, err := s.RecordBill(step.bill, step.newPlayerID)
+
+			if step.wantErr != "" {
+				if err == nil || err.Error() != step.wantErr {
+					t.Fatalf("err = %v, want %q", err, step.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecordBill: %v", err)
+			}
+
+			if step.wantPlayer == "" {
+				if player != nil || bill.PlayerID != "" {
+					t.Errorf("credited %+v, want no player", player)
+				}
+				return
+			}
+			if player == nil || player.ID != step.wantPlayer || bill.PlayerID != step.wantPlayer {
+				t.Fatalf("credited %+v (bill %q), want %s", player, bill.PlayerID, step.wantPlayer)
+			}
+			stored, err := s.GetPlayer(step.wantPlayer)
+			if err != nil {
+				t.Fatalf("GetPlayer: %v", err)
+			}
+			if stored.SpinsGranted != step.wantSpins {
+				t.Errorf("SpinsGranted = %d, want %d", stored.SpinsGranted, step.wantSpins)
+			}
+		})
+		if !ok {
+			t.FailNow()
+		}
+	}
+
+	ledger, err := s.GetBillLedger()
+	if err != nil {
+		t.Fatalf("GetBillLedger: %v", err)
+	}
+	if len(ledger.Bills) != 4 {
+		t.Errorf("ledger has %d bills, want 4", len(ledger.Bills))
+	}
+}
+
+func TestRecordBillRefusesTrimmedIDs(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	start := time.Date(2026, 1, 1, 19, 0, 0, 0, time.UTC)
+
+	// Fill the ledger to its limit directly; posting that many bills one by one is slow
+	ledger, err := s.GetBillLedger()
+	if err != nil {
+		t.Fatalf("GetBillLedger: %v", err)
+	}
+	for i := 0; i < models.MaxBillRecords; i++ {
+		ledger.Bills = append(ledger.Bills, models.Bill{ID: fmt.Sprintf("B-%d", i), Table: "A3", Amount: 50, Received: start})
+	}
+	if err := s.writeJSONUnsafe(billsFile, ledger); err != nil {
+		t.Fatalf("write ledger: %v", err)
+	}
+
+	// One more bill pushes the oldest out of the ledger
+	if _, _, err := s.RecordBill(models.Bill{ID: "B-new", Table: "A3", Amount: 50, Received: start}, "unused"); err != nil {
+		t.Fatalf("RecordBill: %v", err)
+	}
+	ledger, err = s.GetBillLedger()
+	if err != nil {
+		t.Fatalf("GetBillLedger: %v", err)
+	}
+	if len(ledger.Bills) != models.MaxBillRecords || ledger.Bills[0].ID != "B-1" {
+		t.Fatalf("ledger holds %d bills from %s, want %d from B-1", len(ledger.Bills), ledger.Bills[0].ID, models.MaxBillRecords)
+	}
+
+	// The trimmed bill can't be replayed for spins
+	replay := models.Bill{ID: "B-0", Table: "A3", Amount: 500, SpinsGranted: 5, Received: start}
+	if _, _, err := s.RecordBill(replay, "unused"); !errors.Is(err, ErrDuplicateBill) {
+		t.Fatalf("err = %v, want %v", err, ErrDuplicateBill)
+	}
+}
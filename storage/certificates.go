@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	certDir      = "tls"
+	certFile     = "cert.pem"
+	keyFile      = "key.pem"
+	certValidity = 825 * 24 * time.Hour // Longest validity browsers accept for TLS certificates
+	certRenewal  = 30 * 24 * time.Hour  // Regenerate this long before expiry
+)
+
+// EnsureSelfSignedCertificate returns a self-signed certificate in the data directory covering hosts,
+// generating a new one if none exists, it is about to expire, or a host is not covered
+// (e.g. the LAN address changed).
+func (s *Storage) EnsureSelfSignedCertificate(hosts []string) (certPath string, keyPath string, err error) {
+	dir := filepath.Join(s.dataDir, certDir)
+	certPath = filepath.Join(dir, certFile)
+	keyPath = filepath.Join(dir, keyFile)
+
+	if existing, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		if leaf, err := x509.ParseCertificate(existing.Certificate[0]); err == nil && certificateCovers(leaf, hosts) {
+			return certPath, keyPath, nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+
+	certPEM, keyPEM, err := generateSelfSignedCertificate(hosts)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return "", "", fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write certificate: %w", err)
+	}
+
+	return certPath, keyPath, nil
+}
+
+// certificateCovers reports whether a certificate is still valid for a while and names every host
+func certificateCovers(cert *x509.Certificate, hosts []string) bool {
+	if time.Until(cert.NotAfter) < certRenewal {
+		return false
+	}
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// generateSelfSignedCertificate creates an ECDSA P-256 certificate and key in PEM form
+func generateSelfSignedCertificate(hosts []string) (certPEM []byte, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Spinner Wheel"}, CommonName: "Spinner Wheel (self-signed)"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEnsureSelfSignedCertificate(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// Steps run in order against the same data directory
+	steps := []struct {
+		name      string
+		hosts     []string
+		wantReuse bool // Whether the previous certificate is kept
+	}{
+		{name: "first start generates a certificate", hosts: []string{"localhost", "192.168.1.20"}},
+		{name: "restart keeps it", hosts: []string{"localhost", "192.168.1.20"}, wantReuse: true},
+		{name: "fewer hosts keep it", hosts: []string{"192.168.1.20"}, wantReuse: true},
+		{name: "new LAN address replaces it", hosts: []string{"localhost", "192.168.1.35"}},
+	}
+
+	var previous []byte
+	for _, step := range steps {
+		certPath, keyPath, err := s.EnsureSelfSignedCertificate(step.hosts)
+		if err != nil {
+			t.Fatalf("%s: EnsureSelfSignedCertificate: %v", step.name, err)
+		}
+		pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			t.Fatalf("%s: LoadX509KeyPair: %v", step.name, err)
+		}
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			t.Fatalf("%s: ParseCertificate: %v", step.name, err)
+		}
+		for _, host := range step.hosts {
+			if err := leaf.VerifyHostname(host); err != nil {
+				t.Errorf("%s: %v", step.name, err)
+			}
+		}
+		if info, err := os.Stat(keyPath); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("%s: key file mode = %v (%v), want 0600", step.name, info.Mode().Perm(), err)
+		}
+
+		if reused := bytes.Equal(pair.Certificate[0], previous); reused != step.wantReuse {
+			t.Errorf("%s: reused = %v, want %v", step.name, reused, step.wantReuse)
+		}
+		previous = pair.Certificate[0]
+	}
+}
+
+func TestCertificateCovers(t *testing.T) {
+	now := time.Now()
+	cert := func(notAfter time.Time) *x509.Certificate {
+		return &x509.Certificate{
+			NotAfter:    notAfter,
+			DNSNames:    []string{"localhost"},
+			IPAddresses: []net.IP{net.ParseIP("192.168.1.20")},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		cert  *x509.Certificate
+		hosts []string
+		want  bool
+	}{
+		{name: "all hosts", cert: cert(now.Add(certValidity)), hosts: []string{"localhost", "192.168.1.20"}, want: true},
+		{name: "no hosts", cert: cert(now.Add(certValidity)), want: true},
+		{name: "missing address", cert: cert(now.Add(certValidity)), hosts: []string{"192.168.1.21"}, want: false},
+		{name: "missing name", cert: cert(now.Add(certValidity)), hosts: []string{"wheel.local"}, want: false},
+		{name: "expires after the renewal window", cert: cert(now.Add(certRenewal + time.Hour)), hosts: []string{"localhost"}, want: true},
+		{name: "expires within the renewal window", cert: cert(now.Add(certRenewal - time.Hour)), hosts: []string{"localhost"}, want: false},
+		{name: "expired", cert: cert(now.Add(-time.Hour)), hosts: []string{"localhost"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certificateCovers(tt.cert, tt.hosts); got != tt.want {
+				t.Errorf("certificateCovers(%v) = %v, want %v", tt.hosts, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+const devicesFile = "devices.json"
+
+// ErrDeviceNotFound reports a device ID that isn't registered
+var ErrDeviceNotFound = errors.New("device not found")
+
+// GetDevices returns all registered display devices
+func (s *Storage) GetDevices() ([]models.Device, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	registry, err := s.getDevicesUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.Devices, nil
+}
+
+// GetDevice returns a single display device
+func (s *Storage) GetDevice(deviceID string) (*models.Device, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	registry, err := s.getDevicesUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, device := range registry.Devices {
+		if device.ID == deviceID {
+			return &device, nil
+		}
+	}
+
+	return nil, fmt.Errorf("device with ID %s not found", deviceID)
+}
+
+// AddDevice registers a new display device
+func (s *Storage) AddDevice(device models.Device) error {
+	if err := device.Validate(); err != nil {
+		return fmt.Errorf("invalid device: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getDevicesUnsafe()
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range registry.Devices {
+		if existing.ID == device.ID {
+			return fmt.Errorf("device with ID %s already exists", device.ID)
+		}
+	}
+
+	registry.Devices = append(registry.Devices, device)
+
+	return s.writeJSONUnsafe(devicesFile, registry)
+}
+
+// UpdateDevice applies update to a display device under the storage lock and saves it,
+// returning the device before and after. Nothing is saved if update fails.
+func (s *Storage) UpdateDevice(deviceID string, update func(device *models.Device) error) (before, after models.Device, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getDevicesUnsafe()
+	if err != nil {
+		return before, after, err
+	}
+
+	for i := range registry.Devices {
+		if registry.Devices[i].ID != deviceID {
+			continue
+		}
+		before = registry.Devices[i]
+		after = before
+		if err := update(&after); err != nil {
+			return before, before, err
+		}
+		if err := after.Validate(); err != nil {
+			return before, before, fmt.Errorf("invalid device: %w", err)
+		}
+		registry.Devices[i] = after
+		if err := s.writeJSONUnsafe(devicesFile, registry); err != nil {
+			return before, before, err
+		}
+		return before, after, nil
+	}
+
+	return before, after, ErrDeviceNotFound
+}
+
+// DeleteDevice removes a display device from the registry
+func (s *Storage) DeleteDevice(deviceID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getDevicesUnsafe()
+	if err != nil {
+		return err
+	}
+
+	for i, device := range registry.Devices {
+		if device.ID == deviceID {
+			registry.Devices = append(registry.Devices[:i], registry.Devices[i+1:]...)
+			return s.writeJSONUnsafe(devicesFile, registry)
+		}
+	}
+
+	return fmt.Errorf("device with ID %s not found", deviceID)
+}
+
+// RecordDevicesSeen saves last-seen times for registered devices in one write.
+// Unknown IDs are ignored and older times never replace newer ones.
+func (s *Storage) RecordDevicesSeen(seen map[string]time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getDevicesUnsafe()
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for i, device := range registry.Devices {
+		if at, ok := seen[device.ID]; ok && at.After(device.LastSeen) {
+			registry.Devices[i].LastSeen = at
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	return s.writeJSONUnsafe(devicesFile, registry)
+}
+
+// initializeDevices creates an empty device registry if it doesn't exist
+func (s *Storage) initializeDevices() error {
+	return s.initializeJSON(devicesFile, &models.DeviceRegistry{Devices: make([]models.Device, 0)})
+}
+
+// getDevicesUnsafe reads the device registry without locking (internal use)
+func (s *Storage) getDevicesUnsafe() (*models.DeviceRegistry, error) {
+	var registry models.DeviceRegistry
+	if err := s.readJSONUnsafe(devicesFile, &registry); err != nil {
+		return nil, err
+	}
+	return &registry, nil
+}
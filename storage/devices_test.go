@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+func newDeviceTest(t *testing.T) *Storage {
+	t.Helper()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := s.AddDevice(models.Device{ID: "tv1", Name: "大电视", Kind: "tv", Created: time.Now()}); err != nil {
+		t.Fatalf("AddDevice: %v", err)
+	}
+	return s
+}
+
+func TestUpdateDevice(t *testing.T) {
+	tests := []struct {
+		name     string
+		deviceID string
+		update   func(device *models.Device) error
+		wantErr  string // Part of the expected error, "" for success
+		wantName string // Stored name afterwards
+	}{
+		{
+			name:     "rename",
+			deviceID: "tv1",
+			update:   func(device *models.Device) error { device.Name = "吧台"; return nil },
+			wantName: "吧台",
+		},
+		{
+			name:     "update refuses",
+			deviceID: "tv1",
+			update:   func(device *models.Device) error { device.Name = "吧台"; return errors.New("refused") },
+			wantErr:  "refused",
+			wantName: "大电视",
+		},
+		{
+			name:     "invalid result",
+			deviceID: "tv1",
+			update:   func(device *models.Device) error { device.Kind = "toaster"; return nil },
+			wantErr:  "invalid device",
+			wantName: "大电视",
+		},
+		{
+			name:     "unknown device",
+			deviceID: "missing",
+			update:   func(device *models.Device) error { return nil },
+			wantErr:  ErrDeviceNotFound.Error(),
+			wantName: "大电视",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newDeviceTest(t)
+
+			before, after, err := s.UpdateDevice(tt.deviceID, tt.update)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("UpdateDevice: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if err == nil && (before.Name != "大电视" || after.Name != tt.wantName) {
+				t.Errorf("before/after = %q/%q, want 大电视/%q", before.Name, after.Name, tt.wantName)
+			}
+
+			stored, err := s.GetDevice("tv1")
+			if err != nil {
+				t.Fatalf("GetDevice: %v", err)
+			}
+			if stored.Name != tt.wantName {
+				t.Errorf("stored name = %q, want %q", stored.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestUpdateDeviceConcurrentEditsKeepEachOther(t *testing.T) {
+	s := newDeviceTest(t)
+
+	// One admin renames the screen while another pins its page; neither change may be lost
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, _, err := s.UpdateDevice("tv1", func(device *models.Device) error {
+				device.Name = "吧台"
+				return nil
+			}); err != nil {
+				t.Errorf("rename: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, _, err := s.UpdateDevice("tv1", func(device *models.Device) error {
+				device.AssignedPage = "advertisement"
+				return nil
+			}); err != nil {
+				t.Errorf("pin: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stored, err := s.GetDevice("tv1")
+	if err != nil {
+		t.Fatalf("GetDevice: %v", err)
+	}
+	if stored.Name != "吧台" || stored.AssignedPage != "advertisement" {
+		t.Errorf("device = %+v, want both edits", stored)
+	}
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"spinner-wheel/models"
+)
+
+const jackpotFile = "jackpot.json"
+
+// GetJackpot returns the progressive jackpot pool
+func (s *Storage) GetJackpot() (*models.Jackpot, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var jackpot models.Jackpot
+	if err := s.readJSONUnsafe(jackpotFile, &jackpot); err != nil {
+		return nil, err
+	}
+	return &jackpot, nil
+}
+
+// SaveJackpot replaces the progressive jackpot pool
+func (s *Storage) SaveJackpot(jackpot *models.Jackpot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.writeJSONUnsafe(jackpotFile, jackpot)
+}
+
+// initializeJackpot creates the jackpot pool at the default seed if it doesn't exist
+func (s *Storage) initializeJackpot() error {
+	return s.initializeJSON(jackpotFile, models.GetDefaultJackpot())
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+const playersFile = "players.json"
+
+// ErrNoSpinsRemaining reports that a player has used up their spin allowance
+var ErrNoSpinsRemaining = errors.New("player has no spins remaining")
+
+// GetPlayers returns all registered players
+func (s *Storage) GetPlayers() ([]models.Player, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	registry, err := s.getPlayersUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.Players, nil
+}
+
+// GetPlayer returns a single player
+func (s *Storage) GetPlayer(playerID string) (*models.Player, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	registry, err := s.getPlayersUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, player := range registry.Players {
+		if player.ID == playerID {
+			return &player, nil
+		}
+	}
+
+	return nil, fmt.Errorf("player with ID %s not found", playerID)
+}
+
+// AddPlayer registers a new player
+func (s *Storage) AddPlayer(player models.Player) error {
+	if err := player.Validate(); err != nil {
+		return fmt.Errorf("invalid player: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getPlayersUnsafe()
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range registry.Players {
+		if existing.ID == player.ID {
+			return fmt.Errorf("player with ID %s already exists", player.ID)
+		}
+	}
+
+	registry.Players = append(registry.Players, player)
+
+	return s.writeJSONUnsafe(playersFile, registry)
+}
+
+// UpdatePlayer applies update to a player under the storage lock, so spins taken
+// in the meantime are never overwritten, and returns the player before and after
+func (s *Storage) UpdatePlayer(playerID string, update func(*models.Player)) (before, after models.Player, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getPlayersUnsafe()
+	if err != nil {
+		return before, after, err
+	}
+
+	for i, player := range registry.Players {
+		if player.ID != playerID {
+			continue
+		}
+		before = player
+		update(&player)
+		player.Updated = time.Now()
+		if err := player.Validate(); err != nil {
+			return before, before, fmt.Errorf("invalid player: %w", err)
+		}
+		registry.Players[i] = player
+		if err := s.writeJSONUnsafe(playersFile, registry); err != nil {
+			return before, before, err
+		}
+		return before, player, nil
+	}
+
+	return before, after, fmt.Errorf("player with ID %s not found", playerID)
+}
+
+// DeletePlayer removes a player from the registry
+func (s *Storage) DeletePlayer(playerID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getPlayersUnsafe()
+	if err != nil {
+		return err
+	}
+
+	for i, player := range registry.Players {
+		if player.ID == playerID {
+			registry.Players = append(registry.Players[:i], registry.Players[i+1:]...)
+			return s.writeJSONUnsafe(playersFile, registry)
+		}
+	}
+
+	return fmt.Errorf("player with ID %s not found", playerID)
+}
+
+// takePlayerSpinUnsafe returns the registry with one spin taken from a player's allowance,
+// for the caller to write, without locking (internal use)
+func (s *Storage) takePlayerSpinUnsafe(playerID string, at time.Time) (*models.PlayerRegistry, error) {
+	registry, err := s.getPlayersUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range registry.Players {
+		if registry.Players[i].ID != playerID {
+			continue
+		}
+		if registry.Players[i].RemainingSpins() <= 0 {
+			return nil, ErrNoSpinsRemaining
+		}
+		registry.Players[i].SpinsUsed++
+		registry.Players[i].Updated = at
+		return registry, nil
+	}
+
+	return nil, fmt.Errorf("player with ID %s not found", playerID)
+}
+
+// initializePlayers creates an empty player registry if it doesn't exist
+func (s *Storage) initializePlayers() error {
+	return s.initializeJSON(playersFile, &models.PlayerRegistry{Players: make([]models.Player, 0)})
+}
+
+// getPlayersUnsafe reads the player registry without locking (internal use)
+func (s *Storage) getPlayersUnsafe() (*models.PlayerRegistry, error) {
+	var registry models.PlayerRegistry
+	if err := s.readJSONUnsafe(playersFile, &registry); err != nil {
+		return nil, err
+	}
+	if registry.Players == nil {
+		registry.Players = make([]models.Player, 0)
+	}
+	return &registry, nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"spinner-wheel/models"
+)
+
+const queueFile = "queue.json"
+
+// GetQueue returns the players waiting to spin, next up first
+func (s *Storage) GetQueue() (*models.PlayerQueue, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.getQueueUnsafe()
+}
+
+// UpdateQueue applies update to the queue under the storage lock and saves it,
+// returning the queue after the change. Nothing is saved if update fails.
+func (s *Storage) UpdateQueue(update func(queue *models.PlayerQueue) error) (*models.PlayerQueue, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	queue, err := s.getQueueUnsafe()
+	if err != nil {
+		return nil, err
+	}
+	if err := update(queue); err != nil {
+		return nil, err
+	}
+	if err := s.writeJSONUnsafe(queueFile, queue); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+// initializeQueue creates an empty queue if it doesn't exist
+func (s *Storage) initializeQueue() error {
+	return s.initializeJSON(queueFile, &models.PlayerQueue{Entries: make([]models.QueueEntry, 0)})
+}
+
+// getQueueUnsafe reads the queue without locking (internal use)
+func (s *Storage) getQueueUnsafe() (*models.PlayerQueue, error) {
+	var queue models.PlayerQueue
+	if err := s.readJSONUnsafe(queueFile, &queue); err != nil {
+		return nil, err
+	}
+	if queue.Entries == nil {
+		queue.Entries = make([]models.QueueEntry, 0)
+	}
+	return &queue, nil
+}
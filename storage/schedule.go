@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"spinner-wheel/models"
+)
+
+const scheduleFile = "schedule.json"
+
+// GetDisplaySchedule returns the display schedule and any manual override in force
+func (s *Storage) GetDisplaySchedule() (*models.DisplayScheduleData, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var data models.DisplayScheduleData
+	if err := s.readJSONUnsafe(scheduleFile, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// SaveDisplaySchedule replaces the display schedule and its override
+func (s *Storage) SaveDisplaySchedule(data *models.DisplayScheduleData) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.writeJSONUnsafe(scheduleFile, data)
+}
+
+// initializeSchedule creates a disabled display schedule file if it doesn't exist
+func (s *Storage) initializeSchedule() error {
+	return s.initializeJSON(scheduleFile, models.GetDefaultDisplaySchedule())
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+
+	"spinner-wheel/models"
+)
+
+const spinLinksFile = "spinlinks.json"
+
+// ErrSpinLinkNotFound reports a link ID that was never issued
+var ErrSpinLinkNotFound = errors.New("spin link not found")
+
+// GetSpinLinkBook returns the signing secret and every spin link, oldest first
+func (s *Storage) GetSpinLinkBook() (*models.SpinLinkBook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.getSpinLinkBookUnsafe()
+}
+
+// AddSpinLink records a new spin link, forgetting the oldest beyond the record limit
+func (s *Storage) AddSpinLink(link models.SpinLink) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	book, err := s.getSpinLinkBookUnsafe()
+	if err != nil {
+		return err
+	}
+
+	book.Links = append(book.Links, link)
+	if len(book.Links) > models.MaxSpinLinkRecords {
+		book.Links = book.Links[len(book.Links)-models.MaxSpinLinkRecords:]
+	}
+	return s.saveSpinLinkBookUnsafe(book)
+}
+
+// UpdateSpinLink applies update to a spin link under the storage lock and saves it,
+// returning the link before and after. Nothing is saved if update fails.
+func (s *Storage) UpdateSpinLink(id string, update func(link *models.SpinLink) error) (before, after models.SpinLink, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	book, err := s.getSpinLinkBookUnsafe()
+	if err != nil {
+		return before, after, err
+	}
+	for i := range book.Links {
+		if book.Links[i].ID != id {
+			continue
+		}
+		before = book.Links[i]
+		after = before
+		if err := update(&after); err != nil {
+			return before, before, err
+		}
+		book.Links[i] = after
+		if err := s.saveSpinLinkBookUnsafe(book); err != nil {
+			return before, before, err
+		}
+		return before, after, nil
+	}
+	return before, after, ErrSpinLinkNotFound
+}
+
+// initializeSpinLinks creates the spin link book with a new signing secret if it doesn't exist
+func (s *Storage) initializeSpinLinks() error {
+	secret, err := models.NewSpinLinkSecret()
+	if err != nil {
+		return err
+	}
+	return s.initializeJSONFile(spinLinksFile, &models.SpinLinkBook{
+		Secret: secret,
+		Links:  make([]models.SpinLink, 0),
+	}, 0600)
+}
+
+// getSpinLinkBookUnsafe reads the spin link book without locking (internal use)
+func (s *Storage) getSpinLinkBookUnsafe() (*models.SpinLinkBook, error) {
+	var book models.SpinLinkBook
+	if err := s.readJSONUnsafe(spinLinksFile, &book); err != nil {
+		return nil, err
+	}
+	if book.Secret == "" {
+		return nil, errors.New("spin link signing secret is missing")
+	}
+	if book.Links == nil {
+		book.Links = make([]models.SpinLink, 0)
+	}
+	return &book, nil
+}
+
+// saveSpinLinkBookUnsafe writes the spin link book, readable by the owner only since it holds the secret (internal use)
+func (s *Storage) saveSpinLinkBookUnsafe(book *models.SpinLinkBook) error {
+	return s.writeJSONFileUnsafe(spinLinksFile, book, 0600)
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"spinner-wheel/models"
+)
+
+const spinLockFile = "spinlock.json"
+
+// GetSpinLock returns the persisted spin lock state
+func (s *Storage) GetSpinLock() (*models.SpinLock, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var lock models.SpinLock
+	if err := s.readJSONUnsafe(spinLockFile, &lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// SaveSpinLock persists the spin lock state
+func (s *Storage) SaveSpinLock(lock models.SpinLock) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.writeJSONUnsafe(spinLockFile, &lock)
+}
+
+// initializeSpinLock creates an unlocked spin lock file if it doesn't exist
+func (s *Storage) initializeSpinLock() error {
+	return s.initializeJSON(spinLockFile, &models.SpinLock{})
+}
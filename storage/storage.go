@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 type Storage struct {
 	dataDir string
 	mutex   sync.RWMutex
+	audit   auditHead // Last audit log entry
 }
 
 // New creates a new storage instance
@@ -44,6 +46,71 @@ func New(dataDir string) (*Storage, error) {
 		return nil, fmt.Errorf("failed to initialize restaurant data: %w", err)
 	}
 
+	// Initialize device registry file if it doesn't exist
+	if err := storage.initializeDevices(); err != nil {
+		return nil, fmt.Errorf("failed to initialize devices: %w", err)
+	}
+
+	// Initialize admin credentials file if it doesn't exist
+	if err := storage.initializeAuth(); err != nil {
+		return nil, fmt.Errorf("failed to initialize auth data: %w", err)
+	}
+
+	// Initialize API token registry if it doesn't exist
+	if err := storage.initializeTokens(); err != nil {
+		return nil, fmt.Errorf("failed to initialize API tokens: %w", err)
+	}
+
+	// Initialize audit log if it doesn't exist
+	if err := storage.initializeAudit(); err != nil {
+		return nil, fmt.Errorf("failed to initialize audit log: %w", err)
+	}
+
+	// Initialize spin lock state if it doesn't exist
+	if err := storage.initializeSpinLock(); err != nil {
+		return nil, fmt.Errorf("failed to initialize spin lock: %w", err)
+	}
+
+	// Initialize pending page transitions if they don't exist
+	if err := storage.initializeTransitions(); err != nil {
+		return nil, fmt.Errorf("failed to initialize page transitions: %w", err)
+	}
+
+	// Initialize the display schedule if it doesn't exist
+	if err := storage.initializeSchedule(); err != nil {
+		return nil, fmt.Errorf("failed to initialize display schedule: %w", err)
+	}
+
+	// Initialize the player registry if it doesn't exist
+	if err := storage.initializePlayers(); err != nil {
+		return nil, fmt.Errorf("failed to initialize players: %w", err)
+	}
+
+	// Initialize the POS bill ledger if it doesn't exist
+	if err := storage.initializeBills(); err != nil {
+		return nil, fmt.Errorf("failed to initialize bills: %w", err)
+	}
+
+	// Initialize the customer queue if it doesn't exist
+	if err := storage.initializeQueue(); err != nil {
+		return nil, fmt.Errorf("failed to initialize queue: %w", err)
+	}
+
+	// Initialize the prize voucher book if it doesn't exist
+	if err := storage.initializeVouchers(); err != nil {
+		return nil, fmt.Errorf("failed to initialize vouchers: %w", err)
+	}
+
+	// Initialize the self-service spin links and their signing secret if they don't exist
+	if err := storage.initializeSpinLinks(); err != nil {
+		return nil, fmt.Errorf("failed to initialize spin links: %w", err)
+	}
+
+	// Initialize the progressive jackpot pool at its seed if it doesn't exist
+	if err := storage.initializeJackpot(); err != nil {
+		return nil, fmt.Errorf("failed to initialize jackpot: %w", err)
+	}
+
 	// Create uploads directory for advertisements
 	uploadsDir := filepath.Join(dataDir, "uploads")
 	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
@@ -68,6 +135,8 @@ func (s *Storage) GetConfig() (*models.GameConfig, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	config.ApplyTimingDefaults()
+	config.ApplyJackpotDefaults()
 
 	return &config, nil
 }
@@ -134,41 +203,59 @@ func (s *Storage) AddSpinResult(result models.SpinResult) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	history, err := s.getHistoryUnsafe()
-	if err != nil {
-		return err
+	return s.addSpinResultUnsafe(result)
+}
+
+// SaveSpin records a spin result and the config it left behind, taking the spin
+// from the allowance of the registered player it was for and recording the prize
+// voucher issued and the jackpot pool left by it, if any. Everything is checked
+// before the first write; if a write still fails, the files already written are
+// restored and a *PartialWriteError says which they were.
+func (s *Storage) SaveSpin(config *models.GameConfig, result models.SpinResult, voucher *models.Voucher, jackpot *models.Jackpot) error {
+	if err := config.ValidateConfig(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
 	}
 
-	// Add new result
-	history.Results = append(history.Results, result)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	// Clean up old entries (older than 2 days)
-	cutoff := time.Now().Add(-48 * time.Hour)
-	filteredResults := make([]models.SpinResult, 0)
-	for _, res := range history.Results {
-		if res.Timestamp.After(cutoff) {
-			filteredResults = append(filteredResults, res)
+	writes := []fileWrite{
+		{"history.json", func() error { return s.addSpinResultUnsafe(result) }},
+		{"config.json", func() error { return s.saveConfigUnsafe(config) }},
+	}
+
+	if result.PlayerID != "" {
+		registry, err := s.takePlayerSpinUnsafe(result.PlayerID, result.Timestamp)
+		if err != nil {
+			return err
 		}
+		writes = append(writes, fileWrite{playersFile, func() error { return s.writeJSONUnsafe(playersFile, registry) }})
 	}
-	history.Results = filteredResults
 
-	return s.saveHistoryUnsafe(history)
-}
+	if jackpot != nil {
+		writes = append(writes, fileWrite{jackpotFile, func() error { return s.writeJSONUnsafe(jackpotFile, jackpot) }})
+	}
 
-// ResetGame resets the game state
-func (s *Storage) ResetGame() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	// The voucher goes last so it only becomes redeemable once the spin it pays out is recorded
+	if voucher != nil {
+		book, err := s.addVoucherUnsafe(*voucher)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, fileWrite{vouchersFile, func() error { return s.writeJSONUnsafe(vouchersFile, book) }})
+	}
 
-	// Get current config
-	config, err := s.getConfigUnsafe()
-	if err != nil {
-		return err
+	return s.writeAllUnsafe(writes)
+}
+
+// ResetGame saves the reset config and clears the history
+func (s *Storage) ResetGame(config *models.GameConfig) error {
+	if err := config.ValidateConfig(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
 	}
 
-	// Reset relevant fields
-	config.CurrentPlayer = 1
-	config.RemainingSpins = 100
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
 	// Save updated config
 	if err := s.saveConfigUnsafe(config); err != nil {
@@ -212,6 +299,8 @@ func (s *Storage) getConfigUnsafe() (*models.GameConfig, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	config.ApplyTimingDefaults()
+	config.ApplyJackpotDefaults()
 
 	return &config, nil
 }
@@ -238,6 +327,29 @@ func (s *Storage) saveConfigUnsafe(config *models.GameConfig) error {
 	return nil
 }
 
+// addSpinResultUnsafe appends a result to history without locking (internal use)
+func (s *Storage) addSpinResultUnsafe(result models.SpinResult) error {
+	history, err := s.getHistoryUnsafe()
+	if err != nil {
+		return err
+	}
+
+	// Add new result
+	history.Results = append(history.Results, result)
+
+	// Clean up old entries (older than 2 days)
+	cutoff := time.Now().Add(-48 * time.Hour)
+	filteredResults := make([]models.SpinResult, 0)
+	for _, res := range history.Results {
+		if res.Timestamp.After(cutoff) {
+			filteredResults = append(filteredResults, res)
+		}
+	}
+	history.Results = filteredResults
+
+	return s.saveHistoryUnsafe(history)
+}
+
 // getHistoryUnsafe reads history without locking (internal use)
 func (s *Storage) getHistoryUnsafe() (*models.SpinHistory, error) {
 	historyPath := filepath.Join(s.dataDir, "history.json")
@@ -305,6 +417,30 @@ func (s *Storage) SaveRestaurantData(data *models.RestaurantData) error {
 	return s.saveRestaurantDataUnsafe(data)
 }
 
+// UpdateRestaurantConfig replaces the restaurant configuration after check accepts the
+// current data, returning the previous configuration and the updated data
+func (s *Storage) UpdateRestaurantConfig(config models.RestaurantConfig, check func(current *models.RestaurantData) error) (models.RestaurantConfig, *models.RestaurantData, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.getRestaurantDataUnsafe()
+	if err != nil {
+		return models.RestaurantConfig{}, nil, err
+	}
+
+	if err := check(data); err != nil {
+		return models.RestaurantConfig{}, nil, err
+	}
+
+	before := data.Config
+	data.Config = config
+	if err := s.saveRestaurantDataUnsafe(data); err != nil {
+		return models.RestaurantConfig{}, nil, err
+	}
+
+	return before, data, nil
+}
+
 // AddAdvertisement adds a new advertisement
 func (s *Storage) AddAdvertisement(ad models.Advertisement) error {
 	s.mutex.Lock()
@@ -516,4 +652,132 @@ func (s *Storage) saveRestaurantDataUnsafe(data *models.RestaurantData) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// readJSONUnsafe reads and parses a JSON file in the data directory without locking (internal use)
+func (s *Storage) readJSONUnsafe(name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(s.dataDir, name))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// writeJSONUnsafe writes a JSON file in the data directory without locking (internal use)
+func (s *Storage) writeJSONUnsafe(name string, v interface{}) error {
+	return s.writeJSONFileUnsafe(name, v, 0644)
+}
+
+// writeJSONFileUnsafe writes a JSON file with the given permissions without locking (internal use)
+func (s *Storage) writeJSONFileUnsafe(name string, v interface{}, perm os.FileMode) error {
+	file, err := os.OpenFile(filepath.Join(s.dataDir, name), os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false) // Don't escape HTML/UTF-8 characters
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// fileWrite is one step of a save spanning several data files
+type fileWrite struct {
+	name  string // File in the data directory the step writes
+	write func() error
+}
+
+// PartialWriteError reports a save spanning several files that failed partway.
+// Written lists the files written before the failure; they, and the file that
+// failed, have been put back as they were unless RestoreErr is set.
+type PartialWriteError struct {
+	Failed     string
+	Written    []string
+	Err        error
+	RestoreErr error
+}
+
+// Error implements the error interface
+func (e *PartialWriteError) Error() string {
+	message := "failed to write " + e.Failed
+	if len(e.Written) > 0 {
+		message += " after writing " + strings.Join(e.Written, ", ")
+	}
+	if e.RestoreErr != nil {
+		message += fmt.Sprintf(" (restoring failed: %v)", e.RestoreErr)
+	} else if len(e.Written) > 0 {
+		message += " (restored)"
+	}
+	return message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the write that failed
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
+
+// writeAllUnsafe runs the writes in order, keeping each file's previous contents
+// so that a failure can put every file touched so far back (internal use)
+func (s *Storage) writeAllUnsafe(writes []fileWrite) error {
+	type original struct {
+		name   string
+		data   []byte
+		exists bool
+	}
+	originals := make([]original, 0, len(writes))
+	written := make([]string, 0, len(writes))
+
+	for _, w := range writes {
+		data, err := os.ReadFile(filepath.Join(s.dataDir, w.name))
+		if err != nil && !os.IsNotExist(err) {
+			err = fmt.Errorf("failed to read %s: %w", w.name, err)
+		} else {
+			originals = append(originals, original{name: w.name, data: data, exists: err == nil})
+			err = w.write()
+		}
+		if err == nil {
+			written = append(written, w.name)
+			continue
+		}
+
+		// Put back the files in reverse, including the one that may have been left half written
+		var restoreErr error
+		for i := len(originals) - 1; i >= 0; i-- {
+			path := filepath.Join(s.dataDir, originals[i].name)
+			if originals[i].exists {
+				err := os.WriteFile(path, originals[i].data, 0644)
+				if err != nil && restoreErr == nil {
+					restoreErr = err
+				}
+			} else if err := os.Remove(path); err != nil && !os.IsNotExist(err) && restoreErr == nil {
+				restoreErr = err
+			}
+		}
+		return &PartialWriteError{Failed: w.name, Written: written, Err: err, RestoreErr: restoreErr}
+	}
+	return nil
+}
+
+// initializeJSON writes the default value to a data file if it doesn't exist yet
+func (s *Storage) initializeJSON(name string, defaultValue interface{}) error {
+	return s.initializeJSONFile(name, defaultValue, 0644)
+}
+
+// initializeJSONFile writes the default value with the given permissions if the file doesn't exist yet
+func (s *Storage) initializeJSONFile(name string, defaultValue interface{}, perm os.FileMode) error {
+	if _, err := os.Stat(filepath.Join(s.dataDir, name)); os.IsNotExist(err) {
+		return s.writeJSONFileUnsafe(name, defaultValue, perm)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+func TestSaveSpinChargesThePlayer(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	now := time.Now()
+	if err := s.AddPlayer(models.Player{ID: "p1", Table: "A1", SpinsGranted: 1, Created: now, Updated: now}); err != nil {
+		t.Fatalf("AddPlayer: %v", err)
+	}
+	config := models.GetDefaultConfig()
+
+	// Two spins racing for the last spin of an allowance: only one may be saved
+	steps := []struct {
+		name    string
+		wantErr error
+	}{
+		{name: "last spin of the allowance"},
+		{name: "allowance used up", wantErr: ErrNoSpinsRemaining},
+	}
+	for i, step := range steps {
+		result := models.SpinResult{PlayerID: "p1", Prize: "奖品1", Timestamp: now.Add(time.Duration(i) * time.Second), Mode: config.Mode}
+		if err := s.SaveSpin(config, result, nil, nil); !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", step.name, err, step.wantErr)
+		}
+	}
+
+	player, err := s.GetPlayer("p1")
+	if err != nil {
+		t.Fatalf("GetPlayer: %v", err)
+	}
+	if player.SpinsUsed != 1 {
+		t.Errorf("SpinsUsed = %d, want 1", player.SpinsUsed)
+	}
+	history, err := s.GetHistory()
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(history.Results) != 1 {
+		t.Errorf("history has %d results, want 1", len(history.Results))
+	}
+}
+
+func TestWriteAllUnsafeRestoresOnFailure(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ledger, err := s.GetBillLedger()
+	if err != nil {
+		t.Fatalf("GetBillLedger: %v", err)
+	}
+	failure := errors.New("disk full")
+
+	// The ledger is written, then the player registry fails halfway
+	ledger.Add(models.Bill{ID: "B-1", Table: "A3", Amount: 200, SpinsGranted: 2})
+	err = s.writeAllUnsafe([]fileWrite{
+		{billsFile, func() error { return s.writeJSONUnsafe(billsFile, ledger) }},
+		{playersFile, func() error { return failure }},
+	})
+
+	var partial *PartialWriteError
+	if !errors.As(err, &partial) || !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want a PartialWriteError wrapping %v", err, failure)
+	}
+	if partial.Failed != playersFile || len(partial.Written) != 1 || partial.Written[0] != billsFile || partial.RestoreErr != nil {
+		t.Errorf("PartialWriteError = %+v", partial)
+	}
+	stored, err := s.GetBillLedger()
+	if err != nil {
+		t.Fatalf("GetBillLedger: %v", err)
+	}
+	if len(stored.Bills) != 0 {
+		t.Errorf("ledger kept %d bills after the failed save, want 0", len(stored.Bills))
+	}
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+const tokensFile = "tokens.json"
+
+// GetAPITokens returns all API tokens, including revoked ones
+func (s *Storage) GetAPITokens() ([]models.APIToken, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	registry, err := s.getTokensUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.Tokens, nil
+}
+
+// AddAPIToken stores a new API token
+func (s *Storage) AddAPIToken(token models.APIToken) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getTokensUnsafe()
+	if err != nil {
+		return err
+	}
+
+	registry.Tokens = append(registry.Tokens, token)
+
+	return s.saveTokensUnsafe(registry)
+}
+
+// UseAPIToken returns the active token with a hash and records its use
+func (s *Storage) UseAPIToken(tokenHash string, ip string, now time.Time) (*models.APIToken, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getTokensUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, token := range registry.Tokens {
+		if token.TokenHash != tokenHash {
+			continue
+		}
+		if !token.Active() {
+			return nil, fmt.Errorf("token has been revoked")
+		}
+
+		// Only write last-used occasionally to keep request overhead low; the IP is
+		// refreshed with it, so a client switching addresses doesn't force a write
+		if token.LastUsed == nil || now.Sub(*token.LastUsed) > time.Minute {
+			registry.Tokens[i].LastUsed = &now
+			registry.Tokens[i].LastUsedIP = ip
+			if err := s.saveTokensUnsafe(registry); err != nil {
+				return nil, err
+			}
+		}
+		return &registry.Tokens[i], nil
+	}
+
+	return nil, fmt.Errorf("token not found")
+}
+
+// RevokeAPIToken marks a token as revoked; the record is kept for reference
+func (s *Storage) RevokeAPIToken(tokenID string, at time.Time) (*models.APIToken, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry, err := s.getTokensUnsafe()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, token := range registry.Tokens {
+		if token.ID != tokenID {
+			continue
+		}
+		if !token.Active() {
+			return nil, fmt.Errorf("token %s is already revoked", tokenID)
+		}
+		registry.Tokens[i].Revoked = &at
+		if err := s.saveTokensUnsafe(registry); err != nil {
+			return nil, err
+		}
+		return &registry.Tokens[i], nil
+	}
+
+	return nil, fmt.Errorf("token with ID %s not found", tokenID)
+}
+
+// initializeTokens creates an empty token registry if it doesn't exist
+func (s *Storage) initializeTokens() error {
+	return s.initializeJSONFile(tokensFile, &models.TokenRegistry{Tokens: make([]models.APIToken, 0)}, 0600)
+}
+
+// getTokensUnsafe reads the token registry without locking (internal use)
+func (s *Storage) getTokensUnsafe() (*models.TokenRegistry, error) {
+	var registry models.TokenRegistry
+	if err := s.readJSONUnsafe(tokensFile, &registry); err != nil {
+		return nil, err
+	}
+	return &registry, nil
+}
+
+// saveTokensUnsafe writes the token registry, readable by the owner only (internal use)
+func (s *Storage) saveTokensUnsafe(registry *models.TokenRegistry) error {
+	return s.writeJSONFileUnsafe(tokensFile, registry, 0600)
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+func TestUseAPITokenRecordsLastUse(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := s.AddAPIToken(models.APIToken{ID: "t1", Name: "POS", TokenHash: "hash", Scopes: []string{models.ScopeSpin}}); err != nil {
+		t.Fatalf("AddAPIToken: %v", err)
+	}
+	start := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	// Steps run in order against the same token
+	steps := []struct {
+		name     string
+		after    time.Duration // Since the first use
+		ip       string
+		wantUsed time.Duration // Recorded last use, since the first use
+		wantIP   string
+	}{
+		{name: "first use", after: 0, ip: "10.0.0.1", wantUsed: 0, wantIP: "10.0.0.1"},
+		{name: "within a minute", after: 30 * time.Second, ip: "10.0.0.1", wantUsed: 0, wantIP: "10.0.0.1"},
+		{name: "new IP within a minute", after: 40 * time.Second, ip: "10.0.0.2", wantUsed: 0, wantIP: "10.0.0.1"},
+		{name: "after a minute", after: 61 * time.Second, ip: "10.0.0.2", wantUsed: 61 * time.Second, wantIP: "10.0.0.2"},
+	}
+
+	for _, step := range steps {
+		token, err := s.UseAPIToken("hash", step.ip, start.Add(step.after))
+		if err != nil {
+			t.Fatalf("%s: UseAPIToken: %v", step.name, err)
+		}
+		if token.ID != "t1" {
+			t.Fatalf("%s: got token %s, want t1", step.name, token.ID)
+		}
+
+		tokens, err := s.GetAPITokens()
+		if err != nil {
+			t.Fatalf("GetAPITokens: %v", err)
+		}
+		stored := tokens[0]
+		if stored.LastUsed == nil || !stored.LastUsed.Equal(start.Add(step.wantUsed)) || stored.LastUsedIP != step.wantIP {
+			t.Errorf("%s: last used %v from %q, want %v from %q", step.name, stored.LastUsed, stored.LastUsedIP, start.Add(step.wantUsed), step.wantIP)
+		}
+	}
+
+	if _, err := s.RevokeAPIToken("t1", start); err != nil {
+		t.Fatalf("RevokeAPIToken: %v", err)
+	}
+	if _, err := s.UseAPIToken("hash", "10.0.0.1", start.Add(time.Hour)); err == nil {
+		t.Error("revoked token was accepted")
+	}
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"spinner-wheel/models"
+)
+
+const transitionsFile = "transitions.json"
+
+// GetPageTransitions returns the pending page transitions
+func (s *Storage) GetPageTransitions() ([]models.PageTransition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var list models.PageTransitionList
+	if err := s.readJSONUnsafe(transitionsFile, &list); err != nil {
+		return nil, err
+	}
+	return list.Transitions, nil
+}
+
+// SavePageTransitions replaces the pending page transitions
+func (s *Storage) SavePageTransitions(transitions []models.PageTransition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if transitions == nil {
+		transitions = make([]models.PageTransition, 0)
+	}
+	return s.writeJSONUnsafe(transitionsFile, &models.PageTransitionList{Transitions: transitions})
+}
+
+// initializeTransitions creates an empty page transition file if it doesn't exist
+func (s *Storage) initializeTransitions() error {
+	return s.initializeJSON(transitionsFile, &models.PageTransitionList{Transitions: make([]models.PageTransition, 0)})
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"spinner-wheel/models"
+)
+
+const vouchersFile = "vouchers.json"
+
+var (
+	// ErrVoucherNotFound reports a code that was never issued
+	ErrVoucherNotFound = errors.New("voucher not found")
+	// ErrVoucherBookFull reports that every kept voucher can still be redeemed, so none can be forgotten for a new one
+	ErrVoucherBookFull = errors.New("voucher book is full of outstanding vouchers")
+)
+
+// GetVoucherBook returns the voucher settings and every voucher, oldest first
+func (s *Storage) GetVoucherBook() (*models.VoucherBook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.getVoucherBookUnsafe()
+}
+
+// GetVoucher returns the voucher with a code
+func (s *Storage) GetVoucher(code string) (*models.Voucher, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	book, err := s.getVoucherBookUnsafe()
+	if err != nil {
+		return nil, err
+	}
+	for _, voucher := range book.Vouchers {
+		if voucher.Code == code {
+			return &voucher, nil
+		}
+	}
+	return nil, ErrVoucherNotFound
+}
+
+// UpdateVoucher applies update to a voucher under the storage lock and saves it,
+// returning the voucher before and after. Nothing is saved if update fails.
+func (s *Storage) UpdateVoucher(code string, update func(voucher *models.Voucher) error) (before, after models.Voucher, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	book, err := s.getVoucherBookUnsafe()
+	if err != nil {
+		return before, after, err
+	}
+	for i := range book.Vouchers {
+		if book.Vouchers[i].Code != code {
+			continue
+		}
+		before = book.Vouchers[i]
+		after = before
+		if err := update(&after); err != nil {
+			return before, before, err
+		}
+		book.Vouchers[i] = after
+		if err := s.writeJSONUnsafe(vouchersFile, book); err != nil {
+			return before, before, err
+		}
+		return before, after, nil
+	}
+	return before, after, ErrVoucherNotFound
+}
+
+// UpdateVoucherSettings replaces the voucher settings after check approves the current ones, returning the settings before
+func (s *Storage) UpdateVoucherSettings(settings models.VoucherSettings, check func(current models.VoucherSettings) error) (models.VoucherSettings, error) {
+	if err := settings.Validate(); err != nil {
+		return models.VoucherSettings{}, fmt.Errorf("invalid voucher settings: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	book, err := s.getVoucherBookUnsafe()
+	if err != nil {
+		return models.VoucherSettings{}, err
+	}
+
+	before := book.Settings
+	if err := check(before); err != nil {
+		return before, err
+	}
+
+	book.Settings = settings
+	return before, s.writeJSONUnsafe(vouchersFile, book)
+}
+
+// addVoucherUnsafe returns the voucher book with a newly issued voucher added,
+// for the caller to write, without locking (internal use). To make room it forgets
+// the oldest settled vouchers, and returns ErrVoucherBookFull if there are too few.
+func (s *Storage) addVoucherUnsafe(voucher models.Voucher) (*models.VoucherBook, error) {
+	book, err := s.getVoucherBookUnsafe()
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range book.Vouchers {
+		if existing.Code == voucher.Code {
+			return nil, fmt.Errorf("voucher code %s is already in use", voucher.Code)
+		}
+	}
+
+	// A voucher that can still be redeemed must stay, or its winner would be turned away
+	if excess := len(book.Vouchers) + 1 - models.MaxVoucherRecords; excess > 0 {
+		kept := make([]models.Voucher, 0, len(book.Vouchers))
+		for _, existing := range book.Vouchers {
+			if excess > 0 && existing.IsSettled(voucher.Issued) {
+				excess--
+				continue
+			}
+			kept = append(kept, existing)
+		}
+		if excess > 0 {
+			return nil, ErrVoucherBookFull
+		}
+		book.Vouchers = kept
+	}
+
+	book.Vouchers = append(book.Vouchers, voucher)
+	return book, nil
+}
+
+// initializeVouchers creates an empty voucher book if it doesn't exist
+func (s *Storage) initializeVouchers() error {
+	return s.initializeJSON(vouchersFile, models.GetDefaultVoucherBook())
+}
+
+// getVoucherBookUnsafe reads the voucher book without locking (internal use)
+func (s *Storage) getVoucherBookUnsafe() (*models.VoucherBook, error) {
+	var book models.VoucherBook
+	if err := s.readJSONUnsafe(vouchersFile, &book); err != nil {
+		return nil, err
+	}
+	if book.Vouchers == nil {
+		book.Vouchers = make([]models.Voucher, 0)
+	}
+	return &book, nil
+}
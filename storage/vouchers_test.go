@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"spinner-wheel/models"
+)
+
+func TestAddVoucherUnsafe(t *testing.T) {
+	now := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+	redeemed := now.Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		count       int                             // Vouchers already in the book, V0 oldest
+		settle      map[int]func(v *models.Voucher) // Vouchers that can no longer be redeemed
+		code        string
+		wantErr     error
+		wantDropped []string // Codes forgotten to make room
+	}{
+		{
+			name:  "room left",
+			count: models.MaxVoucherRecords - 1,
+			code:  "NEW",
+		},
+		{
+			name:  "full book forgets the oldest settled voucher",
+			count: models.MaxVoucherRecords,
+			settle: map[int]func(v *models.Voucher){
+				5: func(v *models.Voucher) { v.Status = models.VoucherStatusRedeemed; v.Redeemed = &redeemed },
+				9: func(v *models.Voucher) { v.Status = models.VoucherStatusVoid; v.Voided = &redeemed },
+			},
+			code:        "NEW",
+			wantDropped: []string{"V5"},
+		},
+		{
+			name:  "expired voucher is settled",
+			count: models.MaxVoucherRecords,
+			settle: map[int]func(v *models.Voucher){
+				7: func(v *models.Voucher) { v.Expires = now.Add(-time.Minute) },
+			},
+			code:        "NEW",
+			wantDropped: []string{"V7"},
+		},
+		{
+			name:    "outstanding vouchers are never forgotten",
+			count:   models.MaxVoucherRecords,
+			code:    "NEW",
+			wantErr: ErrVoucherBookFull,
+		},
+		{
+			name:    "code in use",
+			count:   3,
+			code:    "V1",
+			wantErr: errors.New("voucher code V1 is already in use"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(t.TempDir())
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			book, err := s.GetVoucherBook()
+			if err != nil {
+				t.Fatalf("GetVoucherBook: %v", err)
+			}
+			for i := 0; i < tt.count; i++ {
+				voucher := models.Voucher{
+					Code:    fmt.Sprintf("V%d", i),
+					Prize:   "奖品1",
+					Status:  models.VoucherStatusIssued,
+					Issued:  now.AddDate(0, 0, -1),
+					Expires: now.AddDate(0, 0, 29),
+				}
+				if settle := tt.settle[i]; settle != nil {
+					settle(&voucher)
+				}
+				book.Vouchers = append(book.Vouchers, voucher)
+			}
+			if err := s.writeJSONUnsafe(vouchersFile, book); err != nil {
+				t.Fatalf("write vouchers: %v", err)
+			}
+
+			got, err := s.addVoucherUnsafe(models.Voucher{Code: tt.code, Status: models.VoucherStatusIssued, Issued: now, Expires: now.AddDate(0, 0, 30)})
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("addVoucherUnsafe: %v", err)
+			}
+
+			kept := make(map[string]bool, len(got.Vouchers))
+			for _, voucher := range got.Vouchers {
+				kept[voucher.Code] = true
+			}
+			for _, code := range tt.wantDropped {
+				if kept[code] {
+					t.Errorf("%s was kept, want it forgotten", code)
+				}
+			}
+			if want := tt.count + 1 - len(tt.wantDropped); len(got.Vouchers) != want {
+				t.Errorf("book holds %d vouchers, want %d", len(got.Vouchers), want)
+			}
+			if last := got.Vouchers[len(got.Vouchers)-1]; last.Code != tt.code {
+				t.Errorf("newest voucher = %s, want %s", last.Code, tt.code)
+			}
+		})
+	}
+}